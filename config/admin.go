@@ -0,0 +1,22 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type AdminConfig struct {
+	// Masa berlaku token undangan admin
+	InvitationTTL time.Duration
+	// Halaman frontend untuk menerima undangan. Token ditambahkan sebagai query token.
+	// Jika kosong, email undangan hanya berisi token.
+	InvitationURL string
+}
+
+// InitConfigAdmin membaca pengaturan akun admin dari environment variables
+func InitConfigAdmin() AdminConfig {
+	return AdminConfig{
+		InvitationTTL: getDuration("ADMIN_INVITATION_TTL", 72*time.Hour),
+		InvitationURL: os.Getenv("ADMIN_INVITATION_URL"),
+	}
+}
@@ -27,6 +27,10 @@ func InitConfigDB() database.Config {
 		DB_SSL:       os.Getenv("DB_SSL"),
 		DB_TZ:        os.Getenv("DB_TZ"),
 		DB_LOG_LEVEL: os.Getenv("DB_LOG_LEVEL"),
+
+		ADMIN_BOOTSTRAP_NAME:     getString("ADMIN_BOOTSTRAP_NAME", "Super Admin"),
+		ADMIN_BOOTSTRAP_EMAIL:    os.Getenv("ADMIN_BOOTSTRAP_EMAIL"),
+		ADMIN_BOOTSTRAP_PASSWORD: os.Getenv("ADMIN_BOOTSTRAP_PASSWORD"),
 	}
 }
 
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+type DonationConfig struct {
+	// Interval worker rekonsiliasi dan usia minimum donasi pending yang dicek
+	ReconcileInterval time.Duration
+	ReconcileMinAge   time.Duration
+	ReconcileBatch    int
+
+	// Donasi pending yang lebih tua dari PendingTTL diubah menjadi expired setiap ExpireInterval
+	PendingTTL     time.Duration
+	ExpireInterval time.Duration
+
+	// Lama respons pembuatan donasi disimpan untuk header Idempotency-Key. Selama request
+	// masih diproses, key hanya dikunci selama IdempotencyLockTTL.
+	IdempotencyTTL     time.Duration
+	IdempotencyLockTTL time.Duration
+
+	// Worker donasi rutin berjalan setiap SubscriptionInterval. Siklus yang gagal ditagih
+	// ulang setelah SubscriptionRetryDelay, dan dihentikan setelah SubscriptionMaxRetries kali.
+	SubscriptionInterval   time.Duration
+	SubscriptionRetryDelay time.Duration
+	SubscriptionMaxRetries int
+
+	// Interval worker yang mengelompokkan donasi baru ke profil donatur
+	DonorDirectoryInterval time.Duration
+}
+
+// InitConfigDonation membaca pengaturan proses donasi dari environment variables
+func InitConfigDonation() DonationConfig {
+	return DonationConfig{
+		ReconcileInterval: getDuration("RECONCILE_INTERVAL", 15*time.Minute),
+		ReconcileMinAge:   getDuration("RECONCILE_MIN_AGE", 30*time.Minute),
+		ReconcileBatch:    100,
+		PendingTTL:        getDuration("PENDING_DONATION_TTL", 24*time.Hour),
+		ExpireInterval:    getDuration("EXPIRE_INTERVAL", 10*time.Minute),
+		IdempotencyTTL:    getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		IdempotencyLockTTL: getDuration("IDEMPOTENCY_LOCK_TTL", time.Minute),
+
+		SubscriptionInterval:   getDuration("SUBSCRIPTION_INTERVAL", time.Hour),
+		SubscriptionRetryDelay: getDuration("SUBSCRIPTION_RETRY_DELAY", 72*time.Hour),
+		SubscriptionMaxRetries: getInt("SUBSCRIPTION_MAX_RETRIES", 3),
+
+		DonorDirectoryInterval: getDuration("DONOR_DIRECTORY_INTERVAL", 10*time.Minute),
+	}
+}
+
+func getInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid number for %s (%q), using default %d", key, value, fallback)
+		return fallback
+	}
+	return number
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s (%q), using default %s", key, value, fallback)
+		return fallback
+	}
+	return duration
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type MailConfig struct {
+	// Kanal pengiriman email: smtp, file, atau log (default)
+	Transport string
+	From      string
+	FromName  string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	// Folder tujuan file .eml untuk transport file
+	FileDir string
+
+	// Ukuran antrean dan jumlah worker pengiriman asinkron
+	QueueSize int
+	Workers   int
+
+	// Donasi lunas dengan nominal ini atau lebih dikabarkan ke admin
+	LargeDonationAmount int
+	// Penerima kabar donasi besar. Jika kosong, dikirim ke semua akun admin.
+	AdminRecipients []string
+}
+
+// InitConfigMail membaca pengaturan email dari environment variables. Nilai bawaan SMTP
+// mengarah ke server uji lokal (MailHog di port 1025).
+func InitConfigMail() MailConfig {
+	return MailConfig{
+		Transport:           strings.ToLower(getString("MAIL_TRANSPORT", "log")),
+		From:                getString("MAIL_FROM", "no-reply@localhost"),
+		FromName:            getString("MAIL_FROM_NAME", "Panti Asuhan Artanita"),
+		SMTPHost:            getString("SMTP_HOST", "localhost"),
+		SMTPPort:            getInt("SMTP_PORT", 1025),
+		SMTPUsername:        os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:        os.Getenv("SMTP_PASSWORD"),
+		FileDir:             getString("MAIL_FILE_DIR", "mails"),
+		QueueSize:           getInt("MAIL_QUEUE_SIZE", 100),
+		Workers:             getInt("MAIL_WORKERS", 2),
+		LargeDonationAmount: getInt("MAIL_LARGE_DONATION_AMOUNT", 1000000),
+		AdminRecipients:     SplitList(os.Getenv("MAIL_ADMIN_RECIPIENTS")),
+	}
+}
@@ -1,26 +1,68 @@
 package config
 
 import (
-	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 type MidtransConfig struct {
 	ServerKey string
 	ClientKey string
+
+	// Environment bernilai sandbox atau production
+	Environment string
+
+	// Batas waktu pembayaran Snap, dapat ditimpa per program donasi
+	ExpiryDuration int
+	ExpiryUnit     string
+
+	// Metode pembayaran yang ditampilkan di Snap, kosong berarti semua metode aktif
+	EnabledPayments []string
+
+	FinishURL   string
+	UnfinishURL string
+	ErrorURL    string
 }
 
-// InitConfigMidtrans initializes the Midtrans configuration from environment variables
+// InitConfigMidtrans initializes the Midtrans configuration from environment variables.
+// Server key dan client key baru diwajibkan saat gateway midtrans dipilih, lihat
+// payment.NewPaymentGateway.
 func InitConfigMidtrans() MidtransConfig {
 	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
 	clientKey := os.Getenv("MIDTRANS_CLIENT_KEY")
 
-	if serverKey == "" || clientKey == "" {
-		log.Fatal("Missing Midtrans server or client key. Please set the MIDTRANS_SERVER_KEY and MIDTRANS_CLIENT_KEY environment variables.")
+	environment := strings.ToLower(os.Getenv("MIDTRANS_ENV"))
+	if environment != "production" {
+		environment = "sandbox"
+	}
+
+	expiryDuration, _ := strconv.Atoi(os.Getenv("MIDTRANS_EXPIRY_DURATION"))
+	expiryUnit := strings.ToLower(os.Getenv("MIDTRANS_EXPIRY_UNIT"))
+	if expiryUnit == "" {
+		expiryUnit = "minute"
 	}
 
 	return MidtransConfig{
-		ServerKey: serverKey,
-		ClientKey: clientKey,
+		ServerKey:       serverKey,
+		ClientKey:       clientKey,
+		Environment:     environment,
+		ExpiryDuration:  expiryDuration,
+		ExpiryUnit:      expiryUnit,
+		EnabledPayments: SplitList(os.Getenv("MIDTRANS_ENABLED_PAYMENTS")),
+		FinishURL:       os.Getenv("MIDTRANS_FINISH_URL"),
+		UnfinishURL:     os.Getenv("MIDTRANS_UNFINISH_URL"),
+		ErrorURL:        os.Getenv("MIDTRANS_ERROR_URL"),
+	}
+}
+
+// SplitList memecah daftar yang dipisah koma dan membuang elemen kosong
+func SplitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
 	}
+	return result
 }
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// Daftar kata bawaan jika MODERATION_BLOCKED_WORDS tidak diisi: promosi judi online
+// dan kata kasar yang paling sering muncul di pesan spam
+const defaultBlockedWords = "judi,judol,slot,gacor,togel,maxwin,zeus,anjing,bangsat,babi,goblok,tolol,kontol,memek,jancok"
+
+type ModerationConfig struct {
+	// Kata atau frasa yang membuat pesan ditandai, dipisah koma
+	BlockedWords []string
+	// Pesan yang berisi tautan ikut ditandai
+	BlockLinks bool
+	// Interval worker yang memeriksa pesan donasi baru
+	Interval time.Duration
+}
+
+// InitConfigModeration membaca pengaturan moderasi pesan donatur dari environment variables
+func InitConfigModeration() ModerationConfig {
+	return ModerationConfig{
+		BlockedWords: SplitList(getString("MODERATION_BLOCKED_WORDS", defaultBlockedWords)),
+		BlockLinks:   getString("MODERATION_BLOCK_LINKS", "true") != "false",
+		Interval:     getDuration("MODERATION_INTERVAL", time.Minute),
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type PaymentConfig struct {
+	Gateway string
+
+	// Rekening tujuan untuk gateway transfer manual
+	ManualBankName       string
+	ManualAccountNumber  string
+	ManualAccountHolder  string
+	ManualInstructionURL string
+}
+
+// InitConfigPayment membaca gateway pembayaran yang dipakai dari environment variables.
+// PAYMENT_GATEWAY dapat bernilai midtrans (default), manual, atau fake.
+func InitConfigPayment() PaymentConfig {
+	gateway := strings.ToLower(os.Getenv("PAYMENT_GATEWAY"))
+	if gateway == "" {
+		gateway = "midtrans"
+	}
+
+	return PaymentConfig{
+		Gateway:              gateway,
+		ManualBankName:       os.Getenv("MANUAL_TRANSFER_BANK_NAME"),
+		ManualAccountNumber:  os.Getenv("MANUAL_TRANSFER_ACCOUNT_NUMBER"),
+		ManualAccountHolder:  os.Getenv("MANUAL_TRANSFER_ACCOUNT_HOLDER"),
+		ManualInstructionURL: os.Getenv("MANUAL_TRANSFER_INSTRUCTION_URL"),
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import "os"
+
+type ReceiptConfig struct {
+	// Identitas yayasan yang dicetak pada kuitansi donasi
+	FoundationName    string
+	FoundationAddress string
+	FoundationPhone   string
+	FoundationEmail   string
+	FoundationTaxID   string
+
+	// Awalan nomor kuitansi, contoh KWT/2026/000001
+	NumberPrefix string
+
+	// Alamat halaman verifikasi kuitansi yang dicetak pada kuitansi
+	VerifyURL string
+}
+
+// InitConfigReceipt membaca pengaturan kuitansi donasi dari environment variables
+func InitConfigReceipt() ReceiptConfig {
+	return ReceiptConfig{
+		FoundationName:    getString("RECEIPT_FOUNDATION_NAME", "Panti Asuhan Artanita"),
+		FoundationAddress: os.Getenv("RECEIPT_FOUNDATION_ADDRESS"),
+		FoundationPhone:   os.Getenv("RECEIPT_FOUNDATION_PHONE"),
+		FoundationEmail:   os.Getenv("RECEIPT_FOUNDATION_EMAIL"),
+		FoundationTaxID:   os.Getenv("RECEIPT_FOUNDATION_TAX_ID"),
+		NumberPrefix:      getString("RECEIPT_NUMBER_PREFIX", "KWT"),
+		VerifyURL:         os.Getenv("RECEIPT_VERIFY_URL"),
+	}
+}
+
+func getString(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+type WhatsAppConfig struct {
+	// Penyedia pengiriman: http, stub, atau log (default)
+	Provider string
+
+	// Gateway WhatsApp berbasis HTTP
+	BaseURL string
+	APIKey  string
+
+	// Alamat server stub lokal untuk provider stub
+	StubAddr string
+
+	// Token yang harus dikirim gateway pada header X-Webhook-Token saat memanggil webhook
+	WebhookToken string
+	// Alamat webhook status pengiriman yang dipanggil server stub
+	StatusCallbackURL string
+
+	// Worker pengiriman antrean pesan
+	DispatchInterval time.Duration
+	BatchSize        int
+	MaxAttempts      int
+
+	// Worker kabar program bulanan berjalan setiap UpdateInterval dan mengirim kabar
+	// bulan berjalan mulai tanggal UpdateDay
+	UpdateInterval time.Duration
+	UpdateDay      int
+}
+
+// InitConfigWhatsApp membaca pengaturan notifikasi WhatsApp dari environment variables
+func InitConfigWhatsApp() WhatsAppConfig {
+	return WhatsAppConfig{
+		Provider:          strings.ToLower(getString("WHATSAPP_PROVIDER", "log")),
+		BaseURL:           strings.TrimRight(os.Getenv("WHATSAPP_BASE_URL"), "/"),
+		APIKey:            os.Getenv("WHATSAPP_API_KEY"),
+		StubAddr:          getString("WHATSAPP_STUB_ADDR", "127.0.0.1:8089"),
+		WebhookToken:      os.Getenv("WHATSAPP_WEBHOOK_TOKEN"),
+		StatusCallbackURL: os.Getenv("WHATSAPP_STATUS_CALLBACK_URL"),
+		DispatchInterval:  getDuration("WHATSAPP_DISPATCH_INTERVAL", 30*time.Second),
+		BatchSize:         getInt("WHATSAPP_BATCH_SIZE", 50),
+		MaxAttempts:       getInt("WHATSAPP_MAX_ATTEMPTS", 3),
+		UpdateInterval:    getDuration("WHATSAPP_UPDATE_INTERVAL", time.Hour),
+		UpdateDay:         getInt("WHATSAPP_UPDATE_DAY", 1),
+	}
+}
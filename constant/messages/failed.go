@@ -45,4 +45,105 @@ const (
 	FAILED_CREATE_ADMIN = "failed create admin"
 
 	FAILED_GET_DASHBOARD = "failed get dashboard"
+
+	// Payment
+	DUPLICATE_NOTIFICATION    = "notification already processed"
+	ILLEGAL_STATUS_TRANSITION = "illegal donation status transition"
+	GROSS_AMOUNT_MISMATCH     = "transaction amount does not match donation amount"
+	INVALID_ALLOCATION_AMOUNT = "donation amount must be greater than 0"
+
+	FAILED_RUN_RECONCILIATION      = "Failed run reconciliation"
+	FAILED_GET_RECONCILIATION      = "Failed get reconciliation report"
+	RECONCILIATION_ALREADY_RUNNING = "reconciliation already running"
+
+	FAILED_REFUND_DONATION  = "Failed refund donation"
+	FAILED_GET_REFUNDS      = "Failed get refunds"
+	DONATION_NOT_REFUNDABLE = "donation cannot be refunded"
+	INVALID_REFUND_AMOUNT   = "invalid refund amount"
+
+	FAILED_RESUME_DONATION = "Failed resume donation payment"
+	DONATION_NOT_RESUMABLE = "donation is no longer awaiting payment"
+
+	IDEMPOTENCY_KEY_REUSED          = "Idempotency-Key already used for a different request"
+	IDEMPOTENCY_REQUEST_IN_PROGRESS = "A request with this Idempotency-Key is still being processed"
+
+	FAILED_RECORD_OFFLINE_DONATION  = "Failed record offline donation"
+	FAILED_SUBMIT_TRANSFER_PROOF    = "Failed submit transfer proof"
+	FAILED_GET_TRANSFER_PROOFS      = "Failed get transfer proofs"
+	FAILED_REVIEW_TRANSFER_PROOF    = "Failed review transfer proof"
+	TRANSFER_PROOF_ALREADY_REVIEWED = "transfer proof already reviewed"
+
+	FAILED_CREATE_SUBSCRIPTION  = "Failed create recurring donation"
+	FAILED_UPDATE_SUBSCRIPTION  = "Failed update recurring donation"
+	FAILED_GET_SUBSCRIPTIONS    = "Failed get recurring donations"
+	INVALID_MANAGE_TOKEN        = "invalid subscription manage token"
+	SUBSCRIPTION_STATE_CONFLICT = "recurring donation cannot be changed in its current status"
+
+	FAILED_GET_RECEIPT    = "Failed get donation receipt"
+	RECEIPT_NOT_AVAILABLE = "receipt is only available for paid donations"
+	INVALID_RECEIPT       = "receipt number or verification code is invalid"
+
+	FAILED_REGISTER_DONOR      = "Failed register donor account"
+	FAILED_VERIFY_DONOR        = "Failed verify donor account"
+	FAILED_GET_DONOR_PROFILE   = "Failed get donor profile"
+	FAILED_GET_DONOR_DONATIONS = "Failed get donor donations"
+	EMAIL_ALREADY_REGISTERED   = "email is already registered"
+	EMAIL_NOT_VERIFIED         = "email is not verified"
+	INVALID_VERIFICATION       = "verification token or code is invalid or expired"
+
+	TOO_MANY_VERIFICATION_ATTEMPTS = "too many verification attempts, please try again later"
+
+	FAILED_GET_DONOR_PROFILES      = "Failed get donor profiles"
+	FAILED_GET_DONOR_DUPLICATES    = "Failed get suspected duplicate donors"
+	FAILED_MERGE_DONOR_PROFILES    = "Failed merge donor profiles"
+	FAILED_DISMISS_DONOR_DUPLICATE = "Failed dismiss suspected duplicate donor"
+	FAILED_EXPORT_DONOR_PROFILES   = "Failed export donor profiles"
+	INVALID_DONOR_MERGE            = "donor profiles cannot be merged"
+	DONOR_DUPLICATE_REVIEWED       = "suspected duplicate has already been reviewed"
+
+	FAILED_GET_DONATION_MESSAGES = "Failed get donation messages"
+	FAILED_MODERATE_MESSAGE      = "Failed moderate donation message"
+	FAILED_GET_PRAYERS           = "Failed get prayers"
+
+	FAILED_STREAM_DONATIONS = "Failed stream donation events"
+
+	FAILED_GET_NOTIFICATIONS  = "Failed get donation notifications"
+	FAILED_COUNT_NOTIFICATION = "Failed count unread notifications"
+	FAILED_MARK_NOTIFICATION  = "Failed mark notification as read"
+	INVALID_CURSOR            = "invalid pagination cursor"
+
+	FAILED_GET_WHATSAPP_MESSAGES   = "Failed get WhatsApp messages"
+	FAILED_HANDLE_WHATSAPP_WEBHOOK = "Failed handle WhatsApp webhook"
+	FAILED_GET_WHATSAPP_PREFERENCE = "Failed get WhatsApp preference"
+	FAILED_SET_WHATSAPP_PREFERENCE = "Failed update WhatsApp preference"
+	WHATSAPP_NUMBER_REQUIRED       = "account has no verified WhatsApp number"
+
+	FAILED_GET_ROLES        = "Failed get admin roles"
+	FAILED_GET_PERMISSIONS  = "Failed get admin permissions"
+	FAILED_UPDATE_ROLE      = "Failed update role permissions"
+	FAILED_ASSIGN_ROLE      = "Failed assign admin role"
+	FAILED_CHECK_PERMISSION = "Failed check admin permission"
+	UNKNOWN_ROLE            = "unknown admin role"
+	UNKNOWN_PERMISSION      = "unknown permission"
+	ROLE_LOCKED             = "super admin permissions cannot be changed"
+	CANNOT_CHANGE_OWN_ROLE  = "cannot change your own role"
+	PERMISSION_RESERVED     = "this permission is reserved for super admin"
+
+	FAILED_GET_ADMINS        = "Failed get admins"
+	FAILED_INVITE_ADMIN      = "Failed invite admin"
+	FAILED_UPDATE_ADMIN      = "Failed update admin"
+	FAILED_DEACTIVATE_ADMIN  = "Failed deactivate admin"
+	FAILED_ACTIVATE_ADMIN    = "Failed activate admin"
+	FAILED_DELETE_ADMIN      = "Failed delete admin"
+	FAILED_CHANGE_PASSWORD   = "Failed change password"
+	INVALID_INVITATION       = "invitation is invalid or expired"
+	ADMIN_DEACTIVATED        = "admin account is deactivated"
+	CANNOT_MANAGE_SELF       = "cannot deactivate or delete your own account"
+	SUPER_ADMIN_REQUIRED     = "only a super admin can manage super admin accounts"
+	INVALID_CURRENT_PASSWORD = "current password is incorrect"
+
+	FAILED_REFRESH_TOKEN  = "Failed refresh token"
+	FAILED_LOGOUT         = "Failed logout"
+	FAILED_CHECK_SESSION  = "Failed check session"
+	INVALID_REFRESH_TOKEN = "refresh token is invalid or expired"
 )
\ No newline at end of file
@@ -33,4 +33,71 @@ const (
 
 	SUCCESS_GET_DASHBOARD = "Succes Get Dashboard"
 	SUCCESS_GET_ORPHANAGE_USER_ALL = "Success get orphanage user all"
+
+	SUCCESS_RUN_RECONCILIATION = "Success run reconciliation"
+	SUCCESS_GET_RECONCILIATION = "Success get reconciliation report"
+
+	SUCCESS_REFUND_DONATION = "Success refund donation"
+	SUCCESS_GET_REFUNDS     = "Success get refunds"
+
+	SUCCESS_RESUME_DONATION = "Success resume donation payment"
+
+	SUCCESS_RECORD_OFFLINE_DONATION = "Success record offline donation"
+	SUCCESS_SUBMIT_TRANSFER_PROOF   = "Success submit transfer proof"
+	SUCCESS_GET_TRANSFER_PROOFS     = "Success get transfer proofs"
+	SUCCESS_APPROVE_TRANSFER_PROOF  = "Success approve transfer proof"
+	SUCCESS_REJECT_TRANSFER_PROOF   = "Success reject transfer proof"
+
+	SUCCESS_CREATE_SUBSCRIPTION = "Success create recurring donation"
+	SUCCESS_PAUSE_SUBSCRIPTION  = "Success pause recurring donation"
+	SUCCESS_RESUME_SUBSCRIPTION = "Success resume recurring donation"
+	SUCCESS_CANCEL_SUBSCRIPTION = "Success cancel recurring donation"
+	SUCCESS_GET_SUBSCRIPTIONS   = "Success get recurring donations"
+
+	SUCCESS_VERIFY_RECEIPT = "Success verify donation receipt"
+
+	SUCCESS_REGISTER_DONOR      = "Success register donor account, please verify your email"
+	SUCCESS_VERIFY_EMAIL        = "Success verify email"
+	SUCCESS_VERIFY_PHONE        = "Success verify WhatsApp number"
+	SUCCESS_SEND_VERIFICATION   = "Verification sent"
+	SUCCESS_GET_DONOR_PROFILE   = "Success get donor profile"
+	SUCCESS_GET_DONOR_DONATIONS = "Success get donor donations"
+
+	SUCCESS_GET_DONOR_PROFILES      = "Success get donor profiles"
+	SUCCESS_GET_DONOR_DUPLICATES    = "Success get suspected duplicate donors"
+	SUCCESS_MERGE_DONOR_PROFILES    = "Success merge donor profiles"
+	SUCCESS_DISMISS_DONOR_DUPLICATE = "Success dismiss suspected duplicate donor"
+
+	SUCCESS_GET_DONATION_MESSAGES = "Success get donation messages"
+	SUCCESS_APPROVE_MESSAGE       = "Success approve donation message"
+	SUCCESS_HIDE_MESSAGE          = "Success hide donation message"
+	SUCCESS_EDIT_MESSAGE          = "Success edit donation message"
+	SUCCESS_GET_PRAYERS           = "Success get prayers"
+
+	SUCCESS_GET_NOTIFICATIONS  = "Success get donation notifications"
+	SUCCESS_COUNT_NOTIFICATION = "Success count unread notifications"
+	SUCCESS_MARK_NOTIFICATION  = "Success mark notification as read"
+	SUCCESS_MARK_ALL_READ      = "Success mark all notifications as read"
+
+	SUCCESS_GET_WHATSAPP_MESSAGES   = "Success get WhatsApp messages"
+	SUCCESS_HANDLE_WHATSAPP_WEBHOOK = "Success handle WhatsApp webhook"
+	SUCCESS_GET_WHATSAPP_PREFERENCE = "Success get WhatsApp preference"
+	SUCCESS_SET_WHATSAPP_PREFERENCE = "Success update WhatsApp preference"
+
+	SUCCESS_GET_ROLES       = "Success get admin roles"
+	SUCCESS_GET_PERMISSIONS = "Success get admin permissions"
+	SUCCESS_UPDATE_ROLE     = "Success update role permissions"
+	SUCCESS_ASSIGN_ROLE     = "Success assign admin role"
+
+	SUCCESS_GET_ADMINS       = "Success get admins"
+	SUCCESS_INVITE_ADMIN     = "Admin invitation sent"
+	SUCCESS_UPDATE_ADMIN     = "Success update admin"
+	SUCCESS_DEACTIVATE_ADMIN = "Success deactivate admin"
+	SUCCESS_ACTIVATE_ADMIN   = "Success activate admin"
+	SUCCESS_DELETE_ADMIN     = "Success delete admin"
+	SUCCESS_CHANGE_PASSWORD  = "Success change password"
+
+	SUCCESS_REFRESH_TOKEN = "Success refresh token"
+	SUCCESS_LOGOUT        = "Logout successful"
+	SUCCESS_LOGOUT_ALL    = "All sessions logged out"
 )
\ No newline at end of file
@@ -1,17 +1,24 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	msg "tugas-akhir/constant/messages"
 	dto "tugas-akhir/dto/admin"
+	dto_base "tugas-akhir/dto/base"
 	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
 	http_util "tugas-akhir/utils/http"
 	"tugas-akhir/utils/token"
 	"tugas-akhir/utils/validation"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type adminController struct {
@@ -28,6 +35,7 @@ func NewAdminController(adminUsecase usecases.AdminUseCase, validator *validatio
 	}
 }
 
+// Register membuat akun admin dari token undangan
 func (ac *adminController) Register(c echo.Context) error {
 	log := logrus.New()
 	request := new(dto.RegisterRequest)
@@ -40,13 +48,18 @@ func (ac *adminController) Register(c echo.Context) error {
 		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
 	}
 
-	err := ac.adminUsecase.Register(c, request)
-	if err != nil {
+	response, err := ac.adminUsecase.Register(c, request)
+	switch {
+	case errors.Is(err, err_util.ErrInvalidInvitation):
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_INVITATION)
+	case errors.Is(err, err_util.ErrEmailRegistered):
+		return http_util.HandleErrorResponse(c, http.StatusConflict, msg.EMAIL_ALREADY_REGISTERED)
+	case err != nil:
 		log.Error(err)
 		return http_util.HandleErrorResponse(c, http.StatusInternalServerError, msg.FAILED_CREATE_ADMIN)
 	}
 
-	return http_util.HandleSuccessResponse(c, http.StatusCreated, msg.ADMIN_CREATED_SUCCESS, nil)
+	return http_util.HandleSuccessResponse(c, http.StatusCreated, msg.ADMIN_CREATED_SUCCESS, response)
 }
 
 func (ac *adminController) Login(c echo.Context) error {
@@ -62,6 +75,9 @@ func (ac *adminController) Login(c echo.Context) error {
 
 	// Menangkap kedua nilai kembalian dari Login
 	response, err := ac.adminUsecase.Login(c, request)
+	if errors.Is(err, err_util.ErrAdminDeactivated) {
+		return http_util.HandleErrorResponse(c, http.StatusForbidden, msg.ADMIN_DEACTIVATED)
+	}
 	if err != nil {
 		fmt.Println("Error: ", err)
 		return http_util.HandleErrorResponse(c, http.StatusInternalServerError, msg.FAILED_LOGIN)
@@ -69,3 +85,165 @@ func (ac *adminController) Login(c echo.Context) error {
 
 	return http_util.HandleSuccessResponse(c, http.StatusOK, msg.LOGIN_SUCCESS, response)
 }
+
+func (ac *adminController) GetAdmins(c echo.Context) error {
+	intPage, intLimit, err := ac.convertQueryParams(strings.TrimSpace(c.QueryParam("page")), strings.TrimSpace(c.QueryParam("limit")))
+	if err != nil || intLimit < 1 {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	req := &dto_base.PaginationRequest{
+		Page:  intPage,
+		Limit: intLimit,
+	}
+
+	result, metadata, link, err := ac.adminUsecase.GetAdmins(c, req)
+	switch {
+	case errors.Is(err, err_util.ErrPageNotFound):
+		return http_util.HandleErrorResponse(c, http.StatusNotFound, msg.PAGE_NOT_FOUND)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to get admins")
+		return http_util.HandleErrorResponse(c, http.StatusInternalServerError, msg.FAILED_GET_ADMINS)
+	}
+
+	return http_util.HandlePaginationResponse(c, msg.SUCCESS_GET_ADMINS, result, metadata, link)
+}
+
+func (ac *adminController) InviteAdmin(c echo.Context) error {
+	request := new(dto.InviteAdminRequest)
+	if err := c.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := ac.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := ac.adminUsecase.InviteAdmin(c, ac.tokenUtil.GetClaims(c).ID, request)
+	if err != nil {
+		return ac.handleManageError(c, err, msg.FAILED_INVITE_ADMIN)
+	}
+
+	return http_util.HandleSuccessResponse(c, http.StatusCreated, msg.SUCCESS_INVITE_ADMIN, response)
+}
+
+func (ac *adminController) UpdateAdmin(c echo.Context) error {
+	adminID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, "Invalid Admin ID format")
+	}
+
+	request := new(dto.UpdateAdminRequest)
+	if err := c.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := ac.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := ac.adminUsecase.UpdateAdmin(c, adminID, ac.tokenUtil.GetClaims(c).ID, request)
+	if err != nil {
+		return ac.handleManageError(c, err, msg.FAILED_UPDATE_ADMIN)
+	}
+
+	return http_util.HandleSuccessResponse(c, http.StatusOK, msg.SUCCESS_UPDATE_ADMIN, response)
+}
+
+func (ac *adminController) DeactivateAdmin(c echo.Context) error {
+	return ac.setAdminActive(c, false, msg.FAILED_DEACTIVATE_ADMIN, msg.SUCCESS_DEACTIVATE_ADMIN)
+}
+
+func (ac *adminController) ActivateAdmin(c echo.Context) error {
+	return ac.setAdminActive(c, true, msg.FAILED_ACTIVATE_ADMIN, msg.SUCCESS_ACTIVATE_ADMIN)
+}
+
+func (ac *adminController) setAdminActive(c echo.Context, active bool, failedMessage string, successMessage string) error {
+	adminID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, "Invalid Admin ID format")
+	}
+
+	response, err := ac.adminUsecase.SetAdminActive(c, adminID, ac.tokenUtil.GetClaims(c).ID, active)
+	if err != nil {
+		return ac.handleManageError(c, err, failedMessage)
+	}
+
+	return http_util.HandleSuccessResponse(c, http.StatusOK, successMessage, response)
+}
+
+func (ac *adminController) DeleteAdmin(c echo.Context) error {
+	adminID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, "Invalid Admin ID format")
+	}
+
+	if err := ac.adminUsecase.DeleteAdmin(c, adminID, ac.tokenUtil.GetClaims(c).ID); err != nil {
+		return ac.handleManageError(c, err, msg.FAILED_DELETE_ADMIN)
+	}
+
+	return http_util.HandleSuccessResponse(c, http.StatusOK, msg.SUCCESS_DELETE_ADMIN, nil)
+}
+
+// ChangePassword mengganti password admin yang sedang login
+func (ac *adminController) ChangePassword(c echo.Context) error {
+	request := new(dto.ChangePasswordRequest)
+	if err := c.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := ac.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	claims := ac.tokenUtil.GetClaims(c)
+	err := ac.adminUsecase.ChangePassword(c, claims.ID, claims.SessionID, request)
+	switch {
+	case errors.Is(err, err_util.ErrInvalidCurrentPassword):
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_CURRENT_PASSWORD)
+	case err != nil:
+		return ac.handleManageError(c, err, msg.FAILED_CHANGE_PASSWORD)
+	}
+
+	return http_util.HandleSuccessResponse(c, http.StatusOK, msg.SUCCESS_CHANGE_PASSWORD, nil)
+}
+
+func (ac *adminController) handleManageError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(c, http.StatusNotFound, message)
+	case errors.Is(err, err_util.ErrUnknownRole):
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.UNKNOWN_ROLE)
+	case errors.Is(err, err_util.ErrEmailRegistered):
+		return http_util.HandleErrorResponse(c, http.StatusConflict, msg.EMAIL_ALREADY_REGISTERED)
+	case errors.Is(err, err_util.ErrCannotManageSelf):
+		return http_util.HandleErrorResponse(c, http.StatusConflict, msg.CANNOT_MANAGE_SELF)
+	case errors.Is(err, err_util.ErrSuperAdminRequired):
+		return http_util.HandleErrorResponse(c, http.StatusForbidden, msg.SUPER_ADMIN_REQUIRED)
+	}
+
+	logrus.New().WithError(err).Error(message)
+	return http_util.HandleErrorResponse(c, http.StatusInternalServerError, message)
+}
+
+func (ac *adminController) convertQueryParams(page, limit string) (int, int, error) {
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	intPage, err := strconv.Atoi(page)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	intLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return intPage, intLimit, nil
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	dto "tugas-akhir/dto/auth"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type AuthController struct {
+	sessionUsecase usecases.SessionUsecase
+	validator      *validation.Validator
+	tokenUtil      token.TokenUtil
+}
+
+func NewAuthController(sessionUsecase usecases.SessionUsecase, validator *validation.Validator, tokenUtil token.TokenUtil) *AuthController {
+	return &AuthController{
+		sessionUsecase: sessionUsecase,
+		validator:      validator,
+		tokenUtil:      tokenUtil,
+	}
+}
+
+// Refresh menukar refresh token dengan access token dan refresh token baru
+func (ac *AuthController) Refresh(ctx echo.Context) error {
+	request := new(dto.RefreshRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := ac.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := ac.sessionUsecase.Refresh(ctx, request)
+	switch {
+	case errors.Is(err, err_util.ErrInvalidRefreshToken):
+		return http_util.HandleErrorResponse(ctx, http.StatusUnauthorized, msg.INVALID_REFRESH_TOKEN)
+	case errors.Is(err, err_util.ErrAdminDeactivated):
+		return http_util.HandleErrorResponse(ctx, http.StatusForbidden, msg.ADMIN_DEACTIVATED)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to refresh token")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_REFRESH_TOKEN)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_REFRESH_TOKEN, response)
+}
+
+// Logout mencabut sesi dari access token yang dipakai
+func (ac *AuthController) Logout(ctx echo.Context) error {
+	if err := ac.sessionUsecase.Logout(ctx, ac.tokenUtil.GetClaims(ctx).SessionID); err != nil {
+		logrus.New().WithError(err).Error("Failed to logout")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_LOGOUT)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_LOGOUT, nil)
+}
+
+// LogoutAll mencabut semua sesi akun yang sedang login di semua perangkat
+func (ac *AuthController) LogoutAll(ctx echo.Context) error {
+	response, err := ac.sessionUsecase.LogoutAll(ctx, ac.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to logout all sessions")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_LOGOUT)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_LOGOUT_ALL, response)
+}
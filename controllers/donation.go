@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
@@ -10,8 +11,10 @@ import (
 	dto_base "tugas-akhir/dto/base"
 	dto "tugas-akhir/dto/donation"
 	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
 	http_util "tugas-akhir/utils/http"
-	midtran "tugas-akhir/utils/midtrans"
+	"tugas-akhir/drivers/payment"
+	"tugas-akhir/utils/token"
 	"tugas-akhir/utils/validation"
 
 	"github.com/google/uuid"
@@ -22,14 +25,12 @@ import (
 type DonationController struct {
 	donationUsecase usecases.DonationUsecase
 	validator       *validation.Validator
-	midtransClient  *midtran.Client
 }
 
-func NewDonationController(donationUsecase usecases.DonationUsecase, validator *validation.Validator, midtransClient *midtran.Client) *DonationController {
+func NewDonationController(donationUsecase usecases.DonationUsecase, validator *validation.Validator) *DonationController {
 	return &DonationController{
 		donationUsecase: donationUsecase,
 		validator:       validator,
-		midtransClient:  midtransClient, // Menyuntikkan client Midtrans
 	}
 }
 func (d *DonationController) CreateDonation(c echo.Context) error {
@@ -48,8 +49,14 @@ func (d *DonationController) CreateDonation(c echo.Context) error {
 		return http_util.HandleErrorResponse(c, http.StatusBadRequest, "Invalid data in the request")
 	}
 
+	// Donasi dari donatur yang login langsung tercatat di akunnya
+	request.UserID = loggedInDonorID(c)
+
 	// Call usecase to create the donation
 	response, err := d.donationUsecase.CreateDonation(c, request)
+	if errors.Is(err, err_util.ErrInvalidAllocationAmount) {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_ALLOCATION_AMOUNT)
+	}
 	if err != nil {
 		log.WithError(err).Error("Error creating donation in usecase")
 		return http_util.HandleErrorResponse(c, http.StatusInternalServerError, "Failed to create donation transaction")
@@ -60,6 +67,24 @@ func (d *DonationController) CreateDonation(c echo.Context) error {
 	return http_util.HandleSuccessResponse(c, http.StatusCreated, "Donation created successfully", response)
 }
 
+func (d *DonationController) ResumeDonation(c echo.Context) error {
+	donationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(c, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	response, err := d.donationUsecase.ResumeDonation(c, donationID)
+	switch {
+	case errors.Is(err, err_util.ErrDonationNotResumable):
+		return http_util.HandleErrorResponse(c, http.StatusConflict, msg.DONATION_NOT_RESUMABLE)
+	case err != nil:
+		logrus.New().WithError(err).Error("Error resuming donation payment")
+		return http_util.HandleErrorResponse(c, http.StatusInternalServerError, msg.FAILED_RESUME_DONATION)
+	}
+
+	return http_util.HandleSuccessResponse(c, http.StatusOK, msg.SUCCESS_RESUME_DONATION, response)
+}
+
 func (d *DonationController) MidtransWebhook(c echo.Context) error {
     log := logrus.New()
     log.Info("Received webhook from Midtrans")
@@ -75,7 +100,7 @@ func (d *DonationController) MidtransWebhook(c echo.Context) error {
 
     log.Infof("Raw webhook payload: %s", string(bodyBytes))
 
-    var notification midtran.Notification
+    var notification payment.TransactionStatus
     if err := c.Bind(&notification); err != nil {
         log.WithError(err).Error("Error binding webhook data")
         return c.JSON(http.StatusOK, map[string]interface{}{"status": "error", "message": "Invalid request format"})
@@ -83,17 +108,12 @@ func (d *DonationController) MidtransWebhook(c echo.Context) error {
 
     log.Infof("Notification data: %+v", notification)
 
-    if notification.SignatureKey != "" {
-        if !d.midtransClient.VerifyNotificationSignature(notification) {
-            log.Error("Invalid signature from Midtrans webhook")
-            return c.JSON(http.StatusOK, map[string]interface{}{"status": "error", "message": "Invalid signature"})
-        }
-    } else {
-        log.Warn("Signature key is empty, skipping verification")
-    }
-
-    // Memanggil usecase untuk memproses status donasi dan update currentAmount
+    // Memanggil usecase untuk memverifikasi signature, memproses status donasi dan update currentAmount
     err = d.donationUsecase.UpdateDonationStatus(c, notification)
+    if errors.Is(err, payment.ErrInvalidSignature) {
+        log.Error("Invalid signature from Midtrans webhook")
+        return c.JSON(http.StatusOK, map[string]interface{}{"status": "error", "message": "Invalid signature"})
+    }
     if err != nil {
         log.WithError(err).Error("Failed to update donation status")
         return c.JSON(http.StatusOK, map[string]interface{}{"status": "error", "message": "Failed to update donation status"})
@@ -109,6 +129,7 @@ func (d *DonationController) GetDonations(ctx echo.Context) error {
 	page := strings.TrimSpace(ctx.QueryParam("page"))
 	limit := strings.TrimSpace(ctx.QueryParam("limit"))
 	sortBy := ctx.QueryParam("sort_by")
+	includeExpired, _ := strconv.ParseBool(ctx.QueryParam("include_expired"))
 
 	// Parse ProgramDonationID jika ada
 	var programDonationID uuid.UUID
@@ -132,7 +153,7 @@ func (d *DonationController) GetDonations(ctx echo.Context) error {
 	}
 
 	// Panggil usecase untuk mendapatkan donasi dengan filter atau semua donasi
-	result, metadata, link, err := d.donationUsecase.GetDonations(ctx, programDonationID, searchName, req)
+	result, metadata, link, err := d.donationUsecase.GetDonations(ctx, programDonationID, searchName, includeExpired, req)
 	if err != nil {
 		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONATIONS)
 	}
@@ -185,6 +206,22 @@ func (dc *DonationController) GetDonationByID(ctx echo.Context) error {
     return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_DONATIONS, donation)
 }
 
+// GetPublicDonation mengambil detail donasi untuk halaman publik, misalnya halaman
+// status pembayaran donatur
+func (dc *DonationController) GetPublicDonation(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	donation, err := dc.donationUsecase.GetPublicDonation(ctx, donationID)
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONATIONS)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_DONATIONS, donation)
+}
+
 func (d *DonationController) GetDonationsLanding(ctx echo.Context) error {
 	// Memanggil usecase untuk mendapatkan semua donasi yang berstatus 1
 	donations, err := d.donationUsecase.GetDonationLanding(ctx)
@@ -206,15 +243,6 @@ func (d *DonationController) GetChartDonation(ctx echo.Context) error {
 	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_DONATIONS, donations)
 }
 
-func (d *DonationController) GetDonaturNotifikasi(ctx echo.Context) error {
-	donations, err := d.donationUsecase.GetDonaturNotifikasi(ctx)
-	if err != nil {
-		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONATIONS)
-	}
-
-	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_DONATIONS, donations)
-}
-
 func (d *DonationController) GetDonaturByProgramDonation(ctx echo.Context) error {
 	programDonationID, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
@@ -241,4 +269,28 @@ func (d *DonationController) GetNotifikasi(ctx echo.Context) error {
 	}
 
 	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_DONATIONS, notifications)
-}
\ No newline at end of file
+}
+
+func (d *DonationController) GetDonorDonations(ctx echo.Context) error {
+	intPage, intLimit, err := d.convertQueryParams(strings.TrimSpace(ctx.QueryParam("page")), strings.TrimSpace(ctx.QueryParam("limit")))
+	if err != nil || intLimit < 1 {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	req := &dto_base.PaginationRequest{
+		Page:  intPage,
+		Limit: intLimit,
+	}
+
+	userID := token.NewTokenUtil().GetClaims(ctx).ID
+	result, metadata, link, err := d.donationUsecase.GetDonorDonations(ctx, userID, req)
+	switch {
+	case errors.Is(err, err_util.ErrPageNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.PAGE_NOT_FOUND)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to get donations of donor %s", userID)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONOR_DONATIONS)
+	}
+
+	return http_util.HandlePaginationResponse(ctx, msg.SUCCESS_GET_DONOR_DONATIONS, result, metadata, link)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/drivers/broker"
+	"tugas-akhir/usecases"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Jeda komentar heartbeat agar proxy tidak menutup koneksi stream yang sedang sepi
+const streamHeartbeat = 25 * time.Second
+
+// Jeda pemeriksaan ulang sesi pada stream admin, agar sesi yang dicabut tidak terus
+// menerima event selama koneksi terbuka
+const streamSessionCheck = time.Minute
+
+// Event terakhir sebelum stream admin ditutup karena sesi dicabut atau token kedaluwarsa,
+// sehingga dashboard tahu harus menyambung ulang dengan token baru
+const streamSessionEnded = "session.ended"
+
+type DonationFeedController struct {
+	feedUsecase    usecases.DonationFeedUsecase
+	sessionUsecase usecases.SessionUsecase
+}
+
+func NewDonationFeedController(feedUsecase usecases.DonationFeedUsecase, sessionUsecase usecases.SessionUsecase) *DonationFeedController {
+	return &DonationFeedController{
+		feedUsecase:    feedUsecase,
+		sessionUsecase: sessionUsecase,
+	}
+}
+
+// StreamAdmin mengirim event donasi dibuat, lunas, gagal, dan refund ke dashboard admin.
+// Sesi pemilik token diperiksa ulang secara berkala dan stream ditutup saat token
+// kedaluwarsa, karena policy route hanya memeriksanya saat koneksi dibuka.
+func (fc *DonationFeedController) StreamAdmin(ctx echo.Context) error {
+	claims := token.OptionalClaims(ctx)
+	if claims == nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusUnauthorized, msg.UNAUTHORIZED)
+	}
+	return fc.stream(ctx, false, claims)
+}
+
+// StreamPublic mengirim event "seseorang baru saja berdonasi" tanpa identitas donatur
+func (fc *DonationFeedController) StreamPublic(ctx echo.Context) error {
+	return fc.stream(ctx, true, nil)
+}
+
+// stream mengirim event feed sampai koneksi ditutup. Claims diisi untuk stream admin
+// agar sesi dan masa berlaku tokennya diperiksa selama stream berjalan.
+func (fc *DonationFeedController) stream(ctx echo.Context, public bool, claims *token.JWTClaim) error {
+	events, err := fc.feedUsecase.Subscribe(ctx.Request().Context(), public)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to subscribe donation feed")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_STREAM_DONATIONS)
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	// Stream publik tidak memakai pemeriksaan sesi; channel nil tidak pernah terpilih
+	var sessionCheck, tokenExpiry <-chan time.Time
+	if claims != nil {
+		ticker := time.NewTicker(streamSessionCheck)
+		defer ticker.Stop()
+		sessionCheck = ticker.C
+
+		if claims.ExpiresAt != nil {
+			timer := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+			defer timer.Stop()
+			tokenExpiry = timer.C
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := writeStreamEvent(res, event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-sessionCheck:
+			revoked, err := fc.sessionUsecase.IsSessionRevoked(ctx.Request().Context(), claims.SessionID)
+			if err != nil {
+				// Gagal memeriksa sesi tidak langsung memutus stream, dicoba lagi pada jeda berikutnya
+				logrus.New().WithError(err).Errorf("Failed to check session %s of donation stream", claims.SessionID)
+				continue
+			}
+			if revoked {
+				endStream(res)
+				return nil
+			}
+		case <-tokenExpiry:
+			endStream(res)
+			return nil
+		}
+	}
+}
+
+// endStream mengirim event penutup sebelum stream admin diputus
+func endStream(res *echo.Response) {
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: {}\n\n", streamSessionEnded); err != nil {
+		return
+	}
+	res.Flush()
+}
+
+func writeStreamEvent(res *echo.Response, event broker.Event) error {
+	if _, err := fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Payload); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
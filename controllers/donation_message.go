@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	msg "tugas-akhir/constant/messages"
+	dto_base "tugas-akhir/dto/base"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DonationMessageController struct {
+	messageUsecase usecases.DonationMessageUsecase
+	validator      *validation.Validator
+	tokenUtil      token.TokenUtil
+}
+
+func NewDonationMessageController(messageUsecase usecases.DonationMessageUsecase, validator *validation.Validator, tokenUtil token.TokenUtil) *DonationMessageController {
+	return &DonationMessageController{
+		messageUsecase: messageUsecase,
+		validator:      validator,
+		tokenUtil:      tokenUtil,
+	}
+}
+
+func (mc *DonationMessageController) GetMessages(ctx echo.Context) error {
+	intPage, intLimit, err := mc.convertQueryParams(strings.TrimSpace(ctx.QueryParam("page")), strings.TrimSpace(ctx.QueryParam("limit")))
+	if err != nil || intLimit < 1 {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	req := &dto_base.PaginationRequest{
+		Page:  intPage,
+		Limit: intLimit,
+	}
+
+	result, metadata, link, err := mc.messageUsecase.GetMessages(ctx, ctx.QueryParam("status"), req)
+	switch {
+	case errors.Is(err, err_util.ErrPageNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.PAGE_NOT_FOUND)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to get donation messages")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONATION_MESSAGES)
+	}
+
+	return http_util.HandlePaginationResponse(ctx, msg.SUCCESS_GET_DONATION_MESSAGES, result, metadata, link)
+}
+
+func (mc *DonationMessageController) ApproveMessage(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	response, err := mc.messageUsecase.ApproveMessage(ctx, donationID, mc.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		return mc.handleModerationError(ctx, donationID, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_APPROVE_MESSAGE, response)
+}
+
+func (mc *DonationMessageController) HideMessage(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	response, err := mc.messageUsecase.HideMessage(ctx, donationID, mc.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		return mc.handleModerationError(ctx, donationID, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_HIDE_MESSAGE, response)
+}
+
+func (mc *DonationMessageController) EditMessage(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	request := new(dto.EditMessageRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := mc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := mc.messageUsecase.EditMessage(ctx, donationID, mc.tokenUtil.GetClaims(ctx).ID, request)
+	if err != nil {
+		return mc.handleModerationError(ctx, donationID, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_EDIT_MESSAGE, response)
+}
+
+// GetPrayers adalah dinding doa publik, bisa difilter per program dengan query program_id
+func (mc *DonationMessageController) GetPrayers(ctx echo.Context) error {
+	intPage, intLimit, err := mc.convertQueryParams(strings.TrimSpace(ctx.QueryParam("page")), strings.TrimSpace(ctx.QueryParam("limit")))
+	if err != nil || intLimit < 1 {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	var programID *uuid.UUID
+	if value := ctx.QueryParam("program_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Program ID format")
+		}
+		programID = &id
+	}
+
+	req := &dto_base.PaginationRequest{
+		Page:  intPage,
+		Limit: intLimit,
+	}
+
+	result, metadata, link, err := mc.messageUsecase.GetPrayers(ctx, programID, req)
+	switch {
+	case errors.Is(err, err_util.ErrPageNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.PAGE_NOT_FOUND)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to get prayers")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_PRAYERS)
+	}
+
+	return http_util.HandlePaginationResponse(ctx, msg.SUCCESS_GET_PRAYERS, result, metadata, link)
+}
+
+func (mc *DonationMessageController) handleModerationError(ctx echo.Context, donationID uuid.UUID, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_MODERATE_MESSAGE)
+	}
+
+	logrus.New().WithError(err).Errorf("Failed to moderate message of donation %s", donationID)
+	return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_MODERATE_MESSAGE)
+}
+
+func (mc *DonationMessageController) convertQueryParams(page, limit string) (int, int, error) {
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	intPage, err := strconv.Atoi(page)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	intLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return intPage, intLimit, nil
+}
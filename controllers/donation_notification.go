@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	msg "tugas-akhir/constant/messages"
+	dto_base "tugas-akhir/dto/base"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DonationNotificationController struct {
+	notificationUsecase usecases.DonationNotificationUsecase
+	tokenUtil           token.TokenUtil
+}
+
+func NewDonationNotificationController(notificationUsecase usecases.DonationNotificationUsecase, tokenUtil token.TokenUtil) *DonationNotificationController {
+	return &DonationNotificationController{
+		notificationUsecase: notificationUsecase,
+		tokenUtil:           tokenUtil,
+	}
+}
+
+// GetNotifications mengambil notifikasi donasi masuk dengan cursor pagination.
+// Halaman berikutnya diminta dengan query cursor dari next_cursor.
+func (nc *DonationNotificationController) GetNotifications(ctx echo.Context) error {
+	limit := strings.TrimSpace(ctx.QueryParam("limit"))
+	if limit == "" {
+		limit = "10"
+	}
+	intLimit, err := strconv.Atoi(limit)
+	if err != nil || intLimit < 1 {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	req := &dto_base.CursorPaginationRequest{
+		Cursor: strings.TrimSpace(ctx.QueryParam("cursor")),
+		Limit:  intLimit,
+	}
+
+	notifications, metadata, err := nc.notificationUsecase.GetNotifications(ctx, nc.tokenUtil.GetClaims(ctx).ID, req)
+	switch {
+	case errors.Is(err, err_util.ErrInvalidCursor):
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_CURSOR)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to get donation notifications")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_NOTIFICATIONS)
+	}
+
+	return http_util.HandleCursorPaginationResponse(ctx, msg.SUCCESS_GET_NOTIFICATIONS, notifications, metadata)
+}
+
+func (nc *DonationNotificationController) CountUnread(ctx echo.Context) error {
+	response, err := nc.notificationUsecase.CountUnread(ctx, nc.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to count unread notifications")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_COUNT_NOTIFICATION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_COUNT_NOTIFICATION, response)
+}
+
+func (nc *DonationNotificationController) MarkRead(ctx echo.Context) error {
+	notificationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Notification ID format")
+	}
+
+	err = nc.notificationUsecase.MarkRead(ctx, nc.tokenUtil.GetClaims(ctx).ID, notificationID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_MARK_NOTIFICATION)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to mark notification %s as read", notificationID)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_MARK_NOTIFICATION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_MARK_NOTIFICATION, nil)
+}
+
+func (nc *DonationNotificationController) MarkAllRead(ctx echo.Context) error {
+	response, err := nc.notificationUsecase.MarkAllRead(ctx, nc.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to mark all notifications as read")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_MARK_NOTIFICATION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_MARK_ALL_READ, response)
+}
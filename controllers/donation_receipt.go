@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/entities"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DonationReceiptController struct {
+	receiptUsecase usecases.DonationReceiptUsecase
+	tokenUtil      token.TokenUtil
+}
+
+func NewDonationReceiptController(receiptUsecase usecases.DonationReceiptUsecase, tokenUtil token.TokenUtil) *DonationReceiptController {
+	return &DonationReceiptController{
+		receiptUsecase: receiptUsecase,
+		tokenUtil:      tokenUtil,
+	}
+}
+
+// GetReceipt mengunduh kuitansi donasi apa pun, khusus admin
+func (rc *DonationReceiptController) GetReceipt(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	receipt, document, err := rc.receiptUsecase.GetReceiptPDF(ctx, donationID)
+	return rc.receiptResponse(ctx, donationID, receipt, document, err)
+}
+
+// GetDonorReceipt mengunduh kuitansi donasi milik donatur yang sedang login
+func (rc *DonationReceiptController) GetDonorReceipt(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	userID := rc.tokenUtil.GetClaims(ctx).ID
+	receipt, document, err := rc.receiptUsecase.GetDonorReceiptPDF(ctx, userID, donationID)
+	return rc.receiptResponse(ctx, donationID, receipt, document, err)
+}
+
+func (rc *DonationReceiptController) receiptResponse(ctx echo.Context, donationID uuid.UUID, receipt *entities.DonationReceipt, document []byte, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_GET_RECEIPT)
+	case errors.Is(err, err_util.ErrReceiptNotAvailable):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.RECEIPT_NOT_AVAILABLE)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to get receipt for donation %s", donationID)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_RECEIPT)
+	}
+
+	filename := "kuitansi-" + strings.ReplaceAll(receipt.Number, "/", "-") + ".pdf"
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+	return ctx.Blob(http.StatusOK, "application/pdf", document)
+}
+
+func (rc *DonationReceiptController) VerifyReceipt(ctx echo.Context) error {
+	number := ctx.QueryParam("number")
+	code := ctx.QueryParam("code")
+	if number == "" || code == "" {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := rc.receiptUsecase.VerifyReceipt(ctx, number, code)
+	switch {
+	case errors.Is(err, err_util.ErrInvalidReceipt):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.INVALID_RECEIPT)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to verify receipt")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_RECEIPT)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_VERIFY_RECEIPT, response)
+}
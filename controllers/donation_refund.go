@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type DonationRefundController struct {
+	donationRefundUsecase usecases.DonationRefundUsecase
+	validator             *validation.Validator
+	tokenUtil             token.TokenUtil
+}
+
+func NewDonationRefundController(donationRefundUsecase usecases.DonationRefundUsecase, validator *validation.Validator, tokenUtil token.TokenUtil) *DonationRefundController {
+	return &DonationRefundController{
+		donationRefundUsecase: donationRefundUsecase,
+		validator:             validator,
+		tokenUtil:             tokenUtil,
+	}
+}
+
+func (rc *DonationRefundController) RefundDonation(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	request := new(dto.RefundRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := rc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	adminID := rc.tokenUtil.GetClaims(ctx).ID
+
+	response, err := rc.donationRefundUsecase.RefundDonation(ctx, donationID, adminID, request)
+	switch {
+	case errors.Is(err, err_util.ErrDonationNotRefundable):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.DONATION_NOT_REFUNDABLE)
+	case errors.Is(err, err_util.ErrInvalidRefundAmount):
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REFUND_AMOUNT)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to refund donation")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_REFUND_DONATION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_REFUND_DONATION, response)
+}
+
+func (rc *DonationRefundController) GetRefunds(ctx echo.Context) error {
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	refunds, err := rc.donationRefundUsecase.GetRefunds(ctx, donationID)
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_REFUNDS)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_REFUNDS, refunds)
+}
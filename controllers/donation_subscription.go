@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Header berisi token yang diberikan saat donasi rutin dibuat, dipakai donatur
+// untuk menjeda, melanjutkan, atau membatalkan donasinya
+const subscriptionTokenHeader = "X-Subscription-Token"
+
+type DonationSubscriptionController struct {
+	subscriptionUsecase usecases.DonationSubscriptionUsecase
+	validator           *validation.Validator
+}
+
+func NewDonationSubscriptionController(subscriptionUsecase usecases.DonationSubscriptionUsecase, validator *validation.Validator) *DonationSubscriptionController {
+	return &DonationSubscriptionController{
+		subscriptionUsecase: subscriptionUsecase,
+		validator:           validator,
+	}
+}
+
+func (sc *DonationSubscriptionController) CreateSubscription(ctx echo.Context) error {
+	request := new(dto.SubscriptionRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := sc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	request.UserID = loggedInDonorID(ctx)
+
+	response, err := sc.subscriptionUsecase.CreateSubscription(ctx, request)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to create recurring donation")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_CREATE_SUBSCRIPTION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusCreated, msg.SUCCESS_CREATE_SUBSCRIPTION, response)
+}
+
+func (sc *DonationSubscriptionController) PauseSubscription(ctx echo.Context) error {
+	return sc.changeSubscription(ctx, sc.subscriptionUsecase.PauseSubscription, msg.SUCCESS_PAUSE_SUBSCRIPTION)
+}
+
+func (sc *DonationSubscriptionController) ResumeSubscription(ctx echo.Context) error {
+	return sc.changeSubscription(ctx, sc.subscriptionUsecase.ResumeSubscription, msg.SUCCESS_RESUME_SUBSCRIPTION)
+}
+
+func (sc *DonationSubscriptionController) CancelSubscription(ctx echo.Context) error {
+	return sc.changeSubscription(ctx, sc.subscriptionUsecase.CancelSubscription, msg.SUCCESS_CANCEL_SUBSCRIPTION)
+}
+
+func (sc *DonationSubscriptionController) GetSubscriptions(ctx echo.Context) error {
+	subscriptions, err := sc.subscriptionUsecase.GetSubscriptions(ctx, ctx.QueryParam("status"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_SUBSCRIPTIONS)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_SUBSCRIPTIONS, subscriptions)
+}
+
+func (sc *DonationSubscriptionController) GetDonorSubscriptions(ctx echo.Context) error {
+	subscriptions, err := sc.subscriptionUsecase.GetDonorSubscriptions(ctx, token.NewTokenUtil().GetClaims(ctx).ID)
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_SUBSCRIPTIONS)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_SUBSCRIPTIONS, subscriptions)
+}
+
+func (sc *DonationSubscriptionController) changeSubscription(ctx echo.Context, change func(c echo.Context, subscriptionID uuid.UUID, manageToken string) (*dto.SubscriptionResponse, error), successMessage string) error {
+	subscriptionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Subscription ID format")
+	}
+
+	manageToken := ctx.Request().Header.Get(subscriptionTokenHeader)
+	if manageToken == "" {
+		return http_util.HandleErrorResponse(ctx, http.StatusUnauthorized, msg.INVALID_MANAGE_TOKEN)
+	}
+
+	response, err := change(ctx, subscriptionID, manageToken)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_UPDATE_SUBSCRIPTION)
+	case errors.Is(err, err_util.ErrInvalidManageToken):
+		return http_util.HandleErrorResponse(ctx, http.StatusUnauthorized, msg.INVALID_MANAGE_TOKEN)
+	case errors.Is(err, err_util.ErrSubscriptionState):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.SUBSCRIPTION_STATE_CONFLICT)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to update recurring donation %s", subscriptionID)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_UPDATE_SUBSCRIPTION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, successMessage, response)
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	dto "tugas-akhir/dto/donor"
+	"tugas-akhir/entities"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type DonorController struct {
+	donorUsecase usecases.DonorUsecase
+	validator    *validation.Validator
+	tokenUtil    token.TokenUtil
+}
+
+func NewDonorController(donorUsecase usecases.DonorUsecase, validator *validation.Validator, tokenUtil token.TokenUtil) *DonorController {
+	return &DonorController{
+		donorUsecase: donorUsecase,
+		validator:    validator,
+		tokenUtil:    tokenUtil,
+	}
+}
+
+func (dc *DonorController) Register(ctx echo.Context) error {
+	request := new(dto.RegisterRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := dc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := dc.donorUsecase.Register(ctx, request)
+	switch {
+	case errors.Is(err, err_util.ErrEmailRegistered):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.EMAIL_ALREADY_REGISTERED)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to register donor")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_REGISTER_DONOR)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusCreated, msg.SUCCESS_REGISTER_DONOR, response)
+}
+
+func (dc *DonorController) VerifyEmail(ctx echo.Context) error {
+	request := new(dto.VerifyEmailRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := dc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := dc.donorUsecase.VerifyEmail(ctx, request)
+	if err != nil {
+		return dc.handleVerificationError(ctx, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_VERIFY_EMAIL, response)
+}
+
+func (dc *DonorController) ResendVerification(ctx echo.Context) error {
+	request := new(dto.ResendVerificationRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := dc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	if err := dc.donorUsecase.ResendVerification(ctx, request); err != nil {
+		logrus.New().WithError(err).Error("Failed to resend donor verification")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_VERIFY_DONOR)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_SEND_VERIFICATION, nil)
+}
+
+func (dc *DonorController) Login(ctx echo.Context) error {
+	request := new(dto.LoginRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := dc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := dc.donorUsecase.Login(ctx, request)
+	switch {
+	case errors.Is(err, err_util.ErrPasswordMismatch):
+		return http_util.HandleErrorResponse(ctx, http.StatusUnauthorized, msg.FAILED_LOGIN)
+	case errors.Is(err, err_util.ErrEmailNotVerified):
+		return http_util.HandleErrorResponse(ctx, http.StatusForbidden, msg.EMAIL_NOT_VERIFIED)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to login donor")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_LOGIN)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.LOGIN_SUCCESS, response)
+}
+
+func (dc *DonorController) GetProfile(ctx echo.Context) error {
+	response, err := dc.donorUsecase.GetProfile(ctx, dc.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONOR_PROFILE)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_DONOR_PROFILE, response)
+}
+
+func (dc *DonorController) RequestPhoneVerification(ctx echo.Context) error {
+	err := dc.donorUsecase.RequestPhoneVerification(ctx, dc.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		return dc.handleVerificationError(ctx, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_SEND_VERIFICATION, nil)
+}
+
+func (dc *DonorController) VerifyPhone(ctx echo.Context) error {
+	request := new(dto.VerifyPhoneRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := dc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := dc.donorUsecase.VerifyPhone(ctx, dc.tokenUtil.GetClaims(ctx).ID, request)
+	if err != nil {
+		return dc.handleVerificationError(ctx, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_VERIFY_PHONE, response)
+}
+
+func (dc *DonorController) handleVerificationError(ctx echo.Context, err error) error {
+	if errors.Is(err, err_util.ErrInvalidVerification) {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_VERIFICATION)
+	}
+	if errors.Is(err, err_util.ErrTooManyVerificationAttempts) {
+		return http_util.HandleErrorResponse(ctx, http.StatusTooManyRequests, msg.TOO_MANY_VERIFICATION_ATTEMPTS)
+	}
+
+	logrus.New().WithError(err).Error("Failed to verify donor")
+	return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_VERIFY_DONOR)
+}
+
+// loggedInDonorID mengembalikan ID akun donatur pada route publik yang memakai
+// token.GetOptionalJWTConfig, atau nil untuk donatur tamu
+func loggedInDonorID(ctx echo.Context) *uuid.UUID {
+	claims := token.OptionalClaims(ctx)
+	if claims == nil || claims.Role != entities.RoleDonor {
+		return nil
+	}
+	return &claims.ID
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	msg "tugas-akhir/constant/messages"
+	dto_base "tugas-akhir/dto/base"
+	dto "tugas-akhir/dto/donor"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DonorDirectoryController struct {
+	directoryUsecase usecases.DonorDirectoryUsecase
+	validator        *validation.Validator
+	tokenUtil        token.TokenUtil
+}
+
+func NewDonorDirectoryController(directoryUsecase usecases.DonorDirectoryUsecase, validator *validation.Validator, tokenUtil token.TokenUtil) *DonorDirectoryController {
+	return &DonorDirectoryController{
+		directoryUsecase: directoryUsecase,
+		validator:        validator,
+		tokenUtil:        tokenUtil,
+	}
+}
+
+func (dc *DonorDirectoryController) GetProfiles(ctx echo.Context) error {
+	page, limit := strings.TrimSpace(ctx.QueryParam("page")), strings.TrimSpace(ctx.QueryParam("limit"))
+	if page == "" {
+		page = "1"
+	}
+	if limit == "" {
+		limit = "10"
+	}
+
+	intPage, errPage := strconv.Atoi(page)
+	intLimit, errLimit := strconv.Atoi(limit)
+	if errPage != nil || errLimit != nil || intLimit < 1 {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	req := &dto_base.PaginationRequest{
+		Page:  intPage,
+		Limit: intLimit,
+	}
+
+	result, metadata, link, err := dc.directoryUsecase.GetProfiles(ctx, strings.TrimSpace(ctx.QueryParam("search")), req)
+	switch {
+	case errors.Is(err, err_util.ErrPageNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.PAGE_NOT_FOUND)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to get donor profiles")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONOR_PROFILES)
+	}
+
+	return http_util.HandlePaginationResponse(ctx, msg.SUCCESS_GET_DONOR_PROFILES, result, metadata, link)
+}
+
+func (dc *DonorDirectoryController) ExportProfiles(ctx echo.Context) error {
+	document, err := dc.directoryUsecase.ExportProfiles(ctx)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to export donor profiles")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_EXPORT_DONOR_PROFILES)
+	}
+
+	filename := "donatur-" + time.Now().Format("20060102") + ".csv"
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return ctx.Blob(http.StatusOK, "text/csv", document)
+}
+
+func (dc *DonorDirectoryController) GetDuplicates(ctx echo.Context) error {
+	duplicates, err := dc.directoryUsecase.GetDuplicates(ctx, ctx.QueryParam("status"))
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to get suspected duplicate donors")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_DONOR_DUPLICATES)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_DONOR_DUPLICATES, duplicates)
+}
+
+func (dc *DonorDirectoryController) MergeProfiles(ctx echo.Context) error {
+	targetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donor Profile ID format")
+	}
+
+	request := new(dto.MergeProfileRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := dc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	adminID := dc.tokenUtil.GetClaims(ctx).ID
+	response, err := dc.directoryUsecase.MergeProfiles(ctx, targetID, uuid.MustParse(request.SourceID), adminID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_MERGE_DONOR_PROFILES)
+	case errors.Is(err, err_util.ErrInvalidDonorMerge):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.INVALID_DONOR_MERGE)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to merge donor profile %s into %s", request.SourceID, targetID)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_MERGE_DONOR_PROFILES)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_MERGE_DONOR_PROFILES, response)
+}
+
+func (dc *DonorDirectoryController) DismissDuplicate(ctx echo.Context) error {
+	duplicateID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Duplicate ID format")
+	}
+
+	response, err := dc.directoryUsecase.DismissDuplicate(ctx, duplicateID, dc.tokenUtil.GetClaims(ctx).ID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_DISMISS_DONOR_DUPLICATE)
+	case errors.Is(err, err_util.ErrDonorDuplicateReviewed):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.DONOR_DUPLICATE_REVIEWED)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to dismiss suspected duplicate donor %s", duplicateID)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_DISMISS_DONOR_DUPLICATE)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_DISMISS_DONOR_DUPLICATE, response)
+}
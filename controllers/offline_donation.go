@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/drivers/cloudinary"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type OfflineDonationController struct {
+	offlineDonationUsecase usecases.OfflineDonationUsecase
+	validator              *validation.Validator
+	cloudinaryService      cloudinary.CloudinaryService
+	tokenUtil              token.TokenUtil
+}
+
+func NewOfflineDonationController(offlineDonationUsecase usecases.OfflineDonationUsecase, validator *validation.Validator, cloudinaryService cloudinary.CloudinaryService, tokenUtil token.TokenUtil) *OfflineDonationController {
+	return &OfflineDonationController{
+		offlineDonationUsecase: offlineDonationUsecase,
+		validator:              validator,
+		cloudinaryService:      cloudinaryService,
+		tokenUtil:              tokenUtil,
+	}
+}
+
+func (oc *OfflineDonationController) RecordOfflineDonation(ctx echo.Context) error {
+	logger := logrus.New()
+
+	request := new(dto.OfflineDonationRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := oc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	// Foto bukti bersifat opsional untuk donasi offline
+	if file, err := ctx.FormFile("proof"); err == nil {
+		src, err := file.Open()
+		if err != nil {
+			return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+		}
+		defer src.Close()
+
+		secureURL, err := oc.cloudinaryService.UploadImage(ctx.Request().Context(), src, "artanita/donation/proof")
+		if err != nil {
+			logger.Error("Failed to upload proof to Cloudinary: ", err)
+			return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+		}
+		request.ProofImageURL = secureURL
+	}
+
+	adminID := oc.tokenUtil.GetClaims(ctx).ID
+
+	response, err := oc.offlineDonationUsecase.RecordOfflineDonation(ctx, adminID, request)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record offline donation")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_RECORD_OFFLINE_DONATION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusCreated, msg.SUCCESS_RECORD_OFFLINE_DONATION, response)
+}
+
+func (oc *OfflineDonationController) SubmitTransferProof(ctx echo.Context) error {
+	logger := logrus.New()
+
+	donationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Donation ID format")
+	}
+
+	request := new(dto.TransferProofRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := oc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	file, err := ctx.FormFile("image")
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+	src, err := file.Open()
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+	defer src.Close()
+
+	secureURL, err := oc.cloudinaryService.UploadImage(ctx.Request().Context(), src, "artanita/donation/proof")
+	if err != nil {
+		logger.Error("Failed to upload transfer proof to Cloudinary: ", err)
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+	request.ImageURL = secureURL
+
+	response, err := oc.offlineDonationUsecase.SubmitTransferProof(ctx, donationID, request)
+	switch {
+	case errors.Is(err, err_util.ErrDonationNotResumable):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.DONATION_NOT_RESUMABLE)
+	case err != nil:
+		logger.WithError(err).Error("Failed to submit transfer proof")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_SUBMIT_TRANSFER_PROOF)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusCreated, msg.SUCCESS_SUBMIT_TRANSFER_PROOF, response)
+}
+
+func (oc *OfflineDonationController) GetTransferProofs(ctx echo.Context) error {
+	proofs, err := oc.offlineDonationUsecase.GetTransferProofs(ctx, ctx.QueryParam("status"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_TRANSFER_PROOFS)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_TRANSFER_PROOFS, proofs)
+}
+
+func (oc *OfflineDonationController) ApproveTransferProof(ctx echo.Context) error {
+	proofID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Transfer Proof ID format")
+	}
+
+	adminID := oc.tokenUtil.GetClaims(ctx).ID
+
+	response, err := oc.offlineDonationUsecase.ApproveTransferProof(ctx, proofID, adminID)
+	if err != nil {
+		return oc.handleReviewError(ctx, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_APPROVE_TRANSFER_PROOF, response)
+}
+
+func (oc *OfflineDonationController) RejectTransferProof(ctx echo.Context) error {
+	proofID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Transfer Proof ID format")
+	}
+
+	request := new(dto.RejectTransferProofRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := oc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	adminID := oc.tokenUtil.GetClaims(ctx).ID
+
+	response, err := oc.offlineDonationUsecase.RejectTransferProof(ctx, proofID, adminID, request)
+	if err != nil {
+		return oc.handleReviewError(ctx, err)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_REJECT_TRANSFER_PROOF, response)
+}
+
+func (oc *OfflineDonationController) handleReviewError(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_REVIEW_TRANSFER_PROOF)
+	case errors.Is(err, err_util.ErrTransferProofReviewed):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.TRANSFER_PROOF_ALREADY_REVIEWED)
+	case errors.Is(err, err_util.ErrIllegalStatusTransition):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.ILLEGAL_STATUS_TRANSITION)
+	}
+
+	logrus.New().WithError(err).Error("Failed to review transfer proof")
+	return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_REVIEW_TRANSFER_PROOF)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type ReconciliationController struct {
+	reconciliationUsecase usecases.ReconciliationUsecase
+}
+
+func NewReconciliationController(reconciliationUsecase usecases.ReconciliationUsecase) *ReconciliationController {
+	return &ReconciliationController{
+		reconciliationUsecase: reconciliationUsecase,
+	}
+}
+
+func (rc *ReconciliationController) RunReconciliation(ctx echo.Context) error {
+	report, err := rc.reconciliationUsecase.RunNow(ctx)
+	if errors.Is(err, err_util.ErrReconciliationRunning) {
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.RECONCILIATION_ALREADY_RUNNING)
+	}
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to run reconciliation")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_RUN_RECONCILIATION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_RUN_RECONCILIATION, report)
+}
+
+func (rc *ReconciliationController) GetLastReconciliation(ctx echo.Context) error {
+	report, err := rc.reconciliationUsecase.GetLastRun(ctx)
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_RECONCILIATION)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_RECONCILIATION, report)
+}
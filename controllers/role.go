@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	dto "tugas-akhir/dto/admin"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RoleController struct {
+	roleUsecase usecases.RoleUsecase
+	validator   *validation.Validator
+	tokenUtil   token.TokenUtil
+}
+
+func NewRoleController(roleUsecase usecases.RoleUsecase, validator *validation.Validator, tokenUtil token.TokenUtil) *RoleController {
+	return &RoleController{
+		roleUsecase: roleUsecase,
+		validator:   validator,
+		tokenUtil:   tokenUtil,
+	}
+}
+
+func (rc *RoleController) GetRoles(ctx echo.Context) error {
+	roles, err := rc.roleUsecase.GetRoles(ctx)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to get admin roles")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_ROLES)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_ROLES, roles)
+}
+
+func (rc *RoleController) GetPermissions(ctx echo.Context) error {
+	permissions, err := rc.roleUsecase.GetAllPermissions(ctx)
+	if err != nil {
+		logrus.New().WithError(err).Error("Failed to get admin permissions")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_PERMISSIONS)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_PERMISSIONS, permissions)
+}
+
+func (rc *RoleController) UpdateRolePermissions(ctx echo.Context) error {
+	request := new(dto.UpdateRolePermissionsRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := rc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	name := ctx.Param("name")
+	response, err := rc.roleUsecase.UpdateRolePermissions(ctx, name, request)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.UNKNOWN_ROLE)
+	case errors.Is(err, err_util.ErrUnknownPermission):
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.UNKNOWN_PERMISSION)
+	case errors.Is(err, err_util.ErrRoleLocked):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.ROLE_LOCKED)
+	case errors.Is(err, err_util.ErrPermissionReserved):
+		return http_util.HandleErrorResponse(ctx, http.StatusForbidden, msg.PERMISSION_RESERVED)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to update permissions of role %s", name)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_UPDATE_ROLE)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_UPDATE_ROLE, response)
+}
+
+func (rc *RoleController) AssignRole(ctx echo.Context) error {
+	adminID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, "Invalid Admin ID format")
+	}
+
+	request := new(dto.AssignRoleRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := rc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := rc.roleUsecase.AssignRole(ctx, adminID, rc.tokenUtil.GetClaims(ctx).ID, request)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.FAILED_ASSIGN_ROLE)
+	case errors.Is(err, err_util.ErrUnknownRole):
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.UNKNOWN_ROLE)
+	case errors.Is(err, err_util.ErrCannotChangeOwnRole):
+		return http_util.HandleErrorResponse(ctx, http.StatusConflict, msg.CANNOT_CHANGE_OWN_ROLE)
+	case errors.Is(err, err_util.ErrSuperAdminRequired):
+		return http_util.HandleErrorResponse(ctx, http.StatusForbidden, msg.SUPER_ADMIN_REQUIRED)
+	case err != nil:
+		logrus.New().WithError(err).Errorf("Failed to assign role to admin %s", adminID)
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_ASSIGN_ROLE)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_ASSIGN_ROLE, response)
+}
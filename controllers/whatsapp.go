@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/drivers/whatsapp"
+	dto_base "tugas-akhir/dto/base"
+	dto_donor "tugas-akhir/dto/donor"
+	dto "tugas-akhir/dto/whatsapp"
+	"tugas-akhir/usecases"
+	err_util "tugas-akhir/utils/error"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/phone"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type WhatsAppController struct {
+	whatsAppUsecase usecases.WhatsAppUsecase
+	validator       *validation.Validator
+	tokenUtil       token.TokenUtil
+	webhookToken    string
+}
+
+func NewWhatsAppController(whatsAppUsecase usecases.WhatsAppUsecase, validator *validation.Validator, tokenUtil token.TokenUtil, webhookToken string) *WhatsAppController {
+	return &WhatsAppController{
+		whatsAppUsecase: whatsAppUsecase,
+		validator:       validator,
+		tokenUtil:       tokenUtil,
+		webhookToken:    webhookToken,
+	}
+}
+
+// Webhook menerima laporan status pengiriman dan balasan donatur dari gateway. Gateway
+// harus mengirim WHATSAPP_WEBHOOK_TOKEN pada header X-Webhook-Token, webhook ditolak
+// selama token belum dikonfigurasi.
+func (wc *WhatsAppController) Webhook(ctx echo.Context) error {
+	received := ctx.Request().Header.Get(whatsapp.WebhookTokenHeader)
+	if wc.webhookToken == "" || subtle.ConstantTimeCompare([]byte(received), []byte(wc.webhookToken)) != 1 {
+		return http_util.HandleErrorResponse(ctx, http.StatusUnauthorized, msg.UNAUTHORIZED)
+	}
+
+	request := new(dto.WebhookRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := wc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	err := wc.whatsAppUsecase.HandleWebhook(ctx, request)
+	switch {
+	case errors.Is(err, phone.ErrInvalidNumber):
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to handle WhatsApp webhook")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_HANDLE_WHATSAPP_WEBHOOK)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_HANDLE_WHATSAPP_WEBHOOK, nil)
+}
+
+// GetMessages adalah riwayat pesan WhatsApp untuk admin, bisa difilter dengan query status
+func (wc *WhatsAppController) GetMessages(ctx echo.Context) error {
+	intPage, intLimit, err := wc.convertQueryParams(strings.TrimSpace(ctx.QueryParam("page")), strings.TrimSpace(ctx.QueryParam("limit")))
+	if err != nil || intLimit < 1 {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	req := &dto_base.PaginationRequest{
+		Page:  intPage,
+		Limit: intLimit,
+	}
+
+	result, metadata, link, err := wc.whatsAppUsecase.GetMessages(ctx, ctx.QueryParam("status"), req)
+	switch {
+	case errors.Is(err, err_util.ErrPageNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, msg.PAGE_NOT_FOUND)
+	case err != nil:
+		logrus.New().WithError(err).Error("Failed to get WhatsApp messages")
+		return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, msg.FAILED_GET_WHATSAPP_MESSAGES)
+	}
+
+	return http_util.HandlePaginationResponse(ctx, msg.SUCCESS_GET_WHATSAPP_MESSAGES, result, metadata, link)
+}
+
+func (wc *WhatsAppController) GetPreference(ctx echo.Context) error {
+	response, err := wc.whatsAppUsecase.GetPreference(ctx, wc.tokenUtil.GetClaims(ctx).ID)
+	if err != nil {
+		return wc.handlePreferenceError(ctx, err, msg.FAILED_GET_WHATSAPP_PREFERENCE)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_GET_WHATSAPP_PREFERENCE, response)
+}
+
+func (wc *WhatsAppController) UpdatePreference(ctx echo.Context) error {
+	request := new(dto_donor.WhatsAppPreferenceRequest)
+	if err := ctx.Bind(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.MISMATCH_DATA_TYPE)
+	}
+
+	if err := wc.validator.Validate(request); err != nil {
+		return http_util.HandleErrorResponse(ctx, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+	}
+
+	response, err := wc.whatsAppUsecase.UpdatePreference(ctx, wc.tokenUtil.GetClaims(ctx).ID, request)
+	if err != nil {
+		return wc.handlePreferenceError(ctx, err, msg.FAILED_SET_WHATSAPP_PREFERENCE)
+	}
+
+	return http_util.HandleSuccessResponse(ctx, http.StatusOK, msg.SUCCESS_SET_WHATSAPP_PREFERENCE, response)
+}
+
+func (wc *WhatsAppController) handlePreferenceError(ctx echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, err_util.ErrWhatsAppNumberRequired):
+		return http_util.HandleErrorResponse(ctx, http.StatusUnprocessableEntity, msg.WHATSAPP_NUMBER_REQUIRED)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http_util.HandleErrorResponse(ctx, http.StatusNotFound, message)
+	}
+
+	logrus.New().WithError(err).Error(message)
+	return http_util.HandleErrorResponse(ctx, http.StatusInternalServerError, message)
+}
+
+func (wc *WhatsAppController) convertQueryParams(page, limit string) (int, int, error) {
+	if page == "" {
+		page = "1"
+	}
+
+	if limit == "" {
+		limit = "10"
+	}
+
+	intPage, err := strconv.Atoi(page)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	intLimit, err := strconv.Atoi(limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return intPage, intLimit, nil
+}
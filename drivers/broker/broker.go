@@ -0,0 +1,80 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Jumlah event yang ditampung untuk setiap pelanggan. Pelanggan yang terlalu lambat
+// kehilangan event berikutnya alih-alih menahan publisher.
+const subscriberBuffer = 32
+
+// Event adalah pesan yang dikirim lewat broker. Payload disimpan sebagai JSON agar
+// broker lain (misalnya Redis pub/sub) bisa meneruskannya apa adanya.
+type Event struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	Time    time.Time       `json:"time"`
+}
+
+// Broker meneruskan event dari publisher ke semua pelanggan sebuah topik
+type Broker interface {
+	Publish(ctx context.Context, topic string, event Event) error
+	// Subscribe mengembalikan channel event topik. Channel ditutup saat ctx selesai.
+	Subscribe(ctx context.Context, topic string) (<-chan Event, error)
+}
+
+type memoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+// NewMemoryBroker membuat broker dalam proses. Event hanya sampai ke pelanggan pada
+// instance aplikasi yang sama.
+func NewMemoryBroker() Broker {
+	return &memoryBroker{
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, topic string, event Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for subscriber := range b.subscribers[topic] {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(ctx context.Context, topic string) (<-chan Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	subscriber := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan Event]struct{})
+	}
+	b.subscribers[topic][subscriber] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subscribers[topic], subscriber)
+		b.mu.Unlock()
+		close(subscriber)
+	}()
+	return subscriber, nil
+}
@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	msg "tugas-akhir/constant/messages"
 	"tugas-akhir/entities"
 	log_util "tugas-akhir/utils/logger"
+	"tugas-akhir/utils/password"
+	"tugas-akhir/utils/phone"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -23,6 +30,12 @@ type Config struct {
 	DB_SSL       string
 	DB_TZ        string
 	DB_LOG_LEVEL string
+
+	// Super admin pertama yang dibuat saat database belum memiliki super admin aktif.
+	// Dipakai pada instalasi baru karena pendaftaran admin hanya lewat undangan.
+	ADMIN_BOOTSTRAP_NAME     string
+	ADMIN_BOOTSTRAP_EMAIL    string
+	ADMIN_BOOTSTRAP_PASSWORD string
 }
 
 func ConnectDB(config Config) *gorm.DB {
@@ -44,16 +57,24 @@ func ConnectDB(config Config) *gorm.DB {
 		ParameterizedQueries: true,
 	})
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger,
+		Logger:         logger,
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatal(msg.FAILED_CONNECT_DB, err)
 	}
-	migrate(db)
+	migrate(db, config)
 	return db
 }
 
-func migrate(db *gorm.DB) {
+func migrate(db *gorm.DB, config Config) {
+	if err := migratePhoneNumbers(db); err != nil {
+		log.Fatal(msg.FAILED_MIGRATE_DB, err)
+	}
+	if err := migrateAdminEmails(db); err != nil {
+		log.Fatal(msg.FAILED_MIGRATE_DB, err)
+	}
+
 	err := db.AutoMigrate(
 		&entities.User{},
 		&entities.OrphanageActivity{},
@@ -65,8 +86,181 @@ func migrate(db *gorm.DB) {
 		&entities.ProgramDonationImage{},
 		&entities.Donation{},
 		&entities.TransactionNotification{},
+		&entities.ReconciliationRun{},
+		&entities.ReconciliationItem{},
+		&entities.DonationRefund{},
+		&entities.TransferProof{},
+		&entities.DonationAllocation{},
+		&entities.DonationSubscription{},
+		&entities.DonationReceipt{},
+		&entities.ReceiptSequence{},
+		&entities.DonorVerification{},
+		&entities.DonorProfile{},
+		&entities.DonorIdentifier{},
+		&entities.DonorDuplicate{},
+		&entities.NotificationRead{},
+		&entities.WhatsAppMessage{},
+		&entities.WhatsAppOptOut{},
+		&entities.Role{},
+		&entities.Permission{},
+		&entities.RolePermission{},
+		&entities.AdminInvitation{},
+		&entities.Session{},
 	)
 	if err != nil {
 		log.Fatal(msg.FAILED_MIGRATE_DB, err)
 	}
+
+	if err := seedRoles(db); err != nil {
+		log.Fatal(msg.FAILED_MIGRATE_DB, err)
+	}
+	if err := bootstrapAdmin(db, config); err != nil {
+		log.Fatal(msg.FAILED_MIGRATE_DB, err)
+	}
+}
+
+// seedRoles mengisi role dan hak akses bawaan. Hak akses awal sebuah role hanya diisi
+// saat role tersebut baru dibuat, sehingga perubahan oleh super admin tetap dipakai.
+// Admin lama dengan role "admin" menjadi super admin karena dulu memiliki semua akses.
+func seedRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entities.DefaultPermissions).Error; err != nil {
+			return err
+		}
+
+		for _, defaultRole := range entities.DefaultRoles {
+			role := defaultRole.Role
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&role)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 || len(defaultRole.Permissions) == 0 {
+				continue
+			}
+
+			rolePermissions := make([]entities.RolePermission, 0, len(defaultRole.Permissions))
+			for _, permission := range defaultRole.Permissions {
+				rolePermissions = append(rolePermissions, entities.RolePermission{RoleName: role.Name, PermissionName: permission})
+			}
+			if err := tx.Create(&rolePermissions).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&entities.Admin{}).Where("role = ?", "admin").Update("role", entities.RoleSuperAdmin).Error
+	})
+}
+
+// bootstrapAdmin membuat super admin dari ADMIN_BOOTSTRAP_EMAIL dan ADMIN_BOOTSTRAP_PASSWORD
+// jika belum ada super admin aktif, karena pendaftaran admin lain hanya lewat undangan
+func bootstrapAdmin(db *gorm.DB, config Config) error {
+	var count int64
+	err := db.Model(&entities.Admin{}).
+		Where("role = ? AND deactivated_at IS NULL", entities.RoleSuperAdmin).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(config.ADMIN_BOOTSTRAP_EMAIL))
+	if email == "" || config.ADMIN_BOOTSTRAP_PASSWORD == "" {
+		log.Print("No active super admin found, set ADMIN_BOOTSTRAP_EMAIL and ADMIN_BOOTSTRAP_PASSWORD to create one")
+		return nil
+	}
+
+	hashedPassword, err := password.NewPasswordUtil().HashPassword(config.ADMIN_BOOTSTRAP_PASSWORD)
+	if err != nil {
+		return err
+	}
+
+	admin := entities.Admin{
+		ID:        uuid.New(),
+		Name:      config.ADMIN_BOOTSTRAP_NAME,
+		Role:      entities.RoleSuperAdmin,
+		Email:     email,
+		Password:  hashedPassword,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := db.Create(&admin).Error; err != nil {
+		return err
+	}
+	log.Printf("Created bootstrap super admin %s", email)
+	return nil
+}
+
+// migrateAdminEmails menyeragamkan email admin menjadi huruf kecil sebelum AutoMigrate
+// membuat indeks unik email. Email yang sama dengan huruf berbeda harus dirapikan manual
+// karena tidak bisa dipilih otomatis akun mana yang dipertahankan.
+func migrateAdminEmails(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&entities.Admin{}) {
+		return nil
+	}
+
+	var duplicates []string
+	err := db.Model(&entities.Admin{}).
+		Select("lower(trim(email))").
+		Group("lower(trim(email))").
+		Having("count(*) > 1").
+		Pluck("lower(trim(email))", &duplicates).Error
+	if err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate admin emails must be resolved before migrating: %s", strings.Join(duplicates, ", "))
+	}
+
+	return db.Exec("UPDATE admins SET email = lower(trim(email)) WHERE email <> lower(trim(email))").Error
+}
+
+// Tabel yang dulu menyimpan nomor WhatsApp sebagai int
+var phoneNumberTables = []string{"users", "donations", "donation_subscriptions", "donor_profiles"}
+
+// migratePhoneNumbers mengubah kolom no_wa bertipe int menjadi teks E.164 sebelum
+// AutoMigrate berjalan, karena AutoMigrate hanya mengganti tipe kolom tanpa menambahkan
+// kode negara. Kolom diubah ke teks apa adanya lalu setiap nomor dikonversi dengan
+// phone.FromLegacy.
+func migratePhoneNumbers(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range phoneNumberTables {
+			var dataType string
+			err := tx.Raw(`SELECT data_type FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = ? AND column_name = 'no_wa'`, table).
+				Scan(&dataType).Error
+			if err != nil {
+				return err
+			}
+			if dataType != "integer" && dataType != "bigint" {
+				continue
+			}
+
+			err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN no_wa TYPE text USING coalesce(no_wa, 0)::text", table)).Error
+			if err != nil {
+				return err
+			}
+
+			// Nilai lama masih berupa angka tanpa tanda +, sehingga tidak bisa tertukar
+			// dengan nomor yang sudah dikonversi
+			var legacyNumbers []string
+			if err := tx.Table(table).Distinct("no_wa").Pluck("no_wa", &legacyNumbers).Error; err != nil {
+				return err
+			}
+			for _, legacy := range legacyNumbers {
+				number, err := strconv.Atoi(legacy)
+				if err != nil {
+					return fmt.Errorf("invalid legacy phone number %q in %s: %w", legacy, table, err)
+				}
+				err = tx.Exec(fmt.Sprintf("UPDATE %s SET no_wa = ? WHERE no_wa = ?", table), phone.FromLegacy(number).String(), legacy).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN no_wa TYPE varchar(16)", table)).Error; err != nil {
+				return err
+			}
+			log.Printf("Converted %d %s.no_wa values to E.164 phone numbers", len(legacyNumbers), table)
+		}
+		return nil
+	})
 }
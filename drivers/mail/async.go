@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Percobaan pengiriman untuk setiap email sebelum dianggap gagal
+const sendAttempts = 3
+
+var ErrQueueFull = errors.New("mail queue is full")
+
+type asyncTransport struct {
+	transport Transport
+	queue     chan Message
+}
+
+// NewAsyncTransport memasukkan email ke antrean dan mengirimnya di goroutine terpisah.
+// Send langsung kembali sehingga request HTTP dan webhook tidak menunggu server email.
+func NewAsyncTransport(transport Transport, queueSize int, workers int) Transport {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	t := &asyncTransport{
+		transport: transport,
+		queue:     make(chan Message, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go t.work()
+	}
+	return t
+}
+
+func (t *asyncTransport) Name() string {
+	return t.transport.Name()
+}
+
+// Send tidak memakai ctx pemanggil untuk pengiriman, karena context request sudah
+// selesai sebelum email terkirim
+func (t *asyncTransport) Send(ctx context.Context, message Message) error {
+	select {
+	case t.queue <- message:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (t *asyncTransport) work() {
+	for message := range t.queue {
+		t.deliver(message)
+	}
+}
+
+func (t *asyncTransport) deliver(message Message) {
+	var err error
+	for attempt := 1; attempt <= sendAttempts; attempt++ {
+		if err = t.transport.Send(context.Background(), message); err == nil {
+			return
+		}
+		if attempt < sendAttempts {
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+		}
+	}
+
+	logrus.New().WithError(err).Errorf("Failed to send email %q to %s via %s", message.Subject, strings.Join(message.To, ", "), t.transport.Name())
+}
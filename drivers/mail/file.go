@@ -0,0 +1,79 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"tugas-akhir/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fileTransport struct {
+	config config.MailConfig
+}
+
+// NewFileTransport menyimpan setiap email sebagai file .eml di MAIL_FILE_DIR untuk
+// pengembangan. File dapat dibuka langsung dengan aplikasi email.
+func NewFileTransport(mailConfig config.MailConfig) Transport {
+	return &fileTransport{
+		config: mailConfig,
+	}
+}
+
+func (t *fileTransport) Name() string {
+	return "file"
+}
+
+func (t *fileTransport) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := build(fromAddress(t.config), message)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(t.config.FileDir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), slug(message.Subject))
+	return os.WriteFile(filepath.Join(t.config.FileDir, name), data, 0o644)
+}
+
+type logTransport struct{}
+
+// NewLogTransport hanya mencatat penerima dan subjek email ke log
+func NewLogTransport() Transport {
+	return &logTransport{}
+}
+
+func (t *logTransport) Name() string {
+	return "log"
+}
+
+func (t *logTransport) Send(ctx context.Context, message Message) error {
+	logrus.New().Infof("Email to %s: %s (%d attachments)", strings.Join(message.To, ", "), message.Subject, len(message.Attachments))
+	return nil
+}
+
+// slug membuat potongan nama file yang aman dari subjek email
+func slug(subject string) string {
+	var out strings.Builder
+	for _, r := range strings.ToLower(subject) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			out.WriteRune(r)
+		case out.Len() > 0 && !strings.HasSuffix(out.String(), "-"):
+			out.WriteByte('-')
+		}
+		if out.Len() >= 40 {
+			break
+		}
+	}
+	return strings.Trim(out.String(), "-")
+}
@@ -0,0 +1,187 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+	"tugas-akhir/config"
+)
+
+// Attachment adalah lampiran email, misalnya PDF kuitansi
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message adalah satu email. Text dan HTML dikirim sebagai multipart/alternative
+// sehingga klien email memilih tampilan yang didukungnya.
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Transport mengirim email lewat satu kanal pengiriman
+type Transport interface {
+	Send(ctx context.Context, message Message) error
+	Name() string
+}
+
+// NewTransport memilih transport sesuai MAIL_TRANSPORT lalu membungkusnya dengan antrean
+// asinkron, sehingga pemanggil tidak pernah menunggu server email.
+func NewTransport(mailConfig config.MailConfig) Transport {
+	var transport Transport
+	switch mailConfig.Transport {
+	case "smtp":
+		transport = NewSMTPTransport(mailConfig)
+	case "file":
+		transport = NewFileTransport(mailConfig)
+	case "log":
+		transport = NewLogTransport()
+	default:
+		log.Printf("Unknown mail transport %q, falling back to log", mailConfig.Transport)
+		transport = NewLogTransport()
+	}
+
+	return NewAsyncTransport(transport, mailConfig.QueueSize, mailConfig.Workers)
+}
+
+// build menyusun email lengkap dengan header dalam format MIME
+func build(from string, message Message) ([]byte, error) {
+	var out bytes.Buffer
+	body := multipart.NewWriter(&out)
+	contentType := "multipart/alternative"
+	if len(message.Attachments) > 0 {
+		contentType = "multipart/mixed"
+	}
+
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "From: %s\r\n", from)
+	fmt.Fprintf(&head, "To: %s\r\n", strings.Join(message.To, ", "))
+	fmt.Fprintf(&head, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&head, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&head, "Message-ID: %s\r\n", messageID(from))
+	head.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&head, "Content-Type: %s; boundary=%s\r\n\r\n", contentType, body.Boundary())
+
+	if len(message.Attachments) == 0 {
+		if err := writeAlternative(body, message); err != nil {
+			return nil, err
+		}
+		return append(head.Bytes(), out.Bytes()...), nil
+	}
+
+	// Dengan lampiran, isi teks dan HTML menjadi satu bagian multipart/alternative
+	var parts bytes.Buffer
+	alternative := multipart.NewWriter(&parts)
+	if err := writeAlternative(alternative, message); err != nil {
+		return nil, err
+	}
+	part, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(parts.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range message.Attachments {
+		if err := writeAttachment(body, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return append(head.Bytes(), out.Bytes()...), nil
+}
+
+// writeAlternative menulis isi teks dan HTML lalu menutup writer
+func writeAlternative(writer *multipart.Writer, message Message) error {
+	contents := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	}
+	for _, content := range contents {
+		if content.content == "" {
+			continue
+		}
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {content.contentType},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(wrapBase64([]byte(content.content))); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func writeAttachment(writer *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(wrapBase64(attachment.Data))
+	return err
+}
+
+// wrapBase64 memecah base64 per 76 karakter sesuai batas panjang baris email
+func wrapBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var out bytes.Buffer
+	for len(encoded) > 76 {
+		out.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	out.WriteString(encoded + "\r\n")
+	return out.Bytes()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if address, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(address.Address, "@"); at >= 0 {
+			domain = address.Address[at+1:]
+		}
+	}
+
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain)
+}
+
+// fromAddress menyusun header From dari nama dan alamat pengirim
+func fromAddress(mailConfig config.MailConfig) string {
+	return (&mail.Address{Name: mailConfig.FromName, Address: mailConfig.From}).String()
+}
@@ -0,0 +1,92 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+	"tugas-akhir/config"
+)
+
+// Batas waktu satu sesi SMTP, dari membuka koneksi sampai email diterima server
+const smtpTimeout = 30 * time.Second
+
+type smtpTransport struct {
+	config config.MailConfig
+}
+
+// NewSMTPTransport mengirim email ke server SMTP. STARTTLS dipakai jika server
+// mendukungnya dan login hanya dilakukan jika SMTP_USERNAME diisi, sehingga bisa
+// langsung dipakai dengan server uji lokal seperti MailHog.
+func NewSMTPTransport(mailConfig config.MailConfig) Transport {
+	return &smtpTransport{
+		config: mailConfig,
+	}
+}
+
+func (t *smtpTransport) Name() string {
+	return "smtp"
+}
+
+func (t *smtpTransport) Send(ctx context.Context, message Message) error {
+	from := fromAddress(t.config)
+	data, err := build(from, message)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	address := net.JoinHostPort(t.config.SMTPHost, strconv.Itoa(t.config.SMTPPort))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, t.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: t.config.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	if t.config.SMTPUsername != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.config.SMTPUsername, t.config.SMTPPassword, t.config.SMTPHost)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(t.config.From); err != nil {
+		return err
+	}
+	for _, to := range message.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
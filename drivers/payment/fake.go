@@ -0,0 +1,184 @@
+package payment
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const fakeServerKey = "fake-server-key"
+
+// FakeGateway adalah gateway in-memory yang deterministik untuk pengujian.
+// Status transaksi diatur lewat SetStatus, dan semua panggilan tercatat.
+type FakeGateway struct {
+	mu           sync.Mutex
+	transactions map[string]*TransactionStatus
+	refunds      []Refund
+	refundErr    error
+	cancelled    []string
+}
+
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		transactions: map[string]*TransactionStatus{},
+	}
+}
+
+func (f *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (f *FakeGateway) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.transactions[req.OrderID]; exists {
+		return nil, fmt.Errorf("order %s already exists", req.OrderID)
+	}
+
+	f.transactions[req.OrderID] = &TransactionStatus{
+		OrderID:           req.OrderID,
+		TransactionID:     "fake-" + req.OrderID,
+		TransactionStatus: "pending",
+		StatusCode:        "201",
+		GrossAmount:       fmt.Sprintf("%d.00", req.Amount),
+		PaymentType:       "fake",
+	}
+
+	return &Charge{
+		OrderID:     req.OrderID,
+		Token:       "fake-token-" + req.OrderID,
+		RedirectURL: "https://payment.fake/snap/" + req.OrderID,
+	}, nil
+}
+
+func (f *FakeGateway) VerifyNotification(ctx context.Context, status TransactionStatus) error {
+	if status.SignatureKey != FakeSignature(status) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (f *FakeGateway) QueryStatus(ctx context.Context, orderID string) (*TransactionStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.transactions[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, orderID)
+	}
+	result := *status
+	return &result, nil
+}
+
+func (f *FakeGateway) Cancel(ctx context.Context, orderID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.transactions[orderID]
+	if !ok {
+		return errors.New("transaction not found")
+	}
+	status.TransactionStatus = "cancel"
+	f.cancelled = append(f.cancelled, orderID)
+	return nil
+}
+
+// ChargeSavedToken langsung melunasi transaksi dan mengembalikan token yang sama
+func (f *FakeGateway) ChargeSavedToken(ctx context.Context, req ChargeRequest, savedTokenID string) (*TransactionStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.transactions[req.OrderID]; exists {
+		return nil, fmt.Errorf("order %s already exists", req.OrderID)
+	}
+
+	status := &TransactionStatus{
+		OrderID:           req.OrderID,
+		TransactionID:     "fake-" + req.OrderID,
+		TransactionStatus: "capture",
+		FraudStatus:       "accept",
+		StatusCode:        "200",
+		GrossAmount:       fmt.Sprintf("%d.00", req.Amount),
+		PaymentType:       "credit_card",
+		SavedTokenID:      savedTokenID,
+	}
+	f.transactions[req.OrderID] = status
+
+	result := *status
+	return &result, nil
+}
+
+// Refund mengembalikan refund yang sama untuk refund key yang sudah pernah dipakai,
+// seperti Midtrans, sehingga refund yang diulang tidak tercatat dua kali
+func (f *FakeGateway) Refund(ctx context.Context, orderID string, req RefundRequest) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.refundErr != nil {
+		return nil, f.refundErr
+	}
+	if _, ok := f.transactions[orderID]; !ok {
+		return nil, fmt.Errorf("%w: transaction %s not found", ErrRefundRejected, orderID)
+	}
+	for _, refund := range f.refunds {
+		if refund.OrderID == orderID && refund.RefundKey == req.RefundKey {
+			return &refund, nil
+		}
+	}
+
+	refund := Refund{
+		OrderID:   orderID,
+		RefundKey: req.RefundKey,
+		Amount:    req.Amount,
+		Status:    "refund",
+	}
+	f.refunds = append(f.refunds, refund)
+	return &refund, nil
+}
+
+// FailRefunds membuat setiap panggilan Refund mengembalikan err tanpa mencatat refund,
+// misalnya ErrRefundRejected atau error jaringan. Nilai nil mengembalikan perilaku normal.
+func (f *FakeGateway) FailRefunds(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refundErr = err
+}
+
+// SetStatus mengubah status transaksi dan mengembalikan notifikasi bertanda tangan
+// yang siap dikirim ke UpdateDonationStatus
+func (f *FakeGateway) SetStatus(orderID string, transactionStatus string) (TransactionStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, ok := f.transactions[orderID]
+	if !ok {
+		return TransactionStatus{}, errors.New("transaction not found")
+	}
+	status.TransactionStatus = transactionStatus
+	status.StatusCode = "200"
+	status.SignatureKey = FakeSignature(*status)
+	return *status, nil
+}
+
+func (f *FakeGateway) Refunds() []Refund {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Refund(nil), f.refunds...)
+}
+
+func (f *FakeGateway) Cancelled() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.cancelled...)
+}
+
+// FakeSignature menghasilkan signature dengan format yang sama seperti Midtrans
+func FakeSignature(status TransactionStatus) string {
+	h := sha512.New()
+	h.Write([]byte(status.OrderID + status.StatusCode + status.GrossAmount + fakeServerKey))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,151 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/entities"
+	midtran "tugas-akhir/utils/midtrans"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid notification signature")
+	ErrNotSupported     = errors.New("operation not supported by payment gateway")
+
+	// ErrRefundRejected menandai refund yang pasti ditolak gateway. Error lain, misalnya
+	// timeout atau 5xx, tidak pasti sehingga dana mungkin sudah dikembalikan.
+	ErrRefundRejected = errors.New("refund rejected by payment gateway")
+
+	// ErrTransactionNotFound dikembalikan QueryStatus jika gateway tidak mengenal order id,
+	// sehingga transaksi dengan order id tersebut pasti belum pernah dibuat
+	ErrTransactionNotFound = errors.New("transaction not found at payment gateway")
+)
+
+// PaymentGateway adalah abstraksi penyedia pembayaran yang dipakai alur donasi.
+// Status transaksi memakai istilah yang sama dengan Midtrans (pending, settlement,
+// capture, expire, cancel, deny, failure, refund) untuk semua implementasi.
+type PaymentGateway interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	VerifyNotification(ctx context.Context, status TransactionStatus) error
+	QueryStatus(ctx context.Context, orderID string) (*TransactionStatus, error)
+	Cancel(ctx context.Context, orderID string) error
+	Refund(ctx context.Context, orderID string, req RefundRequest) (*Refund, error)
+}
+
+type ChargeRequest struct {
+	OrderID  string
+	Amount   int64
+	Customer Customer
+	Items    []Item
+
+	// Override per transaksi, nilai kosong berarti memakai konfigurasi gateway
+	ExpiryMinutes   int
+	EnabledPayments []string
+
+	// SaveCard meminta gateway menyimpan kartu donatur untuk penagihan berikutnya
+	SaveCard bool
+}
+
+type Customer struct {
+	Name  string
+	Email string
+	Phone string
+}
+
+type Item struct {
+	ID       string
+	Name     string
+	Price    int64
+	Quantity int32
+}
+
+type Charge struct {
+	OrderID     string
+	Token       string
+	RedirectURL string
+	ExpiresAt   time.Time // zero berarti link pembayaran tidak kadaluarsa
+}
+
+// TransactionStatus adalah status transaksi dari gateway, baik yang dikirim
+// lewat webhook maupun hasil QueryStatus.
+type TransactionStatus struct {
+	TransactionTime   string `json:"transaction_time"`
+	TransactionStatus string `json:"transaction_status"`
+	TransactionID     string `json:"transaction_id"`
+	StatusMessage     string `json:"status_message"`
+	StatusCode        string `json:"status_code"`
+	SignatureKey      string `json:"signature_key"`
+	PaymentType       string `json:"payment_type"`
+	OrderID           string `json:"order_id"`
+	MerchantID        string `json:"merchant_id"`
+	GrossAmount       string `json:"gross_amount"`
+	FraudStatus       string `json:"fraud_status"`
+	Currency          string `json:"currency"`
+	SettlementTime    string `json:"settlement_time"`
+	SavedTokenID      string `json:"saved_token_id"`
+}
+
+// TimeLayout adalah format waktu transaksi yang dipakai Midtrans
+const TimeLayout = "2006-01-02 15:04:05"
+
+// DonationStatus memetakan status transaksi ke status donasi
+func (s TransactionStatus) DonationStatus() (entities.DonationStatus, bool) {
+	return midtran.MapTransactionStatus(s.TransactionStatus, s.FraudStatus)
+}
+
+// Amount mengembalikan gross amount transaksi dalam rupiah. Midtrans mengirim nominal
+// dengan dua angka desimal, misalnya "10000.00".
+func (s TransactionStatus) Amount() (int, error) {
+	amount, err := strconv.ParseFloat(s.GrossAmount, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(amount)), nil
+}
+
+// TokenCharger diimplementasikan gateway yang dapat menagih kartu tersimpan tanpa
+// interaksi donatur, dipakai untuk donasi rutin
+type TokenCharger interface {
+	ChargeSavedToken(ctx context.Context, req ChargeRequest, savedTokenID string) (*TransactionStatus, error)
+}
+
+type RefundRequest struct {
+	RefundKey string
+	Amount    int64
+	Reason    string
+}
+
+type Refund struct {
+	OrderID   string
+	RefundKey string
+	Amount    int64
+	Status    string
+}
+
+// NewPaymentGateway memilih implementasi gateway berdasarkan PAYMENT_GATEWAY. Nilai yang
+// tidak dikenal mengembalikan error agar salah konfigurasi langsung terlihat saat start.
+func NewPaymentGateway(paymentConfig config.PaymentConfig, midtransConfig config.MidtransConfig) (PaymentGateway, error) {
+	switch paymentConfig.Gateway {
+	case "manual":
+		return NewManualTransferGateway(paymentConfig), nil
+	case "fake":
+		return NewFakeGateway(), nil
+	case "midtrans":
+		if midtransConfig.ServerKey == "" || midtransConfig.ClientKey == "" {
+			return nil, errors.New("missing Midtrans server or client key, set MIDTRANS_SERVER_KEY and MIDTRANS_CLIENT_KEY or choose another PAYMENT_GATEWAY")
+		}
+		// Snap hanya menerima satuan expiry minute, hour, dan day
+		switch midtransConfig.ExpiryUnit {
+		case "minute", "hour", "day":
+		default:
+			return nil, fmt.Errorf("unsupported MIDTRANS_EXPIRY_UNIT %q, expected minute, hour or day", midtransConfig.ExpiryUnit)
+		}
+		return NewMidtransGateway(midtransConfig), nil
+	}
+	return nil, fmt.Errorf("unknown payment gateway %q, expected midtrans, manual or fake", paymentConfig.Gateway)
+}
@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"testing"
+	"tugas-akhir/config"
+)
+
+func TestNewPaymentGateway(t *testing.T) {
+	keys := config.MidtransConfig{ServerKey: "server-key", ClientKey: "client-key", ExpiryUnit: "minute"}
+	withExpiryUnit := func(unit string) config.MidtransConfig {
+		midtransConfig := keys
+		midtransConfig.ExpiryUnit = unit
+		return midtransConfig
+	}
+
+	tests := []struct {
+		name     string
+		gateway  string
+		midtrans config.MidtransConfig
+		want     string
+		wantErr  bool
+	}{
+		{"fake without midtrans keys", "fake", config.MidtransConfig{}, "fake", false},
+		{"manual without midtrans keys", "manual", config.MidtransConfig{}, "manual", false},
+		{"midtrans with keys", "midtrans", keys, "midtrans", false},
+		{"midtrans with expiry in days", "midtrans", withExpiryUnit("day"), "midtrans", false},
+		{"midtrans without keys", "midtrans", config.MidtransConfig{ExpiryUnit: "minute"}, "", true},
+		{"midtrans without client key", "midtrans", config.MidtransConfig{ServerKey: "server-key", ExpiryUnit: "minute"}, "", true},
+		{"midtrans with expiry in seconds", "midtrans", withExpiryUnit("second"), "", true},
+		{"midtrans with unknown expiry unit", "midtrans", withExpiryUnit("week"), "", true},
+		{"unknown gateway", "xendit", keys, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, err := NewPaymentGateway(config.PaymentConfig{Gateway: tt.gateway}, tt.midtrans)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPaymentGateway(%q) returned error %v, want error %t", tt.gateway, err, tt.wantErr)
+			}
+			if err == nil && gateway.Name() != tt.want {
+				t.Errorf("NewPaymentGateway(%q) returned %s gateway, want %s", tt.gateway, gateway.Name(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"tugas-akhir/config"
+)
+
+// manualTransferGateway dipakai untuk donasi lewat transfer bank langsung ke rekening yayasan.
+// Tidak ada webhook maupun API status; pembayaran dikonfirmasi oleh admin.
+type manualTransferGateway struct {
+	config config.PaymentConfig
+}
+
+func NewManualTransferGateway(paymentConfig config.PaymentConfig) PaymentGateway {
+	return &manualTransferGateway{
+		config: paymentConfig,
+	}
+}
+
+func (m *manualTransferGateway) Name() string {
+	return "manual"
+}
+
+// CreateCharge mengembalikan halaman instruksi transfer untuk order tersebut
+func (m *manualTransferGateway) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	query := url.Values{}
+	query.Set("order_id", req.OrderID)
+	query.Set("amount", fmt.Sprint(req.Amount))
+	query.Set("bank", m.config.ManualBankName)
+	query.Set("account_number", m.config.ManualAccountNumber)
+	query.Set("account_holder", m.config.ManualAccountHolder)
+
+	return &Charge{
+		OrderID:     req.OrderID,
+		RedirectURL: m.config.ManualInstructionURL + "?" + query.Encode(),
+	}, nil
+}
+
+func (m *manualTransferGateway) VerifyNotification(ctx context.Context, status TransactionStatus) error {
+	return ErrNotSupported
+}
+
+// QueryStatus selalu pending karena status transfer manual hanya berubah lewat konfirmasi admin
+func (m *manualTransferGateway) QueryStatus(ctx context.Context, orderID string) (*TransactionStatus, error) {
+	return &TransactionStatus{
+		OrderID:           orderID,
+		TransactionStatus: "pending",
+		PaymentType:       "bank_transfer",
+	}, nil
+}
+
+func (m *manualTransferGateway) Cancel(ctx context.Context, orderID string) error {
+	return nil
+}
+
+// Refund untuk transfer manual dikembalikan langsung oleh bagian keuangan,
+// gateway hanya mencatat nominalnya
+func (m *manualTransferGateway) Refund(ctx context.Context, orderID string, req RefundRequest) (*Refund, error) {
+	return &Refund{
+		OrderID:   orderID,
+		RefundKey: req.RefundKey,
+		Amount:    req.Amount,
+		Status:    "refund",
+	}, nil
+}
@@ -0,0 +1,292 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"tugas-akhir/config"
+	midtran "tugas-akhir/utils/midtrans"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/midtrans/midtrans-go/snap"
+)
+
+// Batas panjang field dari Snap API
+const (
+	midtransMaxItemName     = 50
+	midtransMaxCustomerName = 255
+)
+
+// Batas waktu default Snap jika expiry tidak diatur
+const midtransDefaultExpiry = 24 * time.Hour
+
+type midtransGateway struct {
+	config         config.MidtransConfig
+	snapClient     snap.Client
+	coreClient     coreapi.Client
+	signatureCheck *midtran.Client
+}
+
+// NewMidtransGateway membuat gateway Midtrans Snap. Transaksi dibuat lewat Snap,
+// sedangkan status, pembatalan, dan refund memakai Core API.
+func NewMidtransGateway(midtransConfig config.MidtransConfig) PaymentGateway {
+	environment := midtrans.Sandbox
+	if midtransConfig.Environment == "production" {
+		environment = midtrans.Production
+	}
+
+	gateway := &midtransGateway{
+		config:         midtransConfig,
+		signatureCheck: midtran.NewClient(midtransConfig),
+	}
+	gateway.snapClient.New(midtransConfig.ServerKey, environment)
+	gateway.coreClient.New(midtransConfig.ServerKey, environment)
+	return gateway
+}
+
+func (m *midtransGateway) Name() string {
+	return "midtrans"
+}
+
+func (m *midtransGateway) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	snapReq, err := m.buildSnapRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, merr := m.snapClient.CreateTransactionWithMap(snapReq)
+	if merr != nil {
+		return nil, merr
+	}
+
+	token, _ := resp["token"].(string)
+	redirectURL, _ := resp["redirect_url"].(string)
+	if redirectURL == "" {
+		return nil, errors.New("midtrans snap returned empty redirect url")
+	}
+
+	return &Charge{
+		OrderID:     req.OrderID,
+		Token:       token,
+		RedirectURL: redirectURL,
+		ExpiresAt:   time.Now().Add(m.expiryFor(req)),
+	}, nil
+}
+
+// expiryFor menghitung lama link Snap berlaku dengan aturan yang sama seperti buildSnapRequest
+func (m *midtransGateway) expiryFor(req ChargeRequest) time.Duration {
+	if req.ExpiryMinutes > 0 {
+		return time.Duration(req.ExpiryMinutes) * time.Minute
+	}
+	if m.config.ExpiryDuration <= 0 {
+		return midtransDefaultExpiry
+	}
+
+	duration := time.Duration(m.config.ExpiryDuration)
+	switch m.config.ExpiryUnit {
+	case "hour":
+		return duration * time.Hour
+	case "day":
+		return duration * 24 * time.Hour
+	}
+	return duration * time.Minute
+}
+
+// buildSnapRequest menyusun request Snap. Request dikirim sebagai map karena
+// struct snap.Callbacks dari SDK hanya mendukung finish URL.
+func (m *midtransGateway) buildSnapRequest(req ChargeRequest) (*snap.RequestParamWithMap, error) {
+	snapReq := snap.Request{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.OrderID,
+			GrossAmt: req.Amount,
+		},
+	}
+
+	if req.Customer != (Customer{}) {
+		snapReq.CustomerDetail = &midtrans.CustomerDetails{
+			FName: truncate(req.Customer.Name, midtransMaxCustomerName),
+			Email: req.Customer.Email,
+			Phone: req.Customer.Phone,
+		}
+	}
+
+	if len(req.Items) > 0 {
+		items := make([]midtrans.ItemDetails, 0, len(req.Items))
+		for _, item := range req.Items {
+			items = append(items, midtrans.ItemDetails{
+				ID:    item.ID,
+				Name:  truncate(item.Name, midtransMaxItemName),
+				Price: item.Price,
+				Qty:   item.Quantity,
+			})
+		}
+		snapReq.Items = &items
+	}
+
+	enabledPayments := req.EnabledPayments
+	if len(enabledPayments) == 0 {
+		enabledPayments = m.config.EnabledPayments
+	}
+	for _, paymentType := range enabledPayments {
+		snapReq.EnabledPayments = append(snapReq.EnabledPayments, snap.SnapPaymentType(paymentType))
+	}
+
+	if req.SaveCard {
+		snapReq.CreditCard = &snap.CreditCardDetails{SaveCard: true, Secure: true}
+	}
+
+	if req.ExpiryMinutes > 0 {
+		snapReq.Expiry = &snap.ExpiryDetails{Unit: "minute", Duration: int64(req.ExpiryMinutes)}
+	} else if m.config.ExpiryDuration > 0 {
+		snapReq.Expiry = &snap.ExpiryDetails{Unit: m.config.ExpiryUnit, Duration: int64(m.config.ExpiryDuration)}
+	}
+
+	payload, err := json.Marshal(snapReq)
+	if err != nil {
+		return nil, err
+	}
+	params := snap.RequestParamWithMap{}
+	if err := json.Unmarshal(payload, &params); err != nil {
+		return nil, err
+	}
+
+	callbacks := map[string]string{}
+	if m.config.FinishURL != "" {
+		callbacks["finish"] = m.config.FinishURL
+	}
+	if m.config.UnfinishURL != "" {
+		callbacks["unfinish"] = m.config.UnfinishURL
+	}
+	if m.config.ErrorURL != "" {
+		callbacks["error"] = m.config.ErrorURL
+	}
+	if len(callbacks) > 0 {
+		params["callbacks"] = callbacks
+	}
+
+	return &params, nil
+}
+
+func (m *midtransGateway) VerifyNotification(ctx context.Context, status TransactionStatus) error {
+	notification := midtran.Notification{
+		OrderID:      status.OrderID,
+		StatusCode:   status.StatusCode,
+		GrossAmount:  status.GrossAmount,
+		SignatureKey: status.SignatureKey,
+	}
+	if status.SignatureKey == "" || !m.signatureCheck.VerifyNotificationSignature(notification) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (m *midtransGateway) QueryStatus(ctx context.Context, orderID string) (*TransactionStatus, error) {
+	resp, merr := m.coreClient.CheckTransaction(orderID)
+	if merr != nil {
+		if merr.GetStatusCode() == 404 {
+			return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, orderID)
+		}
+		return nil, merr
+	}
+
+	return &TransactionStatus{
+		TransactionTime:   resp.TransactionTime,
+		TransactionStatus: resp.TransactionStatus,
+		TransactionID:     resp.TransactionID,
+		StatusMessage:     resp.StatusMessage,
+		StatusCode:        resp.StatusCode,
+		SignatureKey:      resp.SignatureKey,
+		PaymentType:       resp.PaymentType,
+		OrderID:           resp.OrderID,
+		MerchantID:        resp.MerchantID,
+		GrossAmount:       resp.GrossAmount,
+		FraudStatus:       resp.FraudStatus,
+		Currency:          resp.Currency,
+		SettlementTime:    resp.SettlementTime,
+	}, nil
+}
+
+// ChargeSavedToken menagih kartu tersimpan lewat Core API dengan saved_token_id
+// dari transaksi sebelumnya
+func (m *midtransGateway) ChargeSavedToken(ctx context.Context, req ChargeRequest, savedTokenID string) (*TransactionStatus, error) {
+	chargeReq := &coreapi.ChargeReq{
+		PaymentType: coreapi.PaymentTypeCreditCard,
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.OrderID,
+			GrossAmt: req.Amount,
+		},
+		CreditCard: &coreapi.CreditCardDetails{TokenID: savedTokenID},
+	}
+	if req.Customer != (Customer{}) {
+		chargeReq.CustomerDetails = &midtrans.CustomerDetails{
+			FName: truncate(req.Customer.Name, midtransMaxCustomerName),
+			Email: req.Customer.Email,
+			Phone: req.Customer.Phone,
+		}
+	}
+
+	resp, merr := m.coreClient.ChargeTransaction(chargeReq)
+	if merr != nil {
+		return nil, merr
+	}
+
+	return &TransactionStatus{
+		TransactionTime:   resp.TransactionTime,
+		TransactionStatus: resp.TransactionStatus,
+		TransactionID:     resp.TransactionID,
+		StatusMessage:     resp.StatusMessage,
+		StatusCode:        resp.StatusCode,
+		PaymentType:       resp.PaymentType,
+		OrderID:           resp.OrderID,
+		GrossAmount:       resp.GrossAmount,
+		FraudStatus:       resp.FraudStatus,
+		Currency:          resp.Currency,
+		SavedTokenID:      resp.SavedTokenID,
+	}, nil
+}
+
+func (m *midtransGateway) Cancel(ctx context.Context, orderID string) error {
+	if _, merr := m.coreClient.CancelTransaction(orderID); merr != nil {
+		return merr
+	}
+	return nil
+}
+
+func (m *midtransGateway) Refund(ctx context.Context, orderID string, req RefundRequest) (*Refund, error) {
+	resp, merr := m.coreClient.RefundTransaction(orderID, &coreapi.RefundReq{
+		RefundKey: req.RefundKey,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	})
+	if merr != nil {
+		if isMidtransRejection(merr) {
+			return nil, fmt.Errorf("%w: %s", ErrRefundRejected, merr.GetMessage())
+		}
+		return nil, merr
+	}
+
+	return &Refund{
+		OrderID:   orderID,
+		RefundKey: resp.RefundKey,
+		Amount:    req.Amount,
+		Status:    resp.TransactionStatus,
+	}, nil
+}
+
+// isMidtransRejection bernilai true jika Midtrans menjawab dengan error 4xx yang pasti
+// menolak permintaan. Timeout (408), rate limit (429), dan 5xx dianggap tidak pasti.
+func isMidtransRejection(merr *midtrans.Error) bool {
+	code := merr.GetStatusCode()
+	return code >= 400 && code < 500 && code != 408 && code != 429
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
@@ -35,6 +35,11 @@ func (r *RedisClient) Set(key string, value string, expiration time.Duration) er
 	return err
 }
 
+// SetNX menyimpan value hanya jika key belum ada, true berarti key berhasil dibuat
+func (r *RedisClient) SetNX(key string, value string, expiration time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (r *RedisClient) Get(key string) (string, error) {
 	result, err := r.Client.Get(ctx, key).Result()
 	if err != nil {
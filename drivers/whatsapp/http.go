@@ -0,0 +1,85 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"tugas-akhir/utils/phone"
+)
+
+// Batas waktu satu permintaan ke gateway
+const httpTimeout = 15 * time.Second
+
+type sendRequest struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+type sendResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type httpProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewHTTPProvider mengirim pesan ke gateway WhatsApp lewat POST {baseURL}/messages dengan
+// body {"to": "+62...", "message": "..."}. Gateway membalas {"id": "...", "status": "sent"}
+// dan melaporkan status berikutnya ke webhook.
+func NewHTTPProvider(baseURL string, apiKey string) Provider {
+	return &httpProvider{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (p *httpProvider) Name() string {
+	return "http"
+}
+
+func (p *httpProvider) Send(ctx context.Context, to phone.Number, body string) (*SendResult, error) {
+	payload, err := json.Marshal(sendRequest{To: to.String(), Message: body})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("whatsapp gateway returned %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	var result sendResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid whatsapp gateway response: %w", err)
+	}
+	if result.Status == "" {
+		result.Status = StatusSent
+	}
+	return &SendResult{MessageID: result.ID, Status: result.Status}, nil
+}
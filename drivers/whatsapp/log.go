@@ -0,0 +1,29 @@
+package whatsapp
+
+import (
+	"context"
+	"tugas-akhir/utils/phone"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type logProvider struct{}
+
+// NewLogProvider hanya mencatat pesan ke log, dipakai saat gateway belum dikonfigurasi
+func NewLogProvider() Provider {
+	return &logProvider{}
+}
+
+func (p *logProvider) Name() string {
+	return "log"
+}
+
+func (p *logProvider) Send(ctx context.Context, to phone.Number, body string) (*SendResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	logrus.New().Infof("WhatsApp to %s: %s", to.Masked(), body)
+	return &SendResult{MessageID: uuid.NewString(), Status: StatusSent}, nil
+}
@@ -0,0 +1,181 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+	"tugas-akhir/config"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Jeda sebelum server stub melaporkan pesan sebagai terkirim ke perangkat penerima
+const stubDeliveryDelay = 2 * time.Second
+
+// StubMessage adalah pesan yang diterima server stub
+type StubMessage struct {
+	ID        string    `json:"id"`
+	To        string    `json:"to"`
+	Message   string    `json:"message"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StubServer meniru gateway WhatsApp untuk pengembangan dan pengujian lokal. Pesan
+// disimpan di memori dan dapat dilihat lewat GET /messages. Status delivered dilaporkan
+// ke webhook setelah jeda singkat, dan balasan donatur dapat disimulasikan lewat
+// POST /inbound dengan body {"from": "+62...", "message": "STOP"}.
+type StubServer struct {
+	callbackURL   string
+	webhookToken  string
+	deliveryDelay time.Duration
+	client        *http.Client
+
+	mu       sync.Mutex
+	messages []StubMessage
+}
+
+func NewStubServer(callbackURL string, webhookToken string) *StubServer {
+	return &StubServer{
+		callbackURL:   callbackURL,
+		webhookToken:  webhookToken,
+		deliveryDelay: stubDeliveryDelay,
+		client:        &http.Client{Timeout: httpTimeout},
+	}
+}
+
+// Setiap route membuat provider sendiri, jadi server stub hanya dijalankan sekali
+var startStub sync.Once
+
+// NewStubProvider menjalankan server stub di WHATSAPP_STUB_ADDR lalu mengembalikan
+// provider HTTP yang mengarah ke server tersebut, sehingga jalur pengiriman yang
+// diuji sama dengan gateway sungguhan
+func NewStubProvider(whatsAppConfig config.WhatsAppConfig) Provider {
+	startStub.Do(func() {
+		server := NewStubServer(whatsAppConfig.StatusCallbackURL, whatsAppConfig.WebhookToken)
+		go func() {
+			logrus.New().Infof("WhatsApp stub server listening on %s", whatsAppConfig.StubAddr)
+			if err := http.ListenAndServe(whatsAppConfig.StubAddr, server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.New().WithError(err).Error("WhatsApp stub server stopped")
+			}
+		}()
+	})
+	return NewHTTPProvider("http://"+whatsAppConfig.StubAddr, "")
+}
+
+func (s *StubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/messages":
+		s.handleSend(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/messages":
+		s.writeJSON(w, http.StatusOK, s.Messages())
+	case r.Method == http.MethodPost && r.URL.Path == "/inbound":
+		s.handleInbound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Messages mengembalikan salinan semua pesan yang diterima server stub
+func (s *StubServer) Messages() []StubMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]StubMessage, len(s.messages))
+	copy(messages, s.messages)
+	return messages
+}
+
+func (s *StubServer) handleSend(w http.ResponseWriter, r *http.Request) {
+	var req sendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.To == "" || req.Message == "" {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+
+	message := StubMessage{
+		ID:        uuid.NewString(),
+		To:        req.To,
+		Message:   req.Message,
+		Status:    StatusSent,
+		CreatedAt: time.Now(),
+	}
+	s.mu.Lock()
+	s.messages = append(s.messages, message)
+	s.mu.Unlock()
+
+	go s.deliver(message.ID)
+	s.writeJSON(w, http.StatusOK, sendResponse{ID: message.ID, Status: message.Status})
+}
+
+func (s *StubServer) handleInbound(w http.ResponseWriter, r *http.Request) {
+	var callback Callback
+	if err := json.NewDecoder(r.Body).Decode(&callback); err != nil || callback.From == "" {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+	callback.Type = CallbackMessage
+
+	if err := s.callback(r.Context(), callback); err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *StubServer) deliver(messageID string) {
+	time.Sleep(s.deliveryDelay)
+
+	s.mu.Lock()
+	for i := range s.messages {
+		if s.messages[i].ID == messageID {
+			s.messages[i].Status = StatusDelivered
+		}
+	}
+	s.mu.Unlock()
+
+	err := s.callback(context.Background(), Callback{Type: CallbackStatus, MessageID: messageID, Status: StatusDelivered})
+	if err != nil {
+		logrus.New().WithError(err).Warnf("WhatsApp stub failed to report status of %s", messageID)
+	}
+}
+
+func (s *StubServer) callback(ctx context.Context, callback Callback) error {
+	if s.callbackURL == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(callback)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.callbackURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.webhookToken != "" {
+		req.Header.Set(WebhookTokenHeader, s.webhookToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return errors.New("webhook returned " + resp.Status)
+	}
+	return nil
+}
+
+func (s *StubServer) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,64 @@
+package whatsapp
+
+import (
+	"context"
+	"log"
+	"tugas-akhir/config"
+	"tugas-akhir/utils/phone"
+)
+
+// Status pengiriman yang dilaporkan gateway
+const (
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusRead      = "read"
+	StatusFailed    = "failed"
+)
+
+// Header yang membawa WHATSAPP_WEBHOOK_TOKEN pada panggilan webhook dari gateway
+const WebhookTokenHeader = "X-Webhook-Token"
+
+// Callback adalah body webhook yang dikirim gateway. Type "status" melaporkan status
+// pengiriman pesan, type "message" meneruskan balasan dari donatur.
+type Callback struct {
+	Type      string `json:"type"`
+	MessageID string `json:"message_id,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Error     string `json:"error,omitempty"`
+	From      string `json:"from,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Jenis callback webhook
+const (
+	CallbackStatus  = "status"
+	CallbackMessage = "message"
+)
+
+// SendResult adalah jawaban gateway setelah pesan diterima untuk dikirim. MessageID
+// dipakai untuk mencocokkan laporan status yang datang lewat webhook.
+type SendResult struct {
+	MessageID string
+	Status    string
+}
+
+// Provider mengirim pesan teks WhatsApp lewat satu gateway
+type Provider interface {
+	Send(ctx context.Context, to phone.Number, body string) (*SendResult, error)
+	Name() string
+}
+
+// NewProvider memilih provider sesuai WHATSAPP_PROVIDER
+func NewProvider(whatsAppConfig config.WhatsAppConfig) Provider {
+	switch whatsAppConfig.Provider {
+	case "http":
+		return NewHTTPProvider(whatsAppConfig.BaseURL, whatsAppConfig.APIKey)
+	case "stub":
+		return NewStubProvider(whatsAppConfig)
+	case "log":
+		return NewLogProvider()
+	}
+
+	log.Printf("Unknown WhatsApp provider %q, falling back to log", whatsAppConfig.Provider)
+	return NewLogProvider()
+}
@@ -1,9 +1,13 @@
 package admin
 
+import "time"
+
+// RegisterRequest menerima undangan admin. Email dan role diambil dari undangan, nama
+// boleh dikosongkan untuk memakai nama pada undangan.
 type RegisterRequest struct {
-	Name     string `json:"name" form:"name" validate:"required"`
-	Email    string `json:"email" form:"email" validate:"required"`
-	Password string `json:"password" form:"password" validate:"required"`
+	Token    string `json:"token" form:"token" validate:"required"`
+	Name     string `json:"name" form:"name" validate:"omitempty,max=50"`
+	Password string `json:"password" form:"password" validate:"required,min=8"`
 }
 
 type RegisterResponse struct {
@@ -18,9 +22,65 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Token string `json:"token"`
-	Role  string `json:"role"`
-}
\ No newline at end of file
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+	Role         string    `json:"role"`
+	Permissions  []string  `json:"permissions"`
+}
+
+type AdminResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InviteAdminRequest struct {
+	Name  string `json:"name" validate:"required,max=50"`
+	Email string `json:"email" validate:"required,email,max=50"`
+	Role  string `json:"role" validate:"required"`
+}
+
+type InvitationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type UpdateAdminRequest struct {
+	Name  string `json:"name" validate:"required,max=50"`
+	Email string `json:"email" validate:"required,email,max=50"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateRolePermissionsRequest mengganti seluruh hak akses role dengan daftar ini
+type UpdateRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
@@ -0,0 +1,20 @@
+package auth
+
+import "time"
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// TokenResponse adalah pasangan token sesi. Token adalah access token berumur pendek,
+// refresh token hanya bisa dipakai sekali untuk mendapatkan pasangan token baru.
+type TokenResponse struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+	Role         string    `json:"role"`
+}
+
+type LogoutAllResponse struct {
+	RevokedSessions int64 `json:"revoked_sessions"`
+}
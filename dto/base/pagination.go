@@ -22,3 +22,20 @@ type PaginationMetadata struct {
 	TotalPage   int   `json:"total_page"`
 	TotalData   int64 `json:"total_data"`
 }
+
+// CursorPaginationRequest dipakai pada daftar yang terus bertambah di bagian atas.
+// Cursor berisi next_cursor dari halaman sebelumnya, kosong untuk halaman pertama.
+type CursorPaginationRequest struct {
+	Cursor string `json:"cursor" query:"cursor"`
+	Limit  int    `json:"limit" query:"limit" validate:"required,gt=0"`
+}
+
+type CursorPaginationResponse struct {
+	BaseResponse
+	Pagination *CursorMetadata `json:"pagination"`
+}
+
+type CursorMetadata struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -1,17 +1,42 @@
 package donation
 
 import (
+	"tugas-akhir/utils/phone"
+
 	"github.com/google/uuid"
 )
 
 type DonationRequest struct {
     Name      string    `json:"name" form:"name" validate:"required"`
     Address   string    `json:"address" form:"address" validate:"required"`
-    NoWA      int       `json:"no_wa" form:"no_wa" validate:"required"`
+    NoWA      phone.Number `json:"no_wa" form:"no_wa" validate:"required"`
     Email     string    `json:"email" form:"email" validate:"required"`
-    Amount    int       `json:"amount" form:"amount" validate:"required"`
+    Amount    int       `json:"amount" form:"amount" validate:"required_without=Allocations,gte=0"`
     Message   string    `json:"message" form:"message" validate:"required"`
     ProgramID uuid.UUID `json:"program_id" form:"program_id" validate:"omitempty,uuid4"` 
+
+    // Anonymous menyembunyikan nama donatur di halaman publik. DisplayName adalah nama
+    // yang ditampilkan di halaman publik, kosong berarti nama anonim.
+    Anonymous   bool   `json:"anonymous" form:"anonymous"`
+    DisplayName string `json:"display_name" form:"display_name" validate:"omitempty,max=50"`
+
+    // Allocations membagi satu pembayaran ke beberapa program; jika diisi, Amount dan
+    // ProgramID diabaikan dan nominal donasi adalah jumlah seluruh alokasi
+    Allocations []AllocationRequest `json:"allocations" validate:"omitempty,dive"`
+
+    // UserID diisi controller jika donatur login, bukan dari body request
+    UserID *uuid.UUID `json:"-" form:"-"`
+}
+
+type AllocationRequest struct {
+	ProgramID uuid.UUID `json:"program_id" validate:"required"`
+	Amount    int       `json:"amount" validate:"required,gt=0"`
+}
+
+type AllocationResponse struct {
+	ProgramID    string `json:"program_id"`
+	ProgramTitle string `json:"program_title"`
+	Amount       int    `json:"amount"`
 }
 
 
@@ -20,14 +45,46 @@ type DonationResponse struct {
 	Number       int    `json:"number"`
 	Name         string `json:"name"`
 	Address      string `json:"address"`
-	NoWA         int    `json:"no_wa"`
+	NoWA         string `json:"no_wa"`
 	Email        string `json:"email"`
 	Amount       int    `json:"amount"`
 	Message      string `json:"message"`
 	Status       int    `json:"status"`
+	StatusLabel  string `json:"status_label"`
 	SnapURL      string `json:"snap_url"`
 	ProgramID    string `json:"program_id"`
 	ProgramTitle string `json:"program_title"`
+	Anonymous    bool   `json:"anonymous"`
+	DisplayName  string `json:"display_name"`
+
+	PaymentChannel string `json:"payment_channel,omitempty"`
+	PaidAt         string `json:"paid_at,omitempty"`
+	ProofImageURL  string `json:"proof_image_url,omitempty"`
+
+	Allocations []AllocationResponse `json:"allocations,omitempty"`
+}
+
+// DonationPublicResponse adalah detail donasi untuk endpoint publik. Nama mengikuti
+// pilihan anonim atau nama publik donatur dan tidak memuat data kontak.
+type DonationPublicResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Amount       int    `json:"amount"`
+	Message      string `json:"message"`
+	Status       int    `json:"status"`
+	StatusLabel  string `json:"status_label"`
+	ProgramID    string `json:"program_id"`
+	PaidAt       string `json:"paid_at,omitempty"`
+
+	Allocations []AllocationResponse `json:"allocations,omitempty"`
+}
+
+// DonationResumeResponse adalah respons endpoint publik resume donasi. Endpoint ini bisa
+// dipanggil siapa saja yang tahu ID donasi sehingga hanya memuat status dan link pembayaran.
+type DonationResumeResponse struct {
+	Status      int    `json:"status"`
+	StatusLabel string `json:"status_label"`
+	SnapURL     string `json:"snap_url"`
 }
 
 type TopUpReq struct {
@@ -42,6 +99,8 @@ type ProgramDonationRequest struct {
 	Title                 string                        `json:"title" form:"title"`
 	Deskripsi             string                        `json:"deskripsi" form:"deskripsi" `
 	GoalAmount            int                           `json:"goal_amount" form:"goal_amount" `
+	PaymentExpiryMinutes  int                           `json:"payment_expiry_minutes" form:"payment_expiry_minutes" validate:"gte=0"`
+	EnabledPayments       string                        `json:"enabled_payments" form:"enabled_payments"`
 	ProgramDonationImages []ProgramDonationImageRequest `json:"program_donation_images" form:"program_donation_images"`
 }
 
@@ -56,6 +115,8 @@ type ProgramDonationResponse struct {
 	Deskripsi             string                         `json:"deskripsi"`
 	GoalAmount            int                            `json:"goal_amount"`
 	CurrentAmount         int                            `json:"current_amount"`
+	PaymentExpiryMinutes  int                            `json:"payment_expiry_minutes"`
+	EnabledPayments       string                         `json:"enabled_payments"`
 	ProgramDonationImages []ProgramDonationImageResponse `json:"program_donation_images"`
 }
 
@@ -69,11 +130,12 @@ type DashboardDonationResponse struct {
 	UniqueDonatorsCount int64 `json:"unique_donators_count"`
 }
 
+// DonationLandingResponse tampil di halaman publik sehingga tidak memuat ID donasi
 type DonationLandingResponse struct {
-	ID           string `json:"id"`
 	Name         string `json:"name"`
 	Amount       int    `json:"amount"`
 	Message      string `json:"message"`
+	Allocations  []AllocationResponse `json:"allocations"`
 }
 
 type DonationChartResponse struct {
@@ -84,11 +146,225 @@ type DonationChartResponse struct {
 }
 
 type DonaturNotifikasiResponse struct {
-	ID string `json:"id"`
-	Name string `json:"name"`
-	Amount string `json:"amount"`
-	ProgramDonation string `json:"program_donation"`
-	Message string `json:"message"`
-	Date string `json:"date"`
-	Status string `json:"status"`
-}
\ No newline at end of file
+	ID              string               `json:"id"`
+	DonationID      string               `json:"donation_id"`
+	Name            string               `json:"name"`
+	Amount          string               `json:"amount"`
+	ProgramDonation string               `json:"program_donation"`
+	Message         string               `json:"message"`
+	Date            string               `json:"date"`
+	Status          string               `json:"status"`
+	Read            bool                 `json:"read"`
+	ReadAt          string               `json:"read_at,omitempty"`
+	CreatedAt       string               `json:"created_at"`
+	Allocations     []AllocationResponse `json:"allocations"`
+}
+
+type UnreadNotificationResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+type MarkAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
+type ReconciliationRunResponse struct {
+	ID         string                       `json:"id"`
+	Trigger    string                       `json:"trigger"`
+	Checked    int                          `json:"checked"`
+	Updated    int                          `json:"updated"`
+	Failed     int                          `json:"failed"`
+	StartedAt  string                       `json:"started_at"`
+	FinishedAt string                       `json:"finished_at"`
+	Items      []ReconciliationItemResponse `json:"items"`
+}
+
+type ReconciliationItemResponse struct {
+	DonationID     string `json:"donation_id"`
+	OrderID        string `json:"order_id"`
+	GatewayStatus  string `json:"gateway_status"`
+	PreviousStatus string `json:"previous_status"`
+	NewStatus      string `json:"new_status"`
+	Error          string `json:"error,omitempty"`
+}
+
+type RefundRequest struct {
+	Amount int    `json:"amount" form:"amount" validate:"gte=0"`
+	Reason string `json:"reason" form:"reason" validate:"required"`
+	Manual bool   `json:"manual" form:"manual"`
+}
+
+type RefundResponse struct {
+	ID             string `json:"id"`
+	DonationID     string `json:"donation_id"`
+	Amount         int    `json:"amount"`
+	Reason         string `json:"reason"`
+	Method         string `json:"method"`
+	RefundKey      string `json:"refund_key"`
+	GatewayStatus  string `json:"gateway_status"`
+	Status         string `json:"status"`
+	FailureReason  string `json:"failure_reason,omitempty"`
+	AdminID        string `json:"admin_id"`
+	DonationStatus string `json:"donation_status"`
+	RefundedAmount int    `json:"refunded_amount"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type OfflineDonationRequest struct {
+	Name           string       `json:"name" form:"name" validate:"required"`
+	Address        string       `json:"address" form:"address"`
+	NoWA           phone.Number `json:"no_wa" form:"no_wa"`
+	Email          string       `json:"email" form:"email"`
+	Amount         int          `json:"amount" form:"amount" validate:"required,gt=0"`
+	Message        string       `json:"message" form:"message"`
+	ProgramID      uuid.UUID    `json:"program_id" form:"program_id" validate:"required"`
+	PaymentChannel string       `json:"payment_channel" form:"payment_channel" validate:"required,oneof=bank_transfer cash"`
+	PaidAt         string       `json:"paid_at" form:"paid_at" validate:"required,datetime=2006-01-02"`
+	ProofImageURL  string       `json:"-" form:"-"`
+}
+
+type TransferProofRequest struct {
+	BankName      string `json:"bank_name" form:"bank_name" validate:"required"`
+	AccountName   string `json:"account_name" form:"account_name" validate:"required"`
+	TransferredAt string `json:"transferred_at" form:"transferred_at" validate:"required,datetime=2006-01-02"`
+	ImageURL      string `json:"-" form:"-"`
+}
+
+type RejectTransferProofRequest struct {
+	Reason string `json:"reason" form:"reason" validate:"required"`
+}
+
+type TransferProofResponse struct {
+	ID             string `json:"id"`
+	DonationID     string `json:"donation_id"`
+	DonorName      string `json:"donor_name"`
+	Amount         int    `json:"amount"`
+	ImageURL       string `json:"image_url"`
+	BankName       string `json:"bank_name"`
+	AccountName    string `json:"account_name"`
+	TransferredAt  string `json:"transferred_at"`
+	Status         string `json:"status"`
+	RejectReason   string `json:"reject_reason,omitempty"`
+	DonationStatus string `json:"donation_status"`
+	ReviewedAt     string `json:"reviewed_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type SubscriptionRequest struct {
+	Name      string       `json:"name" validate:"required"`
+	Address   string       `json:"address"`
+	NoWA      phone.Number `json:"no_wa" validate:"required"`
+	Email     string       `json:"email" validate:"required,email"`
+	Message   string       `json:"message"`
+	ProgramID uuid.UUID    `json:"program_id" validate:"required"`
+	Amount    int          `json:"amount" validate:"required,gt=0"`
+	Interval  string       `json:"interval" validate:"required,oneof=weekly monthly yearly"`
+	StartDate string       `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+
+	Anonymous   bool   `json:"anonymous"`
+	DisplayName string `json:"display_name" validate:"omitempty,max=50"`
+
+	// UserID diisi controller jika donatur login, bukan dari body request
+	UserID *uuid.UUID `json:"-"`
+}
+
+type SubscriptionResponse struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	ProgramID      string `json:"program_id"`
+	Amount         int    `json:"amount"`
+	Interval       string `json:"interval"`
+	Status         string `json:"status"`
+	NextChargeAt   string `json:"next_charge_at"`
+	RetryAt        string `json:"retry_at,omitempty"`
+	FailedAttempts int    `json:"failed_attempts"`
+	AutoCharge     bool   `json:"auto_charge"`
+	SnapURL        string `json:"snap_url,omitempty"`
+
+	// ManageToken hanya dikembalikan sekali saat donasi rutin dibuat
+	ManageToken string `json:"manage_token,omitempty"`
+}
+
+// ReceiptVerificationResponse adalah hasil pemeriksaan keaslian kuitansi donasi
+type ReceiptVerificationResponse struct {
+	Valid          bool   `json:"valid"`
+	Number         string `json:"number"`
+	DonorName      string `json:"donor_name"`
+	Amount         int    `json:"amount"`
+	ProgramTitles  string `json:"program_titles"`
+	PaidAt         string `json:"paid_at"`
+	IssuedAt       string `json:"issued_at"`
+	DonationStatus string `json:"donation_status"`
+}
+
+// DonationHistoryResponse adalah satu baris riwayat donasi di akun donatur
+type DonationHistoryResponse struct {
+	ID             string               `json:"id"`
+	Amount         int                  `json:"amount"`
+	Status         int                  `json:"status"`
+	StatusLabel    string               `json:"status_label"`
+	Message        string               `json:"message"`
+	PaymentChannel string               `json:"payment_channel,omitempty"`
+	SnapURL        string               `json:"snap_url,omitempty"`
+	PaidAt         string               `json:"paid_at,omitempty"`
+	CreatedAt      string               `json:"created_at"`
+	SubscriptionID string               `json:"subscription_id,omitempty"`
+	Allocations    []AllocationResponse `json:"allocations"`
+	Receipt        *ReceiptSummary      `json:"receipt,omitempty"`
+}
+
+type ReceiptSummary struct {
+	Number   string `json:"number"`
+	IssuedAt string `json:"issued_at"`
+	URL      string `json:"url"`
+}
+
+// DonationMessageResponse adalah pesan donatur pada antrean moderasi admin
+type DonationMessageResponse struct {
+	DonationID     string `json:"donation_id"`
+	Name           string `json:"name"`
+	PublicName     string `json:"public_name"`
+	Amount         int    `json:"amount"`
+	DonationStatus string `json:"donation_status"`
+	Message        string `json:"message"`
+	PublicMessage  string `json:"public_message"`
+	Status         string `json:"status"`
+	Flag           string `json:"flag,omitempty"`
+	ReviewedBy     string `json:"reviewed_by,omitempty"`
+	ReviewedAt     string `json:"reviewed_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type EditMessageRequest struct {
+	Message string `json:"message" form:"message" validate:"required,max=500"`
+}
+
+// PrayerResponse adalah pesan dan doa donatur yang tampil di halaman publik
+// PrayerResponse tampil di dinding doa publik sehingga tidak memuat ID donasi
+type PrayerResponse struct {
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at"`
+}
+
+// DonationEventData adalah isi event feed donasi untuk dashboard admin
+type DonationEventData struct {
+	DonationID     string `json:"donation_id"`
+	Name           string `json:"name"`
+	Email          string `json:"email"`
+	Amount         int    `json:"amount"`
+	RefundedAmount int    `json:"refunded_amount"`
+	Status         int    `json:"status"`
+	StatusLabel    string `json:"status_label"`
+	PaymentChannel string `json:"payment_channel,omitempty"`
+	ProgramID      string `json:"program_id"`
+	ProgramTitle   string `json:"program_title"`
+}
+
+// PublicDonationEventData adalah isi event feed publik, tanpa identitas donatur selain
+// nama publiknya
+type PublicDonationEventData struct {
+	Name         string `json:"name"`
+	Amount       int    `json:"amount"`
+	ProgramTitle string `json:"program_title"`
+}
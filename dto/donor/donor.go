@@ -0,0 +1,88 @@
+package donor
+
+import (
+	"time"
+	"tugas-akhir/utils/phone"
+)
+
+type RegisterRequest struct {
+	Name     string       `json:"name" form:"name" validate:"required"`
+	Email    string       `json:"email" form:"email" validate:"required,email"`
+	Password string       `json:"password" form:"password" validate:"required,min=8"`
+	Address  string       `json:"address" form:"address"`
+	NoWA     phone.Number `json:"no_wa" form:"no_wa"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" form:"email" validate:"required"`
+	Password string `json:"password" form:"password" validate:"required"`
+}
+
+type LoginResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+	Role         string    `json:"role"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" form:"email" validate:"required,email"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" form:"code" validate:"required,len=6,numeric"`
+}
+
+type ProfileResponse struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Address       string `json:"address"`
+	NoWA          string `json:"no_wa"`
+	EmailVerified bool   `json:"email_verified"`
+	PhoneVerified bool   `json:"phone_verified"`
+}
+
+type DonorProfileResponse struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Email           string   `json:"email"`
+	NoWA            string   `json:"no_wa"`
+	UserID          string   `json:"user_id,omitempty"`
+	Identifiers     []string `json:"identifiers,omitempty"`
+	DonationCount   int64    `json:"donation_count"`
+	TotalAmount     int64    `json:"total_amount"`
+	FirstDonationAt string   `json:"first_donation_at,omitempty"`
+	LastDonationAt  string   `json:"last_donation_at,omitempty"`
+}
+
+type DonorDuplicateResponse struct {
+	ID         string                `json:"id"`
+	Reason     string                `json:"reason"`
+	Status     string                `json:"status"`
+	Profile    *DonorProfileResponse `json:"profile"`
+	Candidate  *DonorProfileResponse `json:"candidate"`
+	ReviewedBy string                `json:"reviewed_by,omitempty"`
+	ReviewedAt string                `json:"reviewed_at,omitempty"`
+	CreatedAt  string                `json:"created_at"`
+}
+
+type MergeProfileRequest struct {
+	SourceID string `json:"source_id" form:"source_id" validate:"required,uuid"`
+}
+
+type WhatsAppPreferenceRequest struct {
+	OptIn *bool `json:"opt_in" form:"opt_in" validate:"required"`
+}
+
+type WhatsAppPreferenceResponse struct {
+	NoWA  string `json:"no_wa"`
+	OptIn bool   `json:"opt_in"`
+}
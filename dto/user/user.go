@@ -1,16 +1,18 @@
 package user
 
+import "tugas-akhir/utils/phone"
+
 type UserResponse struct {
 	ID      string `json:"id"`
 	Name    string `json:"name"`
 	Email   string `json:"email"`
 	Address string `json:"address"`
-	NoWA    int    `json:"no_wa"`
+	NoWA    string `json:"no_wa"`
 }
 
 type UserRequest struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Address string `json:"address"`
-	NoWA    int    `json:"no_wa"`
+	Name    string       `json:"name"`
+	Email   string       `json:"email"`
+	Address string       `json:"address"`
+	NoWA    phone.Number `json:"no_wa"`
 }
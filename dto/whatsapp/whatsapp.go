@@ -0,0 +1,28 @@
+package whatsapp
+
+// WebhookRequest adalah callback dari gateway WhatsApp. Type "status" melaporkan status
+// pengiriman pesan, type "message" meneruskan balasan dari donatur.
+type WebhookRequest struct {
+	Type      string `json:"type" validate:"required,oneof=status message"`
+	MessageID string `json:"message_id" validate:"required_if=Type status"`
+	Status    string `json:"status" validate:"required_if=Type status"`
+	Error     string `json:"error"`
+	From      string `json:"from" validate:"required_if=Type message"`
+	Message   string `json:"message"`
+}
+
+type MessageResponse struct {
+	ID          string `json:"id"`
+	Phone       string `json:"phone"`
+	Template    string `json:"template"`
+	Reference   string `json:"reference"`
+	Body        string `json:"body"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	Error       string `json:"error,omitempty"`
+	DonationID  string `json:"donation_id,omitempty"`
+	SentAt      string `json:"sent_at,omitempty"`
+	DeliveredAt string `json:"delivered_at,omitempty"`
+	ReadAt      string `json:"read_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
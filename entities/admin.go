@@ -1,11 +1,39 @@
 package entities
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Admin struct {
 	ID       uuid.UUID `gorm:"primaryKey;type:uuid"`
 	Name     string    `gorm:"type:varchar(50); not null"`
 	Role     string    `gorm:"type:varchar(50); not null"`
-	Email    string    `gorm:"type:varchar(50); not null"`
+	Email    string    `gorm:"type:varchar(50); not null;uniqueIndex:idx_admin_email"`
 	Password string    `gorm:"type:varchar(255); not null"`
+
+	// Admin yang dinonaktifkan tidak bisa login sampai diaktifkan kembali
+	DeactivatedAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// IsActive menandakan akun admin boleh login
+func (a Admin) IsActive() bool {
+	return a.DeactivatedAt == nil
+}
+
+// AdminInvitation adalah undangan menjadi admin. Hanya hash token yang disimpan dan
+// token hanya bisa dipakai sekali.
+type AdminInvitation struct {
+	ID         uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Name       string    `gorm:"type:varchar(50);not null"`
+	Email      string    `gorm:"type:varchar(50);not null;index"`
+	Role       string    `gorm:"type:varchar(30);not null"`
+	TokenHash  string    `gorm:"type:varchar(64);not null;index"`
+	InvitedBy  uuid.UUID `gorm:"type:uuid;not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	AcceptedAt *time.Time
+	CreatedAt  time.Time
 }
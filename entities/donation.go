@@ -1,26 +1,117 @@
 package entities
 
 import (
+	"strconv"
 	"time"
+	"tugas-akhir/utils/contact"
+	"tugas-akhir/utils/phone"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Donation struct {
-	ID                uuid.UUID `gorm:"primaryKey;type:uuid"`
-	Name              string    `gorm:"type:varchar(50); not null"`
-	Address           string    `gorm:"type:varchar(255); not null"`
-	NoWA              int       `gorm:"type:int"`
-	Email             string    `gorm:"type:varchar(50); not null"`
-	Amount            int       `gorm:"type:int"`
-	Message           string    `gorm:"type:text; not null"`
-	Status            int       `gorm:"type:int"`
-	SnapURL           string    `gorm:"type:varchar(255); not null"`
-	ProgramDonationID uuid.UUID `gorm:"type:uuid;not null"` // Foreign Key
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
-	DeletedAt         gorm.DeletedAt `gorm:"index"`
+	ID                uuid.UUID      `gorm:"primaryKey;type:uuid"`
+	Name              string         `gorm:"type:varchar(50); not null"`
+	Address           string         `gorm:"type:varchar(255); not null"`
+	NoWA              phone.Number   `gorm:"type:varchar(16)"`
+	Email             string         `gorm:"type:varchar(50); not null"`
+	Amount            int            `gorm:"type:int"`
+	Message           string         `gorm:"type:text; not null"`
+	Status            DonationStatus `gorm:"type:int"`
+	RefundedAmount    int            `gorm:"type:int;not null;default:0"`
+	SnapURL           string         `gorm:"type:varchar(255); not null"`
+	ProgramDonationID uuid.UUID      `gorm:"type:uuid;not null"` // Foreign Key
+
+	// Order id transaksi gateway yang sedang aktif. Pembayaran ulang memakai order id
+	// baru berakhiran "-<percobaan>" karena gateway menolak order id yang sama.
+	OrderID        string     `gorm:"type:varchar(50)"`
+	PaymentAttempt int        `gorm:"type:int;not null;default:1"`
+	SnapExpiresAt  *time.Time // nil berarti link pembayaran tidak punya batas waktu
+
+	// Channel pembayaran (payment type dari gateway, bank_transfer, atau cash) dan waktu
+	// dana diterima. ProofImageURL diisi untuk donasi offline yang dicatat admin.
+	PaymentChannel string `gorm:"type:varchar(30)"`
+	PaidAt         *time.Time
+	ProofImageURL  string `gorm:"type:varchar(255)"`
+
+	// Nama yang tampil di halaman publik. Anonymous menyembunyikan nama sepenuhnya;
+	// tanpa DisplayName pilihan donatur halaman publik memakai nama anonim.
+	Anonymous   bool   `gorm:"not null;default:false"`
+	DisplayName string `gorm:"type:varchar(50)"`
+
+	// Moderasi pesan donatur. Hanya pesan berstatus approved yang tampil di halaman publik,
+	// memakai PublicMessage yang bisa diedit admin tanpa mengubah Message asli.
+	MessageStatus     string     `gorm:"type:varchar(20);not null;default:pending;index"`
+	PublicMessage     string     `gorm:"type:text"`
+	MessageFlag       string     `gorm:"type:varchar(100)"` // alasan pesan ditandai filter otomatis
+	MessageReviewedBy *uuid.UUID `gorm:"type:uuid"`
+	MessageReviewedAt *time.Time
+
+	SubscriptionID *uuid.UUID `gorm:"type:uuid;index"` // terisi untuk donasi dari donasi rutin
+	UserID         *uuid.UUID `gorm:"type:uuid;index"` // akun donatur pemilik donasi, nil untuk donasi tamu
+	DonorProfileID *uuid.UUID `gorm:"type:uuid;index"` // diisi worker direktori donatur
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// Status moderasi pesan donatur. Pesan baru berstatus pending sampai diperiksa filter,
+// lalu approved jika aman atau flagged untuk ditinjau admin.
+const (
+	MessagePending  = "pending"
+	MessageApproved = "approved"
+	MessageFlagged  = "flagged"
+	MessageHidden   = "hidden"
+)
+
+// PublicMessageText mengembalikan pesan donatur yang boleh tampil di endpoint publik
+func (d Donation) PublicMessageText() string {
+	if d.MessageStatus != MessageApproved {
+		return ""
+	}
+	return d.PublicMessage
+}
+
+// Nama pengganti untuk donatur yang memilih anonim
+const AnonymousDonorName = "Hamba Allah"
+
+// PublicName mengembalikan nama donatur yang boleh ditampilkan di endpoint publik. Nama
+// hanya tampil jika donatur memilih nama publik sendiri. DisplayName yang sama dengan
+// nama asli berasal dari donasi lama yang mengisinya otomatis, sehingga tetap anonim.
+func (d Donation) PublicName() string {
+	if d.Anonymous || d.DisplayName == "" || contact.NameKey(d.DisplayName) == contact.NameKey(d.Name) {
+		return AnonymousDonorName
+	}
+	return d.DisplayName
+}
+
+// CurrentOrderID mengembalikan order id transaksi gateway yang aktif.
+// Donasi lama yang belum menyimpan OrderID memakai ID donasi.
+func (d Donation) CurrentOrderID() string {
+	if d.OrderID != "" {
+		return d.OrderID
+	}
+	return d.ID.String()
+}
+
+// DonationOrderID menyusun order id untuk percobaan pembayaran ke-attempt
+func DonationOrderID(donationID uuid.UUID, attempt int) string {
+	if attempt <= 1 {
+		return donationID.String()
+	}
+	return donationID.String() + "-" + strconv.Itoa(attempt)
+}
+
+// ParseDonationOrderID mengambil ID donasi dari order id, termasuk yang berakhiran nomor percobaan
+func ParseDonationOrderID(orderID string) (uuid.UUID, error) {
+	if len(orderID) > 37 && orderID[36] == '-' {
+		if _, err := strconv.Atoi(orderID[37:]); err == nil {
+			orderID = orderID[:36]
+		}
+	}
+	return uuid.Parse(orderID)
 }
 
 type ProgramDonation struct {
@@ -31,9 +122,14 @@ type ProgramDonation struct {
 	CurrentAmount int                    `gorm:"type:int"`
 	DonationImage []ProgramDonationImage `gorm:"foreignKey:ProgramID;references:ID"`
 	Donations     []Donation             `gorm:"foreignKey:ProgramDonationID;references:ID"` // Relasi One-to-Many
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     gorm.DeletedAt `gorm:"index"`
+
+	// Pengaturan pembayaran khusus program, nilai kosong berarti memakai konfigurasi Midtrans
+	PaymentExpiryMinutes int    `gorm:"type:int;not null;default:0"`
+	EnabledPayments      string `gorm:"type:varchar(255)"` // dipisah koma, misal "gopay,bca_va"
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 type ProgramDonationImage struct {
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DonationAllocation adalah bagian nominal donasi untuk satu program. Satu donasi
+// dapat dibagi ke beberapa program dan dibayar dalam satu transaksi.
+type DonationAllocation struct {
+	ID                uuid.UUID `gorm:"primaryKey;type:uuid"`
+	DonationID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	ProgramDonationID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Amount            int       `gorm:"type:int;not null"`
+	CreatedAt         time.Time
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DonationReceipt adalah kuitansi resmi yang diterbitkan saat donasi lunas. Data donatur,
+// nominal, dan program disalin saat terbit agar isi kuitansi tidak berubah.
+type DonationReceipt struct {
+	ID               uuid.UUID `gorm:"primaryKey;type:uuid"`
+	DonationID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	Year             int       `gorm:"not null;uniqueIndex:idx_donation_receipt_sequence"`
+	Sequence         int       `gorm:"not null;uniqueIndex:idx_donation_receipt_sequence"`
+	Number           string    `gorm:"type:varchar(50);not null;uniqueIndex"`
+	VerificationCode string    `gorm:"type:varchar(20);not null"`
+	DonorName        string    `gorm:"type:varchar(255);not null"`
+	DonorEmail       string    `gorm:"type:varchar(255)"`
+	DonorAddress     string    `gorm:"type:text"`
+	Amount           int       `gorm:"not null"`
+	ProgramTitles    string    `gorm:"type:text;not null"`
+	PaymentChannel   string    `gorm:"type:varchar(50)"`
+	PaidAt           time.Time `gorm:"not null"`
+	IssuedAt         time.Time `gorm:"not null"`
+	CreatedAt        time.Time
+}
+
+// ReceiptSequence menyimpan nomor kuitansi terakhir setiap tahun. Nomor diambil di dalam
+// transaksi pelunasan sehingga rollback juga mengembalikan nomor dan urutan tidak berlubang.
+type ReceiptSequence struct {
+	Year       int `gorm:"primaryKey;autoIncrement:false"`
+	LastNumber int `gorm:"not null"`
+}
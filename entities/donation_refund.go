@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RefundMethodGateway = "gateway"
+	RefundMethodManual  = "manual"
+)
+
+// Status refund. Refund gateway dicatat pending sebelum gateway dipanggil dan baru
+// mengurangi dana donasi setelah completed, sehingga refund yang terputus di tengah
+// jalan tetap tercatat dan bisa dilanjutkan.
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusCompleted = "completed"
+	RefundStatusFailed    = "failed"
+)
+
+// DonationRefund adalah catatan audit setiap pengembalian dana donasi
+type DonationRefund struct {
+	ID            uuid.UUID `gorm:"primaryKey;type:uuid"`
+	DonationID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Amount        int       `gorm:"type:int;not null"`
+	Reason        string    `gorm:"type:text;not null"`
+	Method        string    `gorm:"type:varchar(20);not null"`
+	RefundKey     string    `gorm:"type:varchar(100)"`
+	GatewayStatus string    `gorm:"type:varchar(50)"`
+	Status        string    `gorm:"type:varchar(20);not null;default:'completed';index"`
+	FailureReason string    `gorm:"type:text"`
+	AdminID       uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,92 @@
+package entities
+
+// DonationStatus adalah status pembayaran sebuah donasi. Nilainya disimpan
+// sebagai int agar data lama (0 = pending, 1 = berhasil, 2 = gagal) tetap valid.
+type DonationStatus int
+
+const (
+	DonationStatusPending           DonationStatus = 0
+	DonationStatusPaid              DonationStatus = 1
+	DonationStatusFailed            DonationStatus = 2
+	DonationStatusExpired           DonationStatus = 3
+	DonationStatusRefunded          DonationStatus = 4
+	DonationStatusChallenged        DonationStatus = 5
+	DonationStatusPartiallyRefunded DonationStatus = 6
+)
+
+// DonationCollectedStatuses adalah status donasi yang dananya (sebagian) sudah diterima yayasan
+var DonationCollectedStatuses = []DonationStatus{
+	DonationStatusPaid,
+	DonationStatusPartiallyRefunded,
+}
+
+var donationStatusLabels = map[DonationStatus]string{
+	DonationStatusPending:           "pending",
+	DonationStatusPaid:              "paid",
+	DonationStatusFailed:            "failed",
+	DonationStatusExpired:           "expired",
+	DonationStatusRefunded:          "refunded",
+	DonationStatusChallenged:        "challenged",
+	DonationStatusPartiallyRefunded: "partially_refunded",
+}
+
+// donationStatusTransitions berisi perpindahan status yang diizinkan.
+// Status yang tidak memiliki entri adalah status akhir.
+var donationStatusTransitions = map[DonationStatus][]DonationStatus{
+	DonationStatusPending: {
+		DonationStatusPaid,
+		DonationStatusFailed,
+		DonationStatusExpired,
+		DonationStatusChallenged,
+	},
+	DonationStatusChallenged: {
+		DonationStatusPaid,
+		DonationStatusFailed,
+	},
+	DonationStatusPaid: {
+		DonationStatusRefunded,
+		DonationStatusPartiallyRefunded,
+	},
+	DonationStatusPartiallyRefunded: {
+		DonationStatusRefunded,
+	},
+}
+
+func (s DonationStatus) String() string {
+	if label, ok := donationStatusLabels[s]; ok {
+		return label
+	}
+	return "unknown"
+}
+
+// CanTransitionTo memeriksa apakah status boleh berpindah ke status next.
+func (s DonationStatus) CanTransitionTo(next DonationStatus) bool {
+	for _, allowed := range donationStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsLateSettlement bernilai true jika donasi yang sudah berakhir tetap boleh menjadi
+// paid saat gateway mengonfirmasi pembayaran, misalnya settlement yang datang setelah
+// donasi dikedaluwarsakan oleh job lokal. Dana sudah diterima sehingga wajib dicatat.
+func (s DonationStatus) AcceptsLateSettlement() bool {
+	return s == DonationStatusExpired || s == DonationStatusFailed
+}
+
+// IsCollected bernilai true jika dana donasi (sebagian) sudah diterima
+func (s DonationStatus) IsCollected() bool {
+	for _, collected := range DonationCollectedStatuses {
+		if s == collected {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal bernilai true jika status tidak dapat berpindah lagi.
+func (s DonationStatus) IsFinal() bool {
+	return len(donationStatusTransitions[s]) == 0
+}
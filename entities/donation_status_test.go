@@ -0,0 +1,71 @@
+package entities
+
+import "testing"
+
+func TestDonationStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from DonationStatus
+		to   DonationStatus
+		want bool
+	}{
+		{DonationStatusPending, DonationStatusPaid, true},
+		{DonationStatusPending, DonationStatusFailed, true},
+		{DonationStatusPending, DonationStatusExpired, true},
+		{DonationStatusPending, DonationStatusChallenged, true},
+		{DonationStatusPending, DonationStatusRefunded, false},
+		{DonationStatusPending, DonationStatusPartiallyRefunded, false},
+		{DonationStatusChallenged, DonationStatusPaid, true},
+		{DonationStatusChallenged, DonationStatusFailed, true},
+		{DonationStatusChallenged, DonationStatusExpired, false},
+		{DonationStatusChallenged, DonationStatusRefunded, false},
+		{DonationStatusPaid, DonationStatusRefunded, true},
+		{DonationStatusPaid, DonationStatusPartiallyRefunded, true},
+		{DonationStatusPaid, DonationStatusPending, false},
+		{DonationStatusPaid, DonationStatusFailed, false},
+		{DonationStatusPaid, DonationStatusExpired, false},
+		{DonationStatusPartiallyRefunded, DonationStatusRefunded, true},
+		{DonationStatusPartiallyRefunded, DonationStatusPaid, false},
+		{DonationStatusRefunded, DonationStatusPaid, false},
+		{DonationStatusRefunded, DonationStatusPartiallyRefunded, false},
+		{DonationStatusFailed, DonationStatusPaid, false},
+		{DonationStatusExpired, DonationStatusPaid, false},
+		{DonationStatusExpired, DonationStatusPending, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from.String()+" to "+tt.to.String(), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("%s.CanTransitionTo(%s) = %t, want %t", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDonationStatusFlags(t *testing.T) {
+	tests := []struct {
+		status             DonationStatus
+		wantFinal          bool
+		wantCollected      bool
+		wantLateSettlement bool
+	}{
+		{DonationStatusPending, false, false, false},
+		{DonationStatusChallenged, false, false, false},
+		{DonationStatusPaid, false, true, false},
+		{DonationStatusPartiallyRefunded, false, true, false},
+		{DonationStatusRefunded, true, false, false},
+		{DonationStatusFailed, true, false, true},
+		{DonationStatusExpired, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status.String(), func(t *testing.T) {
+			if got := tt.status.IsFinal(); got != tt.wantFinal {
+				t.Errorf("%s.IsFinal() = %t, want %t", tt.status, got, tt.wantFinal)
+			}
+			if got := tt.status.IsCollected(); got != tt.wantCollected {
+				t.Errorf("%s.IsCollected() = %t, want %t", tt.status, got, tt.wantCollected)
+			}
+			if got := tt.status.AcceptsLateSettlement(); got != tt.wantLateSettlement {
+				t.Errorf("%s.AcceptsLateSettlement() = %t, want %t", tt.status, got, tt.wantLateSettlement)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"time"
+	"tugas-akhir/utils/phone"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SubscriptionActive    = "active"
+	SubscriptionPaused    = "paused"
+	SubscriptionCancelled = "cancelled"
+	SubscriptionSuspended = "suspended" // berhenti otomatis setelah percobaan penagihan habis
+)
+
+const (
+	SubscriptionWeekly  = "weekly"
+	SubscriptionMonthly = "monthly"
+	SubscriptionYearly  = "yearly"
+)
+
+// DonationSubscription adalah donasi rutin dengan nominal dan program tetap. Setiap
+// siklus membuat satu Donation; CurrentDonationID terisi selama siklus belum selesai.
+type DonationSubscription struct {
+	ID                uuid.UUID    `gorm:"primaryKey;type:uuid"`
+	Name              string       `gorm:"type:varchar(50);not null"`
+	Address           string       `gorm:"type:varchar(255)"`
+	NoWA              phone.Number `gorm:"type:varchar(16)"`
+	Email             string       `gorm:"type:varchar(50);not null;index"`
+	Message           string       `gorm:"type:text"`
+	ProgramDonationID uuid.UUID    `gorm:"type:uuid;not null"`
+	Amount            int          `gorm:"type:int;not null"`
+	Interval          string       `gorm:"type:varchar(10);not null"`
+	Status            string       `gorm:"type:varchar(20);not null;index"`
+	UserID            *uuid.UUID   `gorm:"type:uuid;index"` // akun donatur pemilik donasi rutin
+	Anonymous         bool         `gorm:"not null;default:false"`
+	DisplayName       string       `gorm:"type:varchar(50)"`
+
+	NextChargeAt      time.Time  `gorm:"not null;index"`
+	AnchorDay         int        `gorm:"type:int;not null;default:0"` // tanggal tagih bulanan, 0 untuk data lama
+	RetryAt           *time.Time // jadwal penagihan ulang setelah siklus gagal
+	FailedAttempts    int        `gorm:"type:int;not null;default:0"`
+	CurrentDonationID *uuid.UUID `gorm:"type:uuid"`
+	LastChargedAt     *time.Time
+
+	// Token kartu dari gateway untuk penagihan otomatis, kosong berarti kirim link pembayaran
+	SavedTokenID    string `gorm:"type:varchar(255)"`
+	ManageTokenHash string `gorm:"type:varchar(64);not null"`
+
+	CancelledAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NextCycle menghitung tanggal penagihan berikutnya setelah from. Siklus bulanan dan
+// tahunan kembali ke AnchorDay, dipotong ke akhir bulan jika bulan tersebut lebih pendek,
+// sehingga donasi rutin tanggal 31 ditagih 28/29 Februari lalu 31 Maret.
+func (s DonationSubscription) NextCycle(from time.Time) time.Time {
+	switch s.Interval {
+	case SubscriptionWeekly:
+		return from.AddDate(0, 0, 7)
+	case SubscriptionYearly:
+		return s.addMonths(from, 12)
+	}
+	return s.addMonths(from, 1)
+}
+
+func (s DonationSubscription) addMonths(from time.Time, months int) time.Time {
+	day := s.AnchorDay
+	if day <= 0 {
+		day = from.Day()
+	}
+
+	firstOfMonth := time.Date(from.Year(), from.Month()+time.Month(months), 1, from.Hour(), from.Minute(), from.Second(), from.Nanosecond(), from.Location())
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDonationSubscriptionNextCycle(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		interval  string
+		anchorDay int
+		from      time.Time
+		want      time.Time
+	}{
+		{"weekly", SubscriptionWeekly, 31, date(2026, time.January, 31), date(2026, time.February, 7)},
+		{"monthly mid month", SubscriptionMonthly, 15, date(2026, time.January, 15), date(2026, time.February, 15)},
+		{"monthly clamps to end of february", SubscriptionMonthly, 31, date(2026, time.January, 31), date(2026, time.February, 28)},
+		{"monthly clamps to leap day", SubscriptionMonthly, 31, date(2028, time.January, 31), date(2028, time.February, 29)},
+		{"monthly returns to anchor after short month", SubscriptionMonthly, 31, date(2026, time.February, 28), date(2026, time.March, 31)},
+		{"monthly clamps to 30 day month", SubscriptionMonthly, 31, date(2026, time.March, 31), date(2026, time.April, 30)},
+		{"monthly across year end", SubscriptionMonthly, 31, date(2026, time.December, 31), date(2027, time.January, 31)},
+		{"monthly without anchor keeps day", SubscriptionMonthly, 0, date(2026, time.January, 20), date(2026, time.February, 20)},
+		{"monthly without anchor clamps", SubscriptionMonthly, 0, date(2026, time.January, 31), date(2026, time.February, 28)},
+		{"yearly from leap day", SubscriptionYearly, 29, date(2028, time.February, 29), date(2029, time.February, 28)},
+		{"yearly returns to leap day", SubscriptionYearly, 29, date(2031, time.February, 28), date(2032, time.February, 29)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := DonationSubscription{Interval: tt.interval, AnchorDay: tt.anchorDay}
+			if got := subscription.NextCycle(tt.from); !got.Equal(tt.want) {
+				t.Errorf("NextCycle(%s) = %s, want %s", tt.from.Format(time.DateOnly), got.Format(time.DateTime), tt.want.Format(time.DateTime))
+			}
+		})
+	}
+}
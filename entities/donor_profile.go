@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"time"
+	"tugas-akhir/utils/phone"
+
+	"github.com/google/uuid"
+)
+
+// Jenis identitas yang dipakai untuk mengelompokkan donasi ke profil donatur
+const (
+	DonorIdentifierEmail = "email"
+	DonorIdentifierPhone = "phone"
+)
+
+// Status dugaan profil ganda
+const (
+	DonorDuplicateOpen      = "open"
+	DonorDuplicateMerged    = "merged"
+	DonorDuplicateDismissed = "dismissed"
+)
+
+// Alasan sebuah pasangan profil diduga milik orang yang sama
+const (
+	DonorDuplicateSharedPhone  = "shared_phone"  // nomor WhatsApp sama dengan email berbeda
+	DonorDuplicateSimilarEmail = "similar_email" // nama sama dan email hampir sama (salah ketik)
+)
+
+// DonorProfile mengelompokkan donasi dari orang yang sama. Profil yang sudah digabung
+// menyimpan MergedIntoID dan tidak lagi dipakai.
+type DonorProfile struct {
+	ID           uuid.UUID    `gorm:"primaryKey;type:uuid"`
+	Name         string       `gorm:"type:varchar(50);not null"`
+	Email        string       `gorm:"type:varchar(50)"`
+	NoWA         phone.Number `gorm:"type:varchar(16)"`
+	UserID       *uuid.UUID   `gorm:"type:uuid;index"` // akun donatur, jika ada
+	MergedIntoID *uuid.UUID   `gorm:"type:uuid;index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// DonorIdentifier adalah email atau nomor WhatsApp yang sudah dinormalkan milik sebuah
+// profil. Satu identitas hanya boleh dimiliki satu profil.
+type DonorIdentifier struct {
+	ID        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	ProfileID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Kind      string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_donor_identifier_value"`
+	Value     string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_donor_identifier_value"`
+	CreatedAt time.Time
+}
+
+// DonorDuplicate adalah dugaan dua profil milik orang yang sama, menunggu ditinjau admin
+type DonorDuplicate struct {
+	ID          uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	ProfileID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_donor_duplicate_pair"`
+	CandidateID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_donor_duplicate_pair"`
+	Reason      string     `gorm:"type:varchar(30);not null"`
+	Status      string     `gorm:"type:varchar(20);not null;index"`
+	ReviewedBy  *uuid.UUID `gorm:"type:uuid"`
+	ReviewedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// DonorProfileSummary adalah profil donatur beserta rekap donasinya, hasil agregasi
+// dan bukan tabel
+type DonorProfileSummary struct {
+	DonorProfile
+	DonationCount   int64
+	TotalAmount     int64
+	FirstDonationAt *time.Time
+	LastDonationAt  *time.Time
+}
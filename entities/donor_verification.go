@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kanal verifikasi akun donatur
+const (
+	VerificationChannelEmail = "email"
+	VerificationChannelPhone = "phone"
+)
+
+// DonorVerification adalah token verifikasi email atau kode verifikasi nomor WhatsApp
+// akun donatur. Hanya hash token yang disimpan.
+type DonorVerification struct {
+	ID        uuid.UUID `gorm:"primaryKey;type:uuid"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Channel   string    `gorm:"type:varchar(10);not null"`
+	TokenHash string    `gorm:"type:varchar(64);not null;index"`
+	Attempts  int       `gorm:"type:int;not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
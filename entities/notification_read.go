@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationRead mencatat notifikasi pembayaran yang sudah dibaca oleh seorang admin.
+// Setiap admin punya status baca sendiri.
+type NotificationRead struct {
+	NotificationID uuid.UUID `gorm:"primaryKey;type:uuid"`
+	AdminID        uuid.UUID `gorm:"primaryKey;type:uuid;index"`
+	ReadAt         time.Time `gorm:"not null"`
+}
+
+// DonationNotificationSummary adalah notifikasi pembayaran lunas beserta data donasi,
+// judul program, dan waktu dibaca admin, hasil join dan bukan tabel
+type DonationNotificationSummary struct {
+	ID                uuid.UUID
+	GrossAmount       string
+	TransactionStatus string
+	CreatedAt         time.Time
+	DonationID        uuid.UUID
+	Name              string
+	Message           string
+	Amount            int
+	ProgramDonationID uuid.UUID
+	ProgramTitle      string
+	ReadAt            *time.Time
+}
+
+// NotificationAllocation adalah alokasi donasi beserta judul programnya
+type NotificationAllocation struct {
+	DonationID        uuid.UUID
+	ProgramDonationID uuid.UUID
+	ProgramTitle      string
+	Amount            int
+}
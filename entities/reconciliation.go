@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconciliationRun mencatat satu kali proses rekonsiliasi donasi pending dengan payment gateway
+type ReconciliationRun struct {
+	ID         uuid.UUID `gorm:"primaryKey;type:uuid"`
+	Trigger    string    `gorm:"type:varchar(20); not null"`
+	Checked    int       `gorm:"type:int"`
+	Updated    int       `gorm:"type:int"`
+	Failed     int       `gorm:"type:int"`
+	StartedAt  time.Time `gorm:"index"`
+	FinishedAt time.Time
+	Items      []ReconciliationItem `gorm:"foreignKey:RunID;references:ID"`
+}
+
+// ReconciliationItem mencatat donasi yang statusnya diubah atau gagal dicek saat rekonsiliasi
+type ReconciliationItem struct {
+	ID             uuid.UUID      `gorm:"primaryKey;type:uuid"`
+	RunID          uuid.UUID      `gorm:"type:uuid;index"`
+	DonationID     uuid.UUID      `gorm:"type:uuid"`
+	OrderID        string         `gorm:"type:varchar(50)"`
+	GatewayStatus  string         `gorm:"type:varchar(50)"`
+	PreviousStatus DonationStatus `gorm:"type:int"`
+	NewStatus      DonationStatus `gorm:"type:int"`
+	Error          string         `gorm:"type:text"`
+	CreatedAt      time.Time
+}
@@ -0,0 +1,91 @@
+package entities
+
+// Role admin. Hak akses setiap role disimpan di tabel role_permissions, kecuali super
+// admin yang selalu memiliki semua hak akses.
+const (
+	RoleSuperAdmin    = "super_admin"
+	RoleFinance       = "finance"
+	RoleContentEditor = "content_editor"
+	RoleViewer        = "viewer"
+)
+
+// Hak akses yang diperiksa oleh policy route
+const (
+	PermissionDonationRead    = "donation:read"
+	PermissionDonationManage  = "donation:manage"
+	PermissionRefundManage    = "refund:manage"
+	PermissionReportRead      = "report:read"
+	PermissionDonorRead       = "donor:read"
+	PermissionDonorManage     = "donor:manage"
+	PermissionMessageModerate = "message:moderate"
+	PermissionContentManage   = "content:manage"
+	PermissionRoleManage      = "role:manage"
+	PermissionAdminManage     = "admin:manage"
+)
+
+type Role struct {
+	Name        string `gorm:"primaryKey;type:varchar(30)"`
+	Description string `gorm:"type:varchar(255);not null"`
+}
+
+type Permission struct {
+	Name        string `gorm:"primaryKey;type:varchar(50)"`
+	Description string `gorm:"type:varchar(255);not null"`
+}
+
+// RolePermission memetakan role ke hak aksesnya
+type RolePermission struct {
+	RoleName       string `gorm:"primaryKey;type:varchar(30)"`
+	PermissionName string `gorm:"primaryKey;type:varchar(50);index"`
+}
+
+// DefaultRoles adalah role bawaan beserta hak akses awalnya. Hak akses awal hanya
+// diisi saat role pertama kali dibuat, perubahan oleh super admin tidak ditimpa.
+var DefaultRoles = []struct {
+	Role        Role
+	Permissions []string
+}{
+	{
+		Role:        Role{Name: RoleSuperAdmin, Description: "Semua hak akses termasuk mengatur role admin"},
+		Permissions: nil,
+	},
+	{
+		Role: Role{Name: RoleFinance, Description: "Donasi, refund, dan laporan keuangan"},
+		Permissions: []string{
+			PermissionDonationRead, PermissionDonationManage, PermissionRefundManage,
+			PermissionReportRead, PermissionDonorRead, PermissionDonorManage,
+		},
+	},
+	{
+		Role:        Role{Name: RoleContentEditor, Description: "Kegiatan, program donasi, media, dan pesan donatur"},
+		Permissions: []string{PermissionContentManage, PermissionMessageModerate},
+	},
+	{
+		Role:        Role{Name: RoleViewer, Description: "Hanya melihat data donasi dan laporan"},
+		Permissions: []string{PermissionDonationRead, PermissionReportRead},
+	},
+}
+
+// DefaultPermissions adalah daftar hak akses yang dikenal aplikasi
+var DefaultPermissions = []Permission{
+	{Name: PermissionDonationRead, Description: "Melihat donasi, notifikasi, dan donasi rutin"},
+	{Name: PermissionDonationManage, Description: "Mencatat donasi offline, meninjau bukti transfer, dan rekonsiliasi"},
+	{Name: PermissionRefundManage, Description: "Melakukan refund donasi"},
+	{Name: PermissionReportRead, Description: "Melihat laporan rekonsiliasi dan mengekspor data donatur"},
+	{Name: PermissionDonorRead, Description: "Melihat data dan profil donatur"},
+	{Name: PermissionDonorManage, Description: "Menggabungkan profil donatur ganda"},
+	{Name: PermissionMessageModerate, Description: "Memoderasi pesan dan doa donatur"},
+	{Name: PermissionContentManage, Description: "Mengelola kegiatan, pengurus panti, program donasi, dan media"},
+	{Name: PermissionRoleManage, Description: "Mengatur role dan hak akses admin"},
+	{Name: PermissionAdminManage, Description: "Mengundang, mengubah, menonaktifkan, dan menghapus akun admin"},
+}
+
+// IsAdminRole menandakan role milik akun admin, bukan donatur
+func IsAdminRole(role string) bool {
+	for _, defaultRole := range DefaultRoles {
+		if defaultRole.Role.Name == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session adalah sesi login admin atau donatur. Refresh token dirotasi setiap kali
+// dipakai dan hanya hash-nya yang disimpan. Hash refresh token sebelumnya ikut disimpan
+// agar refresh token lama yang dipakai ulang bisa dikenali dan sesinya dicabut.
+type Session struct {
+	ID                uuid.UUID `gorm:"primaryKey;type:uuid"`
+	SubjectID         uuid.UUID `gorm:"type:uuid;not null;index"`
+	Role              string    `gorm:"type:varchar(30);not null"`
+	RefreshTokenHash  string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	PreviousTokenHash string    `gorm:"type:varchar(64);not null;default:'';index"`
+	UserAgent         string    `gorm:"type:varchar(255);not null;default:''"`
+	IPAddress         string    `gorm:"type:varchar(45);not null;default:''"`
+	ExpiresAt         time.Time `gorm:"not null;index"`
+	LastUsedAt        time.Time `gorm:"not null"`
+	RevokedAt         *time.Time
+	CreatedAt         time.Time
+}
+
+// IsActive menandakan refresh token sesi masih bisa dipakai
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
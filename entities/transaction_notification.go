@@ -11,10 +11,12 @@ import (
 type TransactionNotification struct {
 	ID                uuid.UUID `gorm:"primaryKey;type:uuid"`
 	OrderID           string    `gorm:"type:varchar(50);not null"`
-	TransactionStatus string    `gorm:"type:varchar(50);not null"`
+	TransactionID     string    `gorm:"type:varchar(100);uniqueIndex:idx_transaction_notification_dedup,where:transaction_id <> ''"`
+	TransactionStatus string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_transaction_notification_dedup,where:transaction_id <> ''"`
 	GrossAmount       string    `gorm:"type:varchar(50);not null"`
 	TransactionTime   string    `gorm:"type:varchar(50);not null"`
 	SignatureKey      string    `gorm:"type:varchar(255);not null"`
+	SavedTokenID      string    `gorm:"type:varchar(255)"` // token kartu jika donatur menyimpan kartunya
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 	DeletedAt         gorm.DeletedAt `gorm:"index"`
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TransferProofPending  = "pending"
+	TransferProofApproved = "approved"
+	TransferProofRejected = "rejected"
+)
+
+// Channel pembayaran donasi di luar payment gateway
+const (
+	PaymentChannelBankTransfer = "bank_transfer"
+	PaymentChannelCash         = "cash"
+)
+
+// TransferProof adalah bukti transfer yang dikirim donatur untuk donasi pending
+// dan menunggu verifikasi admin
+type TransferProof struct {
+	ID            uuid.UUID  `gorm:"primaryKey;type:uuid"`
+	DonationID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ImageURL      string     `gorm:"type:varchar(255);not null"`
+	BankName      string     `gorm:"type:varchar(50)"`
+	AccountName   string     `gorm:"type:varchar(100)"`
+	TransferredAt time.Time  `gorm:"not null"`
+	Status        string     `gorm:"type:varchar(20);not null;index"`
+	RejectReason  string     `gorm:"type:text"`
+	ReviewedBy    *uuid.UUID `gorm:"type:uuid"`
+	ReviewedAt    *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
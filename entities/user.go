@@ -1,13 +1,32 @@
 package entities
 
 import (
+	"time"
+	"tugas-akhir/utils/phone"
+
 	"github.com/google/uuid"
 )
 
+// RoleDonor adalah role JWT untuk donatur yang login
+const RoleDonor = "donor"
+
 type User struct {
-	ID      uuid.UUID `gorm:"primaryKey;type:uuid"`
-	Name    string    `gorm:"type:varchar(50); not null"`
-	Email   string    `gorm:"type:varchar(50); not null"`
-	Address string    `gorm:"type:varchar(255); not null"`
-	NoWA    int       `gorm:"type:int"`
+	ID      uuid.UUID    `gorm:"primaryKey;type:uuid"`
+	Name    string       `gorm:"type:varchar(50); not null"`
+	Email   string       `gorm:"type:varchar(50); not null;uniqueIndex:idx_user_registered_email,where:password <> ''"`
+	Address string       `gorm:"type:varchar(255); not null"`
+	NoWA    phone.Number `gorm:"type:varchar(16)"`
+
+	// Akun donatur. Data donatur lama yang tidak punya password tidak bisa login,
+	// dan email hanya unik di antara akun yang terdaftar.
+	Password        string `gorm:"type:varchar(255);not null;default:''"`
+	EmailVerifiedAt *time.Time
+	PhoneVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// IsRegistered menandakan data donatur sudah menjadi akun yang bisa login
+func (u User) IsRegistered() bool {
+	return u.Password != ""
 }
@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"time"
+	"tugas-akhir/utils/phone"
+
+	"github.com/google/uuid"
+)
+
+// Status pesan WhatsApp. Pesan dimulai dari queued lalu maju sesuai laporan gateway.
+const (
+	WhatsAppStatusQueued    = "queued"
+	WhatsAppStatusSent      = "sent"
+	WhatsAppStatusDelivered = "delivered"
+	WhatsAppStatusRead      = "read"
+	WhatsAppStatusFailed    = "failed"
+)
+
+// Sumber opt-out WhatsApp
+const (
+	WhatsAppOptOutDonor = "donor"
+	WhatsAppOptOutReply = "reply"
+)
+
+// WhatsAppMessage adalah antrean sekaligus riwayat pesan WhatsApp ke donatur. Kombinasi
+// template, nomor, dan referensi unik sehingga pesan yang sama tidak terkirim dua kali,
+// misalnya ucapan terima kasih untuk satu donasi atau kabar program untuk satu bulan.
+type WhatsAppMessage struct {
+	ID                uuid.UUID    `gorm:"primaryKey;type:uuid"`
+	Phone             phone.Number `gorm:"type:varchar(16);not null;uniqueIndex:idx_whatsapp_message_dedup,priority:2"`
+	Template          string       `gorm:"type:varchar(30);not null;uniqueIndex:idx_whatsapp_message_dedup,priority:1"`
+	Reference         string       `gorm:"type:varchar(64);not null;uniqueIndex:idx_whatsapp_message_dedup,priority:3"`
+	Body              string       `gorm:"type:text;not null"`
+	Status            string       `gorm:"type:varchar(10);not null;index"`
+	Attempts          int          `gorm:"type:int;not null;default:0"`
+	NextAttemptAt     time.Time    `gorm:"not null"`
+	ProviderMessageID string       `gorm:"type:varchar(64);index"`
+	Error             string       `gorm:"type:varchar(255)"`
+	DonationID        *uuid.UUID   `gorm:"type:uuid;index"`
+	SentAt            *time.Time
+	DeliveredAt       *time.Time
+	ReadAt            *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// whatsAppStatusRank mengurutkan status agar laporan gateway yang datang terlambat
+// tidak memundurkan status, misalnya sent yang tiba setelah delivered
+var whatsAppStatusRank = map[string]int{
+	WhatsAppStatusQueued:    0,
+	WhatsAppStatusSent:      1,
+	WhatsAppStatusDelivered: 2,
+	WhatsAppStatusRead:      3,
+}
+
+// AdvanceStatus menerapkan status baru dari gateway dan mengembalikan false jika status
+// tidak berubah. Failed hanya berlaku selama pesan belum sampai ke penerima.
+func (m *WhatsAppMessage) AdvanceStatus(status string, at time.Time) bool {
+	if status == WhatsAppStatusFailed {
+		if m.Status != WhatsAppStatusQueued && m.Status != WhatsAppStatusSent {
+			return false
+		}
+		m.Status = status
+		return true
+	}
+
+	rank, ok := whatsAppStatusRank[status]
+	if !ok || m.Status == WhatsAppStatusFailed || rank <= whatsAppStatusRank[m.Status] {
+		return false
+	}
+
+	m.Status = status
+	switch status {
+	case WhatsAppStatusSent:
+		m.SentAt = &at
+	case WhatsAppStatusDelivered:
+		m.DeliveredAt = &at
+	case WhatsAppStatusRead:
+		if m.DeliveredAt == nil {
+			m.DeliveredAt = &at
+		}
+		m.ReadAt = &at
+	}
+	return true
+}
+
+// WhatsAppOptOut adalah nomor yang tidak mau lagi menerima pesan WhatsApp selain kode
+// verifikasi. Opt-out disimpan per nomor agar juga berlaku untuk donasi tanpa akun.
+type WhatsAppOptOut struct {
+	Phone     phone.Number `gorm:"primaryKey;type:varchar(16)"`
+	Source    string       `gorm:"type:varchar(10);not null"`
+	CreatedAt time.Time
+}
+
+// WhatsAppUpdateRecipient adalah nomor donatur dan satu program yang pernah didukungnya,
+// hasil query kabar program bulanan dan bukan tabel
+type WhatsAppUpdateRecipient struct {
+	Phone             phone.Number
+	Name              string
+	ProgramDonationID uuid.UUID
+}
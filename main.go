@@ -44,6 +44,8 @@ func main() {
 			echo.HeaderAccept,
 			echo.HeaderAuthorization,
 			echo.HeaderXCSRFToken,
+			"Idempotency-Key",
+			"X-Subscription-Token",
 			// Tambahkan header khusus Midtrans jika ada
 		},
 		AllowMethods: []string{
@@ -1,12 +1,16 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
 	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/entities"
 	http_util "tugas-akhir/utils/http"
 	"tugas-akhir/utils/token"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
 )
 
 
@@ -14,8 +18,8 @@ func IsAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		claims := token.NewTokenUtil().GetClaims(c)
 		
-		// Memeriksa apakah role pengguna adalah admin
-		if claims.Role != "admin" {
+		// Memeriksa apakah role pengguna adalah salah satu role admin
+		if !entities.IsAdminRole(claims.Role) {
 			return http_util.HandleErrorResponse(
 				c,
 				http.StatusForbidden, 
@@ -27,8 +31,69 @@ func IsAdmin(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// PermissionChecker memeriksa hak akses sebuah role, diimplementasikan oleh RoleUsecase
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role string, permission string) (bool, error)
+}
+
+// HasPermission memeriksa apakah role admin yang login memiliki hak akses yang diberikan
+func HasPermission(checker PermissionChecker, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := token.NewTokenUtil().GetClaims(c)
+			if !entities.IsAdminRole(claims.Role) {
+				return http_util.HandleErrorResponse(c, http.StatusForbidden, msg.FORBIDDEN_ACCESS)
+			}
+
+			allowed, err := checker.HasPermission(c.Request().Context(), claims.Role, permission)
+			if err != nil {
+				logrus.New().WithError(err).Errorf("Failed to check permission %s of role %s", permission, claims.Role)
+				return http_util.HandleErrorResponse(c, http.StatusInternalServerError, msg.FAILED_CHECK_PERMISSION)
+			}
+			if !allowed {
+				return http_util.HandleErrorResponse(c, http.StatusForbidden, msg.FORBIDDEN_ACCESS)
+			}
+
+			return next(c)
+		}
+	}
+}
+
 
 
+// SessionChecker memeriksa apakah sesi login sudah dicabut, diimplementasikan oleh SessionUsecase
+type SessionChecker interface {
+	IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+// ActiveSession menolak access token yang sesinya sudah dicabut lewat logout, pergantian
+// password, atau penonaktifan akun, meskipun token tersebut belum kedaluwarsa. Request
+// tanpa token pada route Optional tetap diteruskan.
+func ActiveSession(sessions SessionChecker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims := token.OptionalClaims(c)
+			if claims == nil {
+				return next(c)
+			}
+			if claims.SessionID == uuid.Nil {
+				return http_util.HandleErrorResponse(c, http.StatusUnauthorized, msg.INVALID_TOKEN)
+			}
+
+			revoked, err := sessions.IsSessionRevoked(c.Request().Context(), claims.SessionID)
+			if err != nil {
+				logrus.New().WithError(err).Errorf("Failed to check session %s", claims.SessionID)
+				return http_util.HandleErrorResponse(c, http.StatusInternalServerError, msg.FAILED_CHECK_SESSION)
+			}
+			if revoked {
+				return http_util.HandleErrorResponse(c, http.StatusUnauthorized, msg.INVALID_TOKEN)
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // HasAnyRole memeriksa apakah pengguna memiliki salah satu dari role yang diberikan
 func HasAnyRole(roles ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
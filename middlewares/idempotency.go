@@ -0,0 +1,162 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/drivers/redis"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// idempotencyRecord disimpan di Redis selama request diproses (Completed false)
+// dan setelah respons pertama selesai (Completed true)
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// IdempotencyStore adalah penyimpanan record idempotensi, dipenuhi oleh redis.RedisClient.
+// Get harus mengembalikan redis.Nil jika key tidak ada.
+type IdempotencyStore interface {
+	SetNX(key string, value string, expiration time.Duration) (bool, error)
+	Get(key string) (string, error)
+	Set(key string, value string, expiration time.Duration) error
+	Del(key string) error
+}
+
+var _ IdempotencyStore = (*redis.RedisClient)(nil)
+
+// Idempotency menyimpan respons pertama untuk setiap header Idempotency-Key selama ttl.
+// Key berlaku per route dan per akun yang login, sehingga akun lain yang kebetulan memakai
+// key yang sama tidak mendapat respons milik orang lain. Request tanpa login berbagi satu
+// ruang key per route, jadi klien tamu perlu memakai key acak seperti UUID. Request ulang
+// dengan body yang sama mendapat respons tersimpan, sedangkan key yang dipakai ulang
+// dengan body berbeda ditolak dengan 409. Penanda request yang sedang diproses hanya
+// berlaku selama lockTTL dan baru diperpanjang menjadi ttl saat respons disimpan, sehingga
+// key dari request yang terhenti di tengah jalan bisa dipakai lagi. Jika Redis tidak
+// tersedia, request tetap diteruskan tanpa perlindungan idempotensi.
+func Idempotency(store IdempotencyStore, lockTTL time.Duration, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+
+			log := logrus.New()
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return http_util.HandleErrorResponse(c, http.StatusBadRequest, msg.INVALID_REQUEST_DATA)
+			}
+			c.Request().Body = io.NopCloser(bytes.NewBuffer(body))
+
+			hash := sha256.Sum256(append([]byte(c.Request().Method+" "+c.Path()+"\n"), body...))
+			fingerprint := hex.EncodeToString(hash[:])
+			storageKey := "idempotency:" + c.Request().Method + ":" + c.Path() + ":" + idempotencyCaller(c) + ":" + key
+
+			pending, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+			acquired, err := store.SetNX(storageKey, string(pending), lockTTL)
+			if err != nil {
+				log.WithError(err).Warn("Idempotency store unavailable, processing request without it")
+				return next(c)
+			}
+
+			if !acquired {
+				stored, err := store.Get(storageKey)
+				// Penanda hilang di antara SetNX dan Get karena kadaluarsa atau dilepas
+				// request lain. Request lain itu mungkin masih berjalan, jadi klien diminta
+				// mencoba lagi alih-alih memproses request tanpa perlindungan.
+				if errors.Is(err, goredis.Nil) {
+					return http_util.HandleErrorResponse(c, http.StatusConflict, msg.IDEMPOTENCY_REQUEST_IN_PROGRESS)
+				}
+				if err != nil {
+					log.WithError(err).Warn("Failed to read idempotency record, processing request without it")
+					return next(c)
+				}
+
+				var record idempotencyRecord
+				if err := json.Unmarshal([]byte(stored), &record); err != nil {
+					log.WithError(err).Warn("Corrupt idempotency record, processing request without it")
+					return next(c)
+				}
+
+				switch {
+				case record.Fingerprint != fingerprint:
+					return http_util.HandleErrorResponse(c, http.StatusConflict, msg.IDEMPOTENCY_KEY_REUSED)
+				case !record.Completed:
+					return http_util.HandleErrorResponse(c, http.StatusConflict, msg.IDEMPOTENCY_REQUEST_IN_PROGRESS)
+				}
+
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return c.Blob(record.StatusCode, record.ContentType, record.Body)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			err = next(c)
+
+			// Respons gagal karena server tidak disimpan agar klien bisa mencoba lagi dengan key yang sama
+			status := c.Response().Status
+			if err != nil || status >= http.StatusInternalServerError {
+				if delErr := store.Del(storageKey); delErr != nil {
+					log.WithError(delErr).Warnf("Failed to release idempotency key %s", key)
+				}
+				return err
+			}
+
+			completed, _ := json.Marshal(idempotencyRecord{
+				Fingerprint: fingerprint,
+				Completed:   true,
+				StatusCode:  status,
+				ContentType: c.Response().Header().Get(echo.HeaderContentType),
+				Body:        recorder.body.Bytes(),
+			})
+			if err := store.Set(storageKey, string(completed), ttl); err != nil {
+				log.WithError(err).Warnf("Failed to store response for idempotency key %s", key)
+			}
+			return nil
+		}
+	}
+}
+
+// idempotencyCaller mengenali pemanggil dari akun yang login. Request tanpa login tidak
+// dibedakan dari alamat IP karena alamat IP bisa dipakai bersama (NAT, jaringan kantor)
+// atau berubah di antara percobaan ulang.
+func idempotencyCaller(c echo.Context) string {
+	if claims := token.OptionalClaims(c); claims != nil {
+		return "account-" + claims.ID.String()
+	}
+	return "guest"
+}
+
+// responseRecorder menyalin body respons agar dapat disimpan
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
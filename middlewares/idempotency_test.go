@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+)
+
+// memIdempotencyStore adalah IdempotencyStore in-memory tanpa masa berlaku
+type memIdempotencyStore struct {
+	records map[string]string
+}
+
+func (s *memIdempotencyStore) SetNX(key string, value string, expiration time.Duration) (bool, error) {
+	if _, exists := s.records[key]; exists {
+		return false, nil
+	}
+	s.records[key] = value
+	return true, nil
+}
+
+func (s *memIdempotencyStore) Get(key string) (string, error) {
+	value, exists := s.records[key]
+	if !exists {
+		return "", goredis.Nil
+	}
+	return value, nil
+}
+
+func (s *memIdempotencyStore) Set(key string, value string, expiration time.Duration) error {
+	s.records[key] = value
+	return nil
+}
+
+func (s *memIdempotencyStore) Del(key string) error {
+	delete(s.records, key)
+	return nil
+}
+
+// lostLockStore kehilangan penanda request di antara SetNX dan Get, seperti penanda
+// yang kadaluarsa tepat sebelum dibaca
+type lostLockStore struct {
+	memIdempotencyStore
+}
+
+func (s *lostLockStore) SetNX(key string, value string, expiration time.Duration) (bool, error) {
+	return false, nil
+}
+
+func newIdempotencyServer(store IdempotencyStore) (*echo.Echo, *int) {
+	calls := 0
+	e := echo.New()
+	e.POST("/donations", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusCreated, map[string]int{"call": calls})
+	}, Idempotency(store, time.Minute, time.Hour))
+	return e, &calls
+}
+
+func postDonation(e *echo.Echo, key string, body string, realIP string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/donations", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(HeaderIdempotencyKey, key)
+	req.Header.Set(echo.HeaderXRealIP, realIP)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyGuestRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		body       string
+		realIP     string
+		wantStatus int
+		wantReplay bool
+		wantCalls  int
+	}{
+		{"same key and body is replayed", "key-1", `{"amount":50000}`, "10.0.0.1", http.StatusCreated, true, 1},
+		{"same key and body from another address is replayed", "key-1", `{"amount":50000}`, "10.0.0.2", http.StatusCreated, true, 1},
+		{"same key with a different body is rejected", "key-1", `{"amount":75000}`, "10.0.0.1", http.StatusConflict, false, 1},
+		{"same key with a different body from another address is rejected", "key-1", `{"amount":75000}`, "10.0.0.2", http.StatusConflict, false, 1},
+		{"new key is processed", "key-2", `{"amount":75000}`, "10.0.0.1", http.StatusCreated, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, calls := newIdempotencyServer(&memIdempotencyStore{records: map[string]string{}})
+			if rec := postDonation(e, "key-1", `{"amount":50000}`, "10.0.0.1"); rec.Code != http.StatusCreated {
+				t.Fatalf("first request returned %d, want %d", rec.Code, http.StatusCreated)
+			}
+
+			rec := postDonation(e, tt.key, tt.body, tt.realIP)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("second request returned %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if replayed := rec.Header().Get(HeaderIdempotentReplayed) == "true"; replayed != tt.wantReplay {
+				t.Errorf("replayed %t, want %t", replayed, tt.wantReplay)
+			}
+			if *calls != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", *calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyLockLostBeforeRead(t *testing.T) {
+	e, calls := newIdempotencyServer(&lostLockStore{memIdempotencyStore{records: map[string]string{}}})
+
+	if rec := postDonation(e, "key-1", `{"amount":50000}`, "10.0.0.1"); rec.Code != http.StatusConflict {
+		t.Errorf("request returned %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if *calls != 0 {
+		t.Errorf("handler called %d times, want 0", *calls)
+	}
+}
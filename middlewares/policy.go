@@ -0,0 +1,152 @@
+package middlewares
+
+import (
+	"net/http"
+	msg "tugas-akhir/constant/messages"
+	"tugas-akhir/entities"
+	http_util "tugas-akhir/utils/http"
+	"tugas-akhir/utils/token"
+
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Jenis akses sebuah endpoint
+const (
+	AccessPublic     = "public"
+	AccessOptional   = "optional"
+	AccessLoggedIn   = "logged_in"
+	AccessDonor      = "donor"
+	AccessAdmin      = "admin"
+	AccessRole       = "role"
+	AccessPermission = "permission"
+)
+
+// Policy adalah aturan akses satu endpoint. JWTConfig kosong berarti memakai
+// konfigurasi JWT default.
+type Policy struct {
+	Access     string
+	Roles      []string
+	Permission string
+	JWTConfig  *echojwt.Config
+}
+
+// Public dapat diakses tanpa login
+func Public() Policy {
+	return Policy{Access: AccessPublic}
+}
+
+// Optional dapat diakses tanpa login, tetapi token yang dikirim tetap diperiksa
+// sehingga claims pengguna yang login tersedia di handler
+func Optional() Policy {
+	return Policy{Access: AccessOptional}
+}
+
+// Authenticated untuk pengguna yang login, baik donatur maupun admin
+func Authenticated() Policy {
+	return Policy{Access: AccessLoggedIn}
+}
+
+// Donor hanya untuk donatur yang login
+func Donor() Policy {
+	return Policy{Access: AccessDonor, Roles: []string{entities.RoleDonor}}
+}
+
+// Admin untuk admin yang login dengan role apa pun
+func Admin() Policy {
+	return Policy{Access: AccessAdmin}
+}
+
+// Role untuk pengguna yang login dengan salah satu role yang diberikan
+func Role(roles ...string) Policy {
+	return Policy{Access: AccessRole, Roles: roles}
+}
+
+// Permission untuk admin yang rolenya memiliki hak akses permission
+func Permission(permission string) Policy {
+	return Policy{Access: AccessPermission, Permission: permission}
+}
+
+// WithJWTConfig mengganti cara token dibaca, misalnya dari query untuk stream SSE
+func (p Policy) WithJWTConfig(config echojwt.Config) Policy {
+	p.JWTConfig = &config
+	return p
+}
+
+// middleware menyusun pemeriksaan token, sesi, role, dan hak akses untuk policy ini
+func (p Policy) middleware(checker PermissionChecker, sessions SessionChecker) []echo.MiddlewareFunc {
+	config := token.GetJWTConfig()
+	if p.Access == AccessOptional {
+		config = token.GetOptionalJWTConfig()
+	}
+	if p.JWTConfig != nil {
+		config = *p.JWTConfig
+	}
+
+	authenticate := []echo.MiddlewareFunc{echojwt.WithConfig(config), ActiveSession(sessions)}
+	switch p.Access {
+	case AccessPublic:
+		return nil
+	case AccessOptional, AccessLoggedIn:
+		return authenticate
+	case AccessAdmin:
+		return append(authenticate, IsAdmin)
+	case AccessPermission:
+		return append(authenticate, HasPermission(checker, p.Permission))
+	default:
+		return append(authenticate, HasAnyRole(p.Roles...))
+	}
+}
+
+// RouteKey adalah method dan path route seperti yang didaftarkan ke Echo, misalnya
+// GET /api/v1/activities/:id
+type RouteKey struct {
+	Method string
+	Path   string
+}
+
+// RoutePolicies memetakan setiap route ke policy aksesnya
+type RoutePolicies map[RouteKey]Policy
+
+// EnforcePolicies menerapkan policy route yang cocok dengan request. Dipasang dengan
+// e.Use sehingga berlaku untuk semua route tanpa bergantung pada urutan pendaftaran.
+// Route yang terdaftar tanpa policy selalu ditolak, sedangkan path atau method yang tidak
+// terdaftar diteruskan agar Echo tetap membalas 404 atau 405. Hak akses policy Permission
+// diperiksa lewat checker, sedangkan sesi dari setiap token diperiksa lewat sessions.
+func EnforcePolicies(policies RoutePolicies, checker PermissionChecker, sessions SessionChecker) echo.MiddlewareFunc {
+	chains := make(map[RouteKey][]echo.MiddlewareFunc, len(policies))
+	for key, policy := range policies {
+		chains[key] = policy.middleware(checker, sessions)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := RouteKey{Method: c.Request().Method, Path: c.Path()}
+			chain, ok := chains[key]
+			if !ok {
+				if !isRegistered(c.Echo(), key) {
+					return next(c)
+				}
+
+				logrus.New().Errorf("Route %s %s has no access policy", key.Method, key.Path)
+				return http_util.HandleErrorResponse(c, http.StatusForbidden, msg.FORBIDDEN_ACCESS)
+			}
+
+			handler := next
+			for i := len(chain) - 1; i >= 0; i-- {
+				handler = chain[i](handler)
+			}
+			return handler(c)
+		}
+	}
+}
+
+func isRegistered(e *echo.Echo, key RouteKey) bool {
+	for _, route := range e.Routes() {
+		if route.Method == key.Method && route.Path == key.Path {
+			return true
+		}
+	}
+	return false
+}
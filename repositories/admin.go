@@ -3,6 +3,8 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
+	dto_base "tugas-akhir/dto/base"
 	"tugas-akhir/entities"
 
 	"github.com/google/uuid"
@@ -15,6 +17,9 @@ type AdminRepository interface {
 	GetAdmin(ctx context.Context, admin *entities.Admin) (*entities.Admin, error)
 	UpdateAdmin(ctx context.Context, id uuid.UUID, admin *entities.Admin) error
 	DeleteAdmin(ctx context.Context, id uuid.UUID) error
+	GetAdminEmails(ctx context.Context) ([]string, error)
+	GetAdmins(ctx context.Context, req *dto_base.PaginationRequest) ([]entities.Admin, int64, error)
+	SetDeactivatedAt(ctx context.Context, id uuid.UUID, deactivatedAt *time.Time) error
 }
 
 type adminRepo struct {
@@ -31,54 +36,39 @@ func (ar *adminRepo) CreateAdmin(ctx context.Context, admin *entities.Admin) err
 	if err := ctx.Err(); err != nil {
 		return err
 	}
-	return ar.DB.Create(admin).Error
+	return dbFromContext(ctx, ar.DB).Create(admin).Error
 }
 
 func (ar *adminRepo) GetAdmin(ctx context.Context, admin *entities.Admin) (*entities.Admin, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	if err := ar.DB.Where(admin).First(admin).Error; err != nil {
+	if err := dbFromContext(ctx, ar.DB).Where(admin).First(admin).Error; err != nil {
 		return nil, err
 	}
 	return admin, nil
 }
 
-// func (ar *adminRepo) GetAdminAll(ctx context.Context, req *dto_base.PaginationRequest) ([]entities.Admin, int64, error) {
-// 	if ar == nil {
-// 		return nil, 0, errors.New("adminRepository is nil")
-// 	}
-
-// 	if req == nil {
-// 		return nil, 0, errors.New("PaginationRequest is nil")
-// 	}
-
-// 	if err := ctx.Err(); err != nil {
-// 		return nil, 0, err
-// 	}
-
-// 	var admins []entities.Admin
-// 	var totalData int64
-
-// 	offset := (req.Page - 1) * req.Limit
-
-// 	// Pastikan ar.DB tidak nil sebelum menggunakan
-// 	if ar.DB == nil {
-// 		return nil, 0, errors.New("DB connection is nil")
-// 	}
+// GetAdmins mengambil akun admin per halaman, diurutkan dari yang terbaru
+func (ar *adminRepo) GetAdmins(ctx context.Context, req *dto_base.PaginationRequest) ([]entities.Admin, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
 
-// 	// Menghitung total data
-// 	if err := ar.DB.WithContext(ctx).Model(&entities.Admin{}).Count(&totalData).Error; err != nil {
-// 		return nil, 0, err
-// 	}
+	query := ar.DB.WithContext(ctx).Model(&entities.Admin{})
 
-// 	query := ar.DB.WithContext(ctx).Model(&entities.Admin{}).Order(req.SortBy).Limit(req.Limit).Offset(offset)
-// 	if err := query.Find(&admins).Error; err != nil {
-// 		return nil, 0, err
-// 	}
+	var totalData int64
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
 
-// 	return admins, totalData, nil
-// }
+	var admins []entities.Admin
+	offset := (req.Page - 1) * req.Limit
+	if err := query.Order("created_at DESC").Order("name").Limit(req.Limit).Offset(offset).Find(&admins).Error; err != nil {
+		return nil, 0, err
+	}
+	return admins, totalData, nil
+}
 
 func (ar *adminRepo) GetAdminByID(ctx context.Context, id uuid.UUID) (*entities.Admin, error) {
 	if ar == nil {
@@ -90,7 +80,7 @@ func (ar *adminRepo) GetAdminByID(ctx context.Context, id uuid.UUID) (*entities.
 	}
 
 	var admin entities.Admin	
-	if err := ar.DB.WithContext(ctx).Model(&entities.Admin{}).Where("id = ?", id).First(&admin).Error; err != nil {
+	if err := dbFromContext(ctx, ar.DB).Model(&entities.Admin{}).Where("id = ?", id).First(&admin).Error; err != nil {
 		return nil, err
 	}
 
@@ -101,12 +91,34 @@ func (ar *adminRepo) UpdateAdmin(ctx context.Context, id uuid.UUID, admin *entit
 	if err := ctx.Err(); err != nil {
 		return err
 	}
-	return ar.DB.Model(&entities.Admin{}).Where("id = ?", id).Updates(admin).Error
+	return dbFromContext(ctx, ar.DB).Model(&entities.Admin{}).Where("id = ?", id).Updates(admin).Error
+}
+
+// SetDeactivatedAt menonaktifkan admin, atau mengaktifkannya kembali jika deactivatedAt nil
+func (ar *adminRepo) SetDeactivatedAt(ctx context.Context, id uuid.UUID, deactivatedAt *time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, ar.DB).Model(&entities.Admin{}).Where("id = ?", id).
+		Updates(map[string]any{"deactivated_at": deactivatedAt, "updated_at": time.Now()}).Error
 }
 
 func (ar *adminRepo) DeleteAdmin(ctx context.Context, id uuid.UUID) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
-	return ar.DB.Model(&entities.Admin{}).Where("id = ?", id).Delete(&entities.Admin{}).Error
-}
\ No newline at end of file
+	return dbFromContext(ctx, ar.DB).Model(&entities.Admin{}).Where("id = ?", id).Delete(&entities.Admin{}).Error
+}
+
+// GetAdminEmails mengambil alamat email akun admin yang aktif, dipakai untuk email pemberitahuan
+func (ar *adminRepo) GetAdminEmails(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var emails []string
+	if err := ar.DB.WithContext(ctx).Model(&entities.Admin{}).Where("email <> '' AND deactivated_at IS NULL").Pluck("email", &emails).Error; err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tugas-akhir/entities"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AdminInvitationRepository interface {
+	CreateInvitation(ctx context.Context, invitation *entities.AdminInvitation) error
+	FindActiveByTokenHash(ctx context.Context, tokenHash string) (*entities.AdminInvitation, error)
+	Update(ctx context.Context, invitation *entities.AdminInvitation) error
+}
+
+type adminInvitationRepo struct {
+	DB *gorm.DB
+}
+
+func NewAdminInvitationRepository(db *gorm.DB) AdminInvitationRepository {
+	return &adminInvitationRepo{
+		DB: db,
+	}
+}
+
+// CreateInvitation menyimpan undangan baru dan mengakhiri undangan lama untuk email yang
+// sama, sehingga hanya undangan terakhir yang berlaku
+func (ir *adminInvitationRepo) CreateInvitation(ctx context.Context, invitation *entities.AdminInvitation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db := dbFromContext(ctx, ir.DB)
+	if err := db.Model(&entities.AdminInvitation{}).
+		Where("email = ? AND accepted_at IS NULL AND expires_at > ?", invitation.Email, time.Now()).
+		Update("expires_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return db.Create(invitation).Error
+}
+
+func (ir *adminInvitationRepo) FindActiveByTokenHash(ctx context.Context, tokenHash string) (*entities.AdminInvitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var invitation entities.AdminInvitation
+	err := dbFromContext(ctx, ir.DB).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (ir *adminInvitationRepo) Update(ctx context.Context, invitation *entities.AdminInvitation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, ir.DB).Save(invitation).Error
+}
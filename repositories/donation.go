@@ -3,25 +3,32 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 	dto_base "tugas-akhir/dto/base"
 	"tugas-akhir/entities"
+	"tugas-akhir/utils/phone"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DonationRepository interface {
 	CreateDonation(ctx context.Context, donation *entities.Donation) error
 	Update(ctx context.Context, donation *entities.Donation) error
 	FindById(ctx context.Context, id uuid.UUID) (*entities.Donation, error)
-	GetDonations(ctx context.Context, programDonationID uuid.UUID, searchName string, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error)
+	FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.Donation, error)
+	GetDonations(ctx context.Context, programDonationID uuid.UUID, searchName string, includeExpired bool, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error)
 	GetDonation(ctx context.Context) (*[]entities.Donation, error)
 	GetDonationsLanding(ctx context.Context) (*[]entities.Donation, error)
 	GetDonationByID(ctx context.Context, donationID uuid.UUID) (*entities.Donation, error)
-	GetNotifikasi(ctx context.Context) (*[]entities.TransactionNotification, error)
 	GetNotifikasiByDonationID(ctx context.Context, donationID uuid.UUID) (*entities.TransactionNotification, error)
 	GetDonationByProgramID(ctx context.Context, programID uuid.UUID) (*[]entities.Donation, error)
+	GetUnsettledDonations(ctx context.Context, createdBefore time.Time, after *time.Time, afterID uuid.UUID, limit int) ([]entities.Donation, error)
+	GetPendingDonationsBefore(ctx context.Context, createdBefore time.Time, now time.Time, after *time.Time, afterID uuid.UUID, limit int) ([]entities.Donation, error)
+	GetDonationsByUserID(ctx context.Context, userID uuid.UUID, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error)
+	LinkGuestDonations(ctx context.Context, userID uuid.UUID, email string, noWA phone.Number) (int64, error)
 }
 
 type donationRepo struct {
@@ -43,7 +50,7 @@ func (dr *donationRepo) CreateDonation(ctx context.Context, donation *entities.D
 
 	log.Infof("Inserting donation into DB: %+v", donation)
 	// Use GORM's WithContext to set the context for the transaction
-	if err := dr.DB.WithContext(ctx).Create(donation).Error; err != nil {
+	if err := dbFromContext(ctx, dr.DB).Create(donation).Error; err != nil {
 		log.WithError(err).Error("Failed to insert donation into DB")
 		return err
 	}
@@ -53,7 +60,20 @@ func (dr *donationRepo) CreateDonation(ctx context.Context, donation *entities.D
 // FindById retrieves a donation by its ID
 func (dr *donationRepo) FindById(ctx context.Context, id uuid.UUID) (*entities.Donation, error) {
 	var donation entities.Donation
-	if err := dr.DB.WithContext(ctx).First(&donation, "id = ?", id).Error; err != nil {
+	if err := dbFromContext(ctx, dr.DB).First(&donation, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("donation not found")
+		}
+		return nil, err
+	}
+	return &donation, nil
+}
+
+// FindByIdForUpdate mengambil donasi dan mengunci barisnya sampai transaksi selesai,
+// sehingga notifikasi yang datang bersamaan diproses satu per satu
+func (dr *donationRepo) FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.Donation, error) {
+	var donation entities.Donation
+	if err := dbFromContext(ctx, dr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).First(&donation, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("donation not found")
 		}
@@ -65,13 +85,13 @@ func (dr *donationRepo) FindById(ctx context.Context, id uuid.UUID) (*entities.D
 // Update updates the donation record in the database (e.g., updating the donation status)
 func (dr *donationRepo) Update(ctx context.Context, donation *entities.Donation) error {
 	// Update the donation record based on the donation ID
-	if err := dr.DB.WithContext(ctx).Save(donation).Error; err != nil {
+	if err := dbFromContext(ctx, dr.DB).Save(donation).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (dr *donationRepo) GetDonations(ctx context.Context, programDonationID uuid.UUID, searchName string, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error) {
+func (dr *donationRepo) GetDonations(ctx context.Context, programDonationID uuid.UUID, searchName string, includeExpired bool, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, 0, err
 	}
@@ -93,6 +113,11 @@ func (dr *donationRepo) GetDonations(ctx context.Context, programDonationID uuid
 		query = query.Where("name LIKE ?", "%"+searchName+"%")
 	}
 
+	// Donasi yang kadaluarsa disembunyikan kecuali diminta
+	if !includeExpired {
+		query = query.Where("status <> ?", entities.DonationStatusExpired)
+	}
+
 	// Menghitung total data
 	if err := query.Count(&totalData).Error; err != nil {
 		return nil, 0, err
@@ -115,8 +140,8 @@ func (dr *donationRepo) GetDonationByID(ctx context.Context, donationID uuid.UUI
 
 	var donation entities.Donation
 
-	// Query untuk mendapatkan donasi berdasarkan ID
-	if err := dr.DB.WithContext(ctx).Where("id = ?", donationID).First(&donation).Error; err != nil {
+	// Query untuk mendapatkan donasi berdasarkan ID, ikut transaksi yang sedang berjalan jika ada
+	if err := dbFromContext(ctx, dr.DB).Where("id = ?", donationID).First(&donation).Error; err != nil {
 		return nil, err
 	}
 
@@ -131,34 +156,22 @@ func (dr *donationRepo) GetDonationsLanding(ctx context.Context) (*[]entities.Do
 
 	var donations []entities.Donation
 
-	// Query untuk mendapatkan donasi dengan status 1
-	if err := dr.DB.WithContext(ctx).Where("status = ?", 1).Find(&donations).Error; err != nil {
+	// Query untuk mendapatkan donasi yang sudah dibayar
+	if err := dr.DB.WithContext(ctx).Where("status IN ?", entities.DonationCollectedStatuses).Find(&donations).Error; err != nil {
 		return nil, err
 	}
 
 	return &donations, nil
 }
 
-func (dr *donationRepo) GetNotifikasi(ctx context.Context) (*[]entities.TransactionNotification, error) {
-	// Memeriksa konteks
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
-
-	var notifications []entities.TransactionNotification
-
-	// Query untuk mendapatkan donasi dengan status 1
-	if err := dr.DB.WithContext(ctx).Find(&notifications).Error; err != nil {
-		return nil, err
-	}
-
-	return &notifications, nil
-}
-
 // GetDonationByProgramID mengambil donasi berdasarkan ProgramDonationID
 func (dr *donationRepo) GetDonationByProgramID(ctx context.Context, programID uuid.UUID) (*[]entities.Donation, error) {
 	var donations []entities.Donation
-	if err := dr.DB.WithContext(ctx).Where("program_donation_id = ?", programID).Find(&donations).Error; err != nil {
+	// Termasuk donasi multi program yang salah satu alokasinya untuk program ini
+	if err := dr.DB.WithContext(ctx).
+		Where("program_donation_id = ? OR id IN (?)", programID,
+			dr.DB.Model(&entities.DonationAllocation{}).Select("donation_id").Where("program_donation_id = ?", programID)).
+		Find(&donations).Error; err != nil {
 		return nil, err
 	}
 	return &donations, nil
@@ -175,7 +188,8 @@ func (dr *donationRepo) GetDonation(ctx context.Context) (*[]entities.Donation,
 func (dr *donationRepo) GetNotifikasiByDonationID(ctx context.Context, donationID uuid.UUID) (*entities.TransactionNotification, error) {
 	var notification entities.TransactionNotification
 	if err := dr.DB.WithContext(ctx).
-		Where("order_id = ? AND transaction_status = ?", donationID, "settlement").
+		// order_id dapat berakhiran nomor percobaan jika pembayaran dilanjutkan
+		Where("(order_id = ? OR order_id LIKE ?) AND transaction_status = ?", donationID.String(), donationID.String()+"-%", "settlement").
 		First(&notification).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // atau custom handling
@@ -185,3 +199,94 @@ func (dr *donationRepo) GetNotifikasiByDonationID(ctx context.Context, donationI
 
 	return &notification, nil
 }
+
+// GetUnsettledDonations mengambil donasi pending atau challenged yang dibuat sebelum createdBefore,
+// dimulai dari yang paling lama. Jika after diisi, hanya donasi setelah cursor (after, afterID)
+// sehingga donasi yang statusnya belum berubah tidak menghalangi halaman berikutnya.
+func (dr *donationRepo) GetUnsettledDonations(ctx context.Context, createdBefore time.Time, after *time.Time, afterID uuid.UUID, limit int) ([]entities.Donation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := dr.DB.WithContext(ctx).
+		Where("status IN ? AND created_at < ?", []entities.DonationStatus{entities.DonationStatusPending, entities.DonationStatusChallenged}, createdBefore)
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", *after, afterID)
+	}
+
+	var donations []entities.Donation
+	if err := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&donations).Error; err != nil {
+		return nil, err
+	}
+	return donations, nil
+}
+
+// GetPendingDonationsBefore mengambil donasi pending yang dibuat sebelum createdBefore dan
+// sudah boleh dikedaluwarsakan: link pembayarannya tidak berlaku lagi pada now dan tidak
+// ada bukti transfer yang menunggu verifikasi. Cursor (after, afterID) sama seperti
+// GetUnsettledDonations.
+func (dr *donationRepo) GetPendingDonationsBefore(ctx context.Context, createdBefore time.Time, now time.Time, after *time.Time, afterID uuid.UUID, limit int) ([]entities.Donation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := dr.DB.WithContext(ctx).
+		Where("status = ? AND created_at < ?", entities.DonationStatusPending, createdBefore).
+		Where("snap_expires_at IS NULL OR snap_expires_at <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM transfer_proofs WHERE transfer_proofs.donation_id = donations.id AND transfer_proofs.status = ?)", entities.TransferProofPending)
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", *after, afterID)
+	}
+
+	var donations []entities.Donation
+	if err := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&donations).Error; err != nil {
+		return nil, err
+	}
+	return donations, nil
+}
+
+// GetDonationsByUserID mengambil riwayat donasi milik akun donatur, terbaru lebih dulu
+func (dr *donationRepo) GetDonationsByUserID(ctx context.Context, userID uuid.UUID, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		donations []entities.Donation
+		totalData int64
+	)
+
+	query := dr.DB.WithContext(ctx).Model(&entities.Donation{}).Where("user_id = ?", userID)
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	if err := query.Order("created_at DESC").Limit(req.Limit).Offset(offset).Find(&donations).Error; err != nil {
+		return nil, 0, err
+	}
+	return donations, totalData, nil
+}
+
+// LinkGuestDonations menautkan donasi tamu ke akun donatur berdasarkan email dan/atau
+// nomor WhatsApp yang sudah terverifikasi. Nilai kosong berarti kanal itu tidak dipakai.
+func (dr *donationRepo) LinkGuestDonations(ctx context.Context, userID uuid.UUID, email string, noWA phone.Number) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if email == "" && noWA.IsZero() {
+		return 0, nil
+	}
+
+	result := dbFromContext(ctx, dr.DB).Model(&entities.Donation{}).
+		Where("user_id IS NULL").
+		Where("(? <> '' AND LOWER(email) = LOWER(?)) OR (? <> '' AND no_wa = ?)", email, email, noWA, noWA).
+		Update("user_id", userID)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DonationAllocationRepository interface {
+	CreateAllocations(ctx context.Context, allocations []entities.DonationAllocation) error
+	GetAllocationsByDonationID(ctx context.Context, donationID uuid.UUID) ([]entities.DonationAllocation, error)
+}
+
+type donationAllocationRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonationAllocationRepository(db *gorm.DB) DonationAllocationRepository {
+	return &donationAllocationRepo{
+		DB: db,
+	}
+}
+
+func (ar *donationAllocationRepo) CreateAllocations(ctx context.Context, allocations []entities.DonationAllocation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(allocations) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, ar.DB).Create(&allocations).Error
+}
+
+func (ar *donationAllocationRepo) GetAllocationsByDonationID(ctx context.Context, donationID uuid.UUID) ([]entities.DonationAllocation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var allocations []entities.DonationAllocation
+	if err := dbFromContext(ctx, ar.DB).Where("donation_id = ?", donationID).Order("created_at ASC, id ASC").Find(&allocations).Error; err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
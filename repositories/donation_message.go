@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	dto_base "tugas-akhir/dto/base"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DonationMessageRepository interface {
+	GetPendingMessages(ctx context.Context, limit int) ([]entities.Donation, error)
+	UpdateModeration(ctx context.Context, donation *entities.Donation) error
+	GetMessages(ctx context.Context, status string, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error)
+	GetPrayers(ctx context.Context, programID *uuid.UUID, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error)
+}
+
+type donationMessageRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonationMessageRepository(db *gorm.DB) DonationMessageRepository {
+	return &donationMessageRepo{
+		DB: db,
+	}
+}
+
+// GetPendingMessages mengambil donasi yang pesannya belum diperiksa filter, terlama lebih dulu
+func (mr *donationMessageRepo) GetPendingMessages(ctx context.Context, limit int) ([]entities.Donation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var donations []entities.Donation
+	if err := mr.DB.WithContext(ctx).
+		Where("message_status = ?", entities.MessagePending).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&donations).Error; err != nil {
+		return nil, err
+	}
+	return donations, nil
+}
+
+// UpdateModeration hanya menyimpan kolom moderasi agar tidak menimpa perubahan status
+// pembayaran yang terjadi bersamaan
+func (mr *donationMessageRepo) UpdateModeration(ctx context.Context, donation *entities.Donation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, mr.DB).Model(&entities.Donation{}).
+		Where("id = ?", donation.ID).
+		Updates(map[string]interface{}{
+			"message_status":      donation.MessageStatus,
+			"public_message":      donation.PublicMessage,
+			"message_flag":        donation.MessageFlag,
+			"message_reviewed_by": donation.MessageReviewedBy,
+			"message_reviewed_at": donation.MessageReviewedAt,
+		}).Error
+}
+
+// GetMessages mengambil antrean moderasi: donasi berpesan dengan status tertentu,
+// terbaru lebih dulu
+func (mr *donationMessageRepo) GetMessages(ctx context.Context, status string, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	query := mr.DB.WithContext(ctx).Model(&entities.Donation{}).
+		Where("message <> ''").
+		Where("message_status = ?", status)
+
+	var totalData int64
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var donations []entities.Donation
+	offset := (req.Page - 1) * req.Limit
+	if err := query.Order("created_at DESC").Limit(req.Limit).Offset(offset).Find(&donations).Error; err != nil {
+		return nil, 0, err
+	}
+	return donations, totalData, nil
+}
+
+// GetPrayers mengambil pesan dan doa yang sudah disetujui dari donasi yang sudah dibayar,
+// terbaru lebih dulu. Jika programID diisi, hanya donasi untuk program tersebut.
+func (mr *donationMessageRepo) GetPrayers(ctx context.Context, programID *uuid.UUID, req *dto_base.PaginationRequest) ([]entities.Donation, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	query := mr.DB.WithContext(ctx).Model(&entities.Donation{}).
+		Where("status IN ?", entities.DonationCollectedStatuses).
+		Where("message_status = ? AND public_message <> ''", entities.MessageApproved)
+	if programID != nil {
+		query = query.Where("program_donation_id = ? OR id IN (?)", *programID,
+			mr.DB.Model(&entities.DonationAllocation{}).Select("donation_id").Where("program_donation_id = ?", *programID))
+	}
+
+	var totalData int64
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var donations []entities.Donation
+	offset := (req.Page - 1) * req.Limit
+	if err := query.Order("created_at DESC").Limit(req.Limit).Offset(offset).Find(&donations).Error; err != nil {
+		return nil, 0, err
+	}
+	return donations, totalData, nil
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DonationNotificationRepository interface {
+	GetNotifications(ctx context.Context, adminID uuid.UUID, before *time.Time, beforeID uuid.UUID, limit int) ([]entities.DonationNotificationSummary, error)
+	GetAllocations(ctx context.Context, donationIDs []uuid.UUID) ([]entities.NotificationAllocation, error)
+	CountUnread(ctx context.Context, adminID uuid.UUID) (int64, error)
+	MarkRead(ctx context.Context, adminID uuid.UUID, notificationID uuid.UUID) error
+	MarkAllRead(ctx context.Context, adminID uuid.UUID) (int64, error)
+}
+
+type donationNotificationRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonationNotificationRepository(db *gorm.DB) DonationNotificationRepository {
+	return &donationNotificationRepo{
+		DB: db,
+	}
+}
+
+// settledNotifications adalah notifikasi pelunasan yang donasinya masih ada. Order id
+// dapat berakhiran nomor percobaan, sehingga yang dicocokkan hanya 36 karakter UUID-nya.
+func (nr *donationNotificationRepo) settledNotifications(ctx context.Context) *gorm.DB {
+	return dbFromContext(ctx, nr.DB).
+		Table("transaction_notifications").
+		Joins("JOIN donations ON donations.id::text = LEFT(transaction_notifications.order_id, 36) AND donations.deleted_at IS NULL").
+		Where("transaction_notifications.transaction_status = ? AND transaction_notifications.deleted_at IS NULL", "settlement")
+}
+
+// GetNotifications mengambil notifikasi terbaru lebih dulu dalam satu query. Jika before
+// diisi, hanya notifikasi yang lebih lama dari cursor (before, beforeID).
+func (nr *donationNotificationRepo) GetNotifications(ctx context.Context, adminID uuid.UUID, before *time.Time, beforeID uuid.UUID, limit int) ([]entities.DonationNotificationSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := nr.settledNotifications(ctx).
+		Select(`transaction_notifications.id,
+			transaction_notifications.gross_amount,
+			transaction_notifications.transaction_status,
+			transaction_notifications.created_at,
+			donations.id AS donation_id,
+			donations.name,
+			donations.message,
+			donations.amount,
+			donations.program_donation_id,
+			COALESCE(program_donations.title, '') AS program_title,
+			notification_reads.read_at`).
+		Joins("LEFT JOIN program_donations ON program_donations.id = donations.program_donation_id").
+		Joins("LEFT JOIN notification_reads ON notification_reads.notification_id = transaction_notifications.id AND notification_reads.admin_id = ?", adminID)
+	if before != nil {
+		query = query.Where("(transaction_notifications.created_at, transaction_notifications.id) < (?, ?)", *before, beforeID)
+	}
+
+	var notifications []entities.DonationNotificationSummary
+	if err := query.
+		Order("transaction_notifications.created_at DESC, transaction_notifications.id DESC").
+		Limit(limit).
+		Scan(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// GetAllocations mengambil alokasi program untuk beberapa donasi sekaligus
+func (nr *donationNotificationRepo) GetAllocations(ctx context.Context, donationIDs []uuid.UUID) ([]entities.NotificationAllocation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(donationIDs) == 0 {
+		return nil, nil
+	}
+
+	var allocations []entities.NotificationAllocation
+	if err := dbFromContext(ctx, nr.DB).
+		Table("donation_allocations").
+		Select("donation_allocations.donation_id, donation_allocations.program_donation_id, COALESCE(program_donations.title, '') AS program_title, donation_allocations.amount").
+		Joins("LEFT JOIN program_donations ON program_donations.id = donation_allocations.program_donation_id").
+		Where("donation_allocations.donation_id IN ?", donationIDs).
+		Order("donation_allocations.created_at ASC").
+		Scan(&allocations).Error; err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+func (nr *donationNotificationRepo) CountUnread(ctx context.Context, adminID uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	if err := nr.settledNotifications(ctx).
+		Joins("LEFT JOIN notification_reads ON notification_reads.notification_id = transaction_notifications.id AND notification_reads.admin_id = ?", adminID).
+		Where("notification_reads.notification_id IS NULL").
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// MarkRead menandai satu notifikasi sudah dibaca admin. Notifikasi yang tidak ada
+// mengembalikan gorm.ErrRecordNotFound, sedangkan yang sudah dibaca dibiarkan.
+func (nr *donationNotificationRepo) MarkRead(ctx context.Context, adminID uuid.UUID, notificationID uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var total int64
+	if err := nr.settledNotifications(ctx).Where("transaction_notifications.id = ?", notificationID).Count(&total).Error; err != nil {
+		return err
+	}
+	if total == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return dbFromContext(ctx, nr.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.NotificationRead{
+		NotificationID: notificationID,
+		AdminID:        adminID,
+		ReadAt:         time.Now(),
+	}).Error
+}
+
+// MarkAllRead menandai semua notifikasi yang belum dibaca admin dan mengembalikan jumlahnya
+func (nr *donationNotificationRepo) MarkAllRead(ctx context.Context, adminID uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	unread := nr.settledNotifications(ctx).Select("transaction_notifications.id, ?::uuid, ?::timestamptz", adminID, time.Now())
+	result := dbFromContext(ctx, nr.DB).
+		Exec("INSERT INTO notification_reads (notification_id, admin_id, read_at) ? ON CONFLICT DO NOTHING", unread)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
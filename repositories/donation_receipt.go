@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DonationReceiptRepository interface {
+	NextSequence(ctx context.Context, year int) (int, error)
+	CreateReceipt(ctx context.Context, receipt *entities.DonationReceipt) error
+	GetReceiptByDonationID(ctx context.Context, donationID uuid.UUID) (*entities.DonationReceipt, error)
+	GetReceiptByNumber(ctx context.Context, number string) (*entities.DonationReceipt, error)
+}
+
+type donationReceiptRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonationReceiptRepository(db *gorm.DB) DonationReceiptRepository {
+	return &donationReceiptRepo{
+		DB: db,
+	}
+}
+
+// NextSequence menaikkan nomor kuitansi tahun tersebut. Baris urutan tetap terkunci sampai
+// transaksi selesai, sehingga penerbitan kuitansi bersamaan menunggu gilirannya.
+func (rr *donationReceiptRepo) NextSequence(ctx context.Context, year int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var sequence int
+	err := dbFromContext(ctx, rr.DB).Raw(
+		`INSERT INTO receipt_sequences (year, last_number) VALUES (?, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = receipt_sequences.last_number + 1
+		RETURNING last_number`, year).Scan(&sequence).Error
+	if err != nil {
+		return 0, err
+	}
+	return sequence, nil
+}
+
+func (rr *donationReceiptRepo) CreateReceipt(ctx context.Context, receipt *entities.DonationReceipt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, rr.DB).Create(receipt).Error
+}
+
+// GetReceiptByDonationID mengembalikan nil tanpa error jika donasi belum punya kuitansi
+func (rr *donationReceiptRepo) GetReceiptByDonationID(ctx context.Context, donationID uuid.UUID) (*entities.DonationReceipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var receipt entities.DonationReceipt
+	err := dbFromContext(ctx, rr.DB).First(&receipt, "donation_id = ?", donationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (rr *donationReceiptRepo) GetReceiptByNumber(ctx context.Context, number string) (*entities.DonationReceipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var receipt entities.DonationReceipt
+	if err := dbFromContext(ctx, rr.DB).First(&receipt, "number = ?", number).Error; err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
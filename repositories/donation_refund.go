@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DonationRefundRepository interface {
+	CreateRefund(ctx context.Context, refund *entities.DonationRefund) error
+	FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonationRefund, error)
+	Update(ctx context.Context, refund *entities.DonationRefund) error
+	GetRefundsByDonationID(ctx context.Context, donationID uuid.UUID) ([]entities.DonationRefund, error)
+	GetPendingAmount(ctx context.Context, donationID uuid.UUID) (int, error)
+	GetPendingRefundsBefore(ctx context.Context, createdBefore time.Time, limit int) ([]entities.DonationRefund, error)
+}
+
+type donationRefundRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonationRefundRepository(db *gorm.DB) DonationRefundRepository {
+	return &donationRefundRepo{
+		DB: db,
+	}
+}
+
+func (rr *donationRefundRepo) CreateRefund(ctx context.Context, refund *entities.DonationRefund) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, rr.DB).Create(refund).Error
+}
+
+// FindByIdForUpdate mengunci refund agar penyelesaiannya tidak berjalan dua kali
+func (rr *donationRefundRepo) FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonationRefund, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var refund entities.DonationRefund
+	if err := dbFromContext(ctx, rr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).First(&refund, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (rr *donationRefundRepo) Update(ctx context.Context, refund *entities.DonationRefund) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, rr.DB).Save(refund).Error
+}
+
+func (rr *donationRefundRepo) GetRefundsByDonationID(ctx context.Context, donationID uuid.UUID) ([]entities.DonationRefund, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var refunds []entities.DonationRefund
+	if err := rr.DB.WithContext(ctx).Where("donation_id = ?", donationID).Order("created_at ASC").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// GetPendingAmount menjumlahkan refund donasi yang belum selesai. Nominal ini sudah
+// dipesan sehingga tidak boleh di-refund lagi.
+func (rr *donationRefundRepo) GetPendingAmount(ctx context.Context, donationID uuid.UUID) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var amount int
+	if err := dbFromContext(ctx, rr.DB).Model(&entities.DonationRefund{}).
+		Where("donation_id = ? AND status = ?", donationID, entities.RefundStatusPending).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&amount).Error; err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+// GetPendingRefundsBefore mengambil refund pending yang dibuat sebelum createdBefore
+func (rr *donationRefundRepo) GetPendingRefundsBefore(ctx context.Context, createdBefore time.Time, limit int) ([]entities.DonationRefund, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var refunds []entities.DonationRefund
+	if err := rr.DB.WithContext(ctx).
+		Where("status = ? AND created_at < ?", entities.RefundStatusPending, createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
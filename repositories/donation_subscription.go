@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tugas-akhir/entities"
+	"tugas-akhir/utils/phone"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DonationSubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, subscription *entities.DonationSubscription) error
+	FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonationSubscription, error)
+	Update(ctx context.Context, subscription *entities.DonationSubscription) error
+	GetSubscriptions(ctx context.Context, status string) ([]entities.DonationSubscription, error)
+	GetDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]entities.DonationSubscription, error)
+	GetOpenCycles(ctx context.Context, limit int) ([]entities.DonationSubscription, error)
+	GetSubscriptionsByUserID(ctx context.Context, userID uuid.UUID) ([]entities.DonationSubscription, error)
+	LinkGuestSubscriptions(ctx context.Context, userID uuid.UUID, email string, noWA phone.Number) (int64, error)
+}
+
+type donationSubscriptionRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonationSubscriptionRepository(db *gorm.DB) DonationSubscriptionRepository {
+	return &donationSubscriptionRepo{
+		DB: db,
+	}
+}
+
+func (sr *donationSubscriptionRepo) CreateSubscription(ctx context.Context, subscription *entities.DonationSubscription) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, sr.DB).Create(subscription).Error
+}
+
+// FindByIdForUpdate mengunci donasi rutin agar scheduler dan permintaan donatur tidak
+// mengubahnya bersamaan
+func (sr *donationSubscriptionRepo) FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonationSubscription, error) {
+	var subscription entities.DonationSubscription
+	if err := dbFromContext(ctx, sr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (sr *donationSubscriptionRepo) Update(ctx context.Context, subscription *entities.DonationSubscription) error {
+	return dbFromContext(ctx, sr.DB).Save(subscription).Error
+}
+
+// GetSubscriptions mengambil donasi rutin dengan status tertentu, atau semua jika status kosong
+func (sr *donationSubscriptionRepo) GetSubscriptions(ctx context.Context, status string) ([]entities.DonationSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := sr.DB.WithContext(ctx).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var subscriptions []entities.DonationSubscription
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetDueSubscriptions mengambil donasi rutin aktif tanpa siklus berjalan yang jadwal
+// penagihan atau penagihan ulangnya sudah lewat
+func (sr *donationSubscriptionRepo) GetDueSubscriptions(ctx context.Context, now time.Time, limit int) ([]entities.DonationSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []entities.DonationSubscription
+	if err := sr.DB.WithContext(ctx).
+		Where("status = ? AND current_donation_id IS NULL", entities.SubscriptionActive).
+		Where("(retry_at IS NOT NULL AND retry_at <= ?) OR (retry_at IS NULL AND next_charge_at <= ?)", now, now).
+		Order("next_charge_at ASC").
+		Limit(limit).
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// GetOpenCycles mengambil donasi rutin yang siklusnya masih menunggu hasil pembayaran
+func (sr *donationSubscriptionRepo) GetOpenCycles(ctx context.Context, limit int) ([]entities.DonationSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []entities.DonationSubscription
+	if err := sr.DB.WithContext(ctx).
+		Where("current_donation_id IS NOT NULL").
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (sr *donationSubscriptionRepo) GetSubscriptionsByUserID(ctx context.Context, userID uuid.UUID) ([]entities.DonationSubscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []entities.DonationSubscription
+	if err := sr.DB.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// LinkGuestSubscriptions menautkan donasi rutin tamu ke akun donatur, sama seperti
+// DonationRepository.LinkGuestDonations
+func (sr *donationSubscriptionRepo) LinkGuestSubscriptions(ctx context.Context, userID uuid.UUID, email string, noWA phone.Number) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if email == "" && noWA.IsZero() {
+		return 0, nil
+	}
+
+	result := dbFromContext(ctx, sr.DB).Model(&entities.DonationSubscription{}).
+		Where("user_id IS NULL").
+		Where("(? <> '' AND LOWER(email) = LOWER(?)) OR (? <> '' AND no_wa = ?)", email, email, noWA, noWA).
+		Update("user_id", userID)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,311 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+	dto_base "tugas-akhir/dto/base"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DonorProfileRepository interface {
+	GetUnassignedDonations(ctx context.Context, after *time.Time, afterID uuid.UUID, limit int) ([]entities.Donation, error)
+	AssignDonation(ctx context.Context, donationID uuid.UUID, profileID uuid.UUID) error
+
+	CreateProfile(ctx context.Context, profile *entities.DonorProfile) error
+	UpdateProfile(ctx context.Context, profile *entities.DonorProfile) error
+	FindProfileForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonorProfile, error)
+	FindProfileByIdentifier(ctx context.Context, kind string, value string) (*entities.DonorProfile, error)
+	GetIdentifiers(ctx context.Context, profileID uuid.UUID) ([]entities.DonorIdentifier, error)
+	AddIdentifier(ctx context.Context, identifier *entities.DonorIdentifier) error
+	GetProfilesByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.DonorProfileSummary, error)
+	GetProfiles(ctx context.Context, search string, req *dto_base.PaginationRequest) ([]entities.DonorProfileSummary, int64, error)
+	GetAllProfiles(ctx context.Context) ([]entities.DonorProfileSummary, error)
+	GetActiveProfilesByName(ctx context.Context, nameKey string) ([]entities.DonorProfile, error)
+	MergeProfile(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) error
+
+	CreateDuplicate(ctx context.Context, duplicate *entities.DonorDuplicate) error
+	GetDuplicates(ctx context.Context, status string) ([]entities.DonorDuplicate, error)
+	FindDuplicateForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonorDuplicate, error)
+	UpdateDuplicate(ctx context.Context, duplicate *entities.DonorDuplicate) error
+}
+
+type donorProfileRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonorProfileRepository(db *gorm.DB) DonorProfileRepository {
+	return &donorProfileRepo{
+		DB: db,
+	}
+}
+
+// GetUnassignedDonations mengambil donasi yang belum masuk profil donatur, terlama lebih
+// dulu. Jika after diisi, hanya donasi setelah cursor (after, afterID) sehingga donasi
+// yang terus gagal dikelompokkan tidak menghalangi halaman berikutnya.
+func (pr *donorProfileRepo) GetUnassignedDonations(ctx context.Context, after *time.Time, afterID uuid.UUID, limit int) ([]entities.Donation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := pr.DB.WithContext(ctx).Where("donor_profile_id IS NULL")
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", *after, afterID)
+	}
+
+	var donations []entities.Donation
+	if err := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&donations).Error; err != nil {
+		return nil, err
+	}
+	return donations, nil
+}
+
+func (pr *donorProfileRepo) AssignDonation(ctx context.Context, donationID uuid.UUID, profileID uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, pr.DB).Model(&entities.Donation{}).
+		Where("id = ?", donationID).
+		UpdateColumn("donor_profile_id", profileID).Error
+}
+
+func (pr *donorProfileRepo) CreateProfile(ctx context.Context, profile *entities.DonorProfile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, pr.DB).Create(profile).Error
+}
+
+func (pr *donorProfileRepo) UpdateProfile(ctx context.Context, profile *entities.DonorProfile) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, pr.DB).Save(profile).Error
+}
+
+func (pr *donorProfileRepo) FindProfileForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonorProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var profile entities.DonorProfile
+	if err := dbFromContext(ctx, pr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).First(&profile, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// FindProfileByIdentifier mengembalikan nil tanpa error jika identitas belum dimiliki profil mana pun
+func (pr *donorProfileRepo) FindProfileByIdentifier(ctx context.Context, kind string, value string) (*entities.DonorProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var profile entities.DonorProfile
+	err := dbFromContext(ctx, pr.DB).
+		Joins("JOIN donor_identifiers ON donor_identifiers.profile_id = donor_profiles.id").
+		Where("donor_identifiers.kind = ? AND donor_identifiers.value = ?", kind, value).
+		First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (pr *donorProfileRepo) GetIdentifiers(ctx context.Context, profileID uuid.UUID) ([]entities.DonorIdentifier, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var identifiers []entities.DonorIdentifier
+	if err := dbFromContext(ctx, pr.DB).Where("profile_id = ?", profileID).Order("kind, value").Find(&identifiers).Error; err != nil {
+		return nil, err
+	}
+	return identifiers, nil
+}
+
+// AddIdentifier menyimpan identitas profil. Identitas yang sudah dimiliki profil lain diabaikan.
+func (pr *donorProfileRepo) AddIdentifier(ctx context.Context, identifier *entities.DonorIdentifier) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, pr.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(identifier).Error
+}
+
+// profileSummaries menyusun query profil beserta rekap donasi yang sudah dibayar
+func (pr *donorProfileRepo) profileSummaries(ctx context.Context) *gorm.DB {
+	return dbFromContext(ctx, pr.DB).
+		Table("donor_profiles").
+		Select(`donor_profiles.*,
+			COUNT(donations.id) AS donation_count,
+			COALESCE(SUM(donations.amount - donations.refunded_amount), 0) AS total_amount,
+			MIN(donations.created_at) AS first_donation_at,
+			MAX(donations.created_at) AS last_donation_at`).
+		Joins("LEFT JOIN donations ON donations.donor_profile_id = donor_profiles.id AND donations.deleted_at IS NULL AND donations.status IN ?", entities.DonationCollectedStatuses).
+		Group("donor_profiles.id")
+}
+
+func (pr *donorProfileRepo) GetProfilesByIDs(ctx context.Context, ids []uuid.UUID) ([]entities.DonorProfileSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var profiles []entities.DonorProfileSummary
+	if err := pr.profileSummaries(ctx).Where("donor_profiles.id IN ?", ids).Scan(&profiles).Error; err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// GetProfiles mengambil profil donatur yang belum digabung, urut dari total donasi terbesar
+func (pr *donorProfileRepo) GetProfiles(ctx context.Context, search string, req *dto_base.PaginationRequest) ([]entities.DonorProfileSummary, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	filter := func(query *gorm.DB) *gorm.DB {
+		query = query.Where("donor_profiles.merged_into_id IS NULL")
+		if search != "" {
+			query = query.Where("(donor_profiles.name ILIKE ? OR donor_profiles.email ILIKE ?)", "%"+search+"%", "%"+search+"%")
+		}
+		return query
+	}
+
+	var totalData int64
+	if err := filter(pr.DB.WithContext(ctx).Model(&entities.DonorProfile{})).Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var profiles []entities.DonorProfileSummary
+	offset := (req.Page - 1) * req.Limit
+	if err := filter(pr.profileSummaries(ctx)).
+		Order("total_amount DESC, donor_profiles.created_at ASC").
+		Limit(req.Limit).
+		Offset(offset).
+		Scan(&profiles).Error; err != nil {
+		return nil, 0, err
+	}
+	return profiles, totalData, nil
+}
+
+// GetAllProfiles mengambil seluruh profil donatur yang belum digabung untuk ekspor
+func (pr *donorProfileRepo) GetAllProfiles(ctx context.Context) ([]entities.DonorProfileSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var profiles []entities.DonorProfileSummary
+	if err := pr.profileSummaries(ctx).
+		Where("donor_profiles.merged_into_id IS NULL").
+		Order("donor_profiles.name ASC").
+		Scan(&profiles).Error; err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (pr *donorProfileRepo) GetActiveProfilesByName(ctx context.Context, nameKey string) ([]entities.DonorProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var profiles []entities.DonorProfile
+	if err := dbFromContext(ctx, pr.DB).
+		Where("merged_into_id IS NULL AND LOWER(TRIM(name)) = ?", nameKey).
+		Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// MergeProfile memindahkan donasi dan identitas profil sumber ke profil tujuan, lalu
+// menandai profil sumber sudah digabung. Dugaan ganda yang melibatkan profil sumber ditutup.
+func (pr *donorProfileRepo) MergeProfile(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db := dbFromContext(ctx, pr.DB)
+
+	if err := db.Model(&entities.Donation{}).
+		Where("donor_profile_id = ?", sourceID).
+		UpdateColumn("donor_profile_id", targetID).Error; err != nil {
+		return err
+	}
+
+	if err := db.Model(&entities.DonorIdentifier{}).
+		Where("profile_id = ?", sourceID).
+		Update("profile_id", targetID).Error; err != nil {
+		return err
+	}
+
+	// Profil yang sebelumnya sudah digabung ke sumber ikut menunjuk ke tujuan
+	if err := db.Model(&entities.DonorProfile{}).
+		Where("merged_into_id = ?", sourceID).
+		Update("merged_into_id", targetID).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&entities.DonorProfile{}).
+		Where("id = ?", sourceID).
+		Updates(map[string]interface{}{"merged_into_id": targetID, "updated_at": time.Now()}).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&entities.DonorDuplicate{}).
+		Where("status = ? AND (profile_id = ? OR candidate_id = ?)", entities.DonorDuplicateOpen, sourceID, sourceID).
+		Updates(map[string]interface{}{"status": entities.DonorDuplicateMerged, "updated_at": time.Now()}).Error
+}
+
+// CreateDuplicate mencatat dugaan profil ganda. Pasangan yang sudah pernah dicatat diabaikan.
+func (pr *donorProfileRepo) CreateDuplicate(ctx context.Context, duplicate *entities.DonorDuplicate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, pr.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(duplicate).Error
+}
+
+// GetDuplicates mengambil dugaan profil ganda dengan status tertentu, atau semua jika status kosong
+func (pr *donorProfileRepo) GetDuplicates(ctx context.Context, status string) ([]entities.DonorDuplicate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := pr.DB.WithContext(ctx).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var duplicates []entities.DonorDuplicate
+	if err := query.Find(&duplicates).Error; err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+func (pr *donorProfileRepo) FindDuplicateForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonorDuplicate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var duplicate entities.DonorDuplicate
+	if err := dbFromContext(ctx, pr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).First(&duplicate, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &duplicate, nil
+}
+
+func (pr *donorProfileRepo) UpdateDuplicate(ctx context.Context, duplicate *entities.DonorDuplicate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, pr.DB).Save(duplicate).Error
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DonorVerificationRepository interface {
+	CreateVerification(ctx context.Context, verification *entities.DonorVerification) error
+	FindActiveByTokenHash(ctx context.Context, channel string, tokenHash string) (*entities.DonorVerification, error)
+	FindLatestActive(ctx context.Context, userID uuid.UUID, channel string) (*entities.DonorVerification, error)
+	Update(ctx context.Context, verification *entities.DonorVerification) error
+	GetUsageSince(ctx context.Context, userID uuid.UUID, channel string, since time.Time) (*VerificationUsage, error)
+}
+
+// VerificationUsage adalah jumlah token yang diterbitkan dan percobaan salah seluruh
+// token sebuah akun dalam satu jendela waktu
+type VerificationUsage struct {
+	Issued         int
+	FailedAttempts int
+}
+
+type donorVerificationRepo struct {
+	DB *gorm.DB
+}
+
+func NewDonorVerificationRepository(db *gorm.DB) DonorVerificationRepository {
+	return &donorVerificationRepo{
+		DB: db,
+	}
+}
+
+// CreateVerification menyimpan token baru dan menonaktifkan token lama di kanal yang sama,
+// sehingga hanya token terakhir yang berlaku
+func (vr *donorVerificationRepo) CreateVerification(ctx context.Context, verification *entities.DonorVerification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db := dbFromContext(ctx, vr.DB)
+	if err := db.Model(&entities.DonorVerification{}).
+		Where("user_id = ? AND channel = ? AND used_at IS NULL", verification.UserID, verification.Channel).
+		Update("expires_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return db.Create(verification).Error
+}
+
+func (vr *donorVerificationRepo) FindActiveByTokenHash(ctx context.Context, channel string, tokenHash string) (*entities.DonorVerification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var verification entities.DonorVerification
+	err := dbFromContext(ctx, vr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("channel = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", channel, tokenHash, time.Now()).
+		First(&verification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+func (vr *donorVerificationRepo) FindLatestActive(ctx context.Context, userID uuid.UUID, channel string) (*entities.DonorVerification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var verification entities.DonorVerification
+	err := dbFromContext(ctx, vr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND channel = ? AND used_at IS NULL AND expires_at > ?", userID, channel, time.Now()).
+		Order("created_at DESC").
+		First(&verification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+func (vr *donorVerificationRepo) Update(ctx context.Context, verification *entities.DonorVerification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, vr.DB).Save(verification).Error
+}
+
+// GetUsageSince menghitung token yang dibuat sejak since beserta total percobaan salahnya,
+// termasuk token yang sudah dinonaktifkan
+func (vr *donorVerificationRepo) GetUsageSince(ctx context.Context, userID uuid.UUID, channel string, since time.Time) (*VerificationUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var usage VerificationUsage
+	err := dbFromContext(ctx, vr.DB).Model(&entities.DonorVerification{}).
+		Select("COUNT(*) AS issued, COALESCE(SUM(attempts), 0) AS failed_attempts").
+		Where("user_id = ? AND channel = ? AND created_at >= ?", userID, channel, since).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
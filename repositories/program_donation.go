@@ -150,13 +150,18 @@ func (pdr *programDonationRepo) GetDashboardData(ctx context.Context) (int64, in
 		return 0, 0, 0, err
 	}
 
-	// Menghitung total donasi terkumpul dengan status = 1
-	if err := pdr.DB.Model(&entities.Donation{}).Where("status = ?", 1).Select("SUM(amount)").Scan(&totalDonation).Error; err != nil {
+	// Menghitung total donasi terkumpul yang sudah dibayar
+	if err := pdr.DB.Model(&entities.Donation{}).Where("status IN ?", entities.DonationCollectedStatuses).Select("COALESCE(SUM(amount - refunded_amount), 0)").Scan(&totalDonation).Error; err != nil {
 		return 0, 0, 0, err
 	}
 
-	// Menghitung jumlah donatur unik (berdasarkan email atau nomor WA)
-	if err := pdr.DB.Model(&entities.Donation{}).Distinct("email").Count(&uniqueDonatorsCount).Error; err != nil {
+	// Menghitung jumlah donatur unik dari donasi yang sudah dibayar, agar donasi pending
+	// yang kadaluarsa tidak ikut terhitung. Donasi dihitung per profil donatur; donasi yang
+	// belum dikelompokkan worker direktori donatur memakai email yang dinormalkan.
+	if err := pdr.DB.Model(&entities.Donation{}).
+		Where("status IN ?", entities.DonationCollectedStatuses).
+		Select("COUNT(DISTINCT COALESCE(donor_profile_id::text, LOWER(TRIM(email))))").
+		Scan(&uniqueDonatorsCount).Error; err != nil {
 		return 0, 0, 0, err
 	}
 
@@ -168,31 +173,20 @@ func (pdr *programDonationRepo) UpdateCurrentAmount(ctx context.Context, program
         return errors.New("db is nil")
     }
 
-    // Memulai transaction untuk memastikan konsistensi data
-    tx := pdr.DB.Begin()
-
-    if tx.Error != nil {
-        return tx.Error
-    }
-
-    // Ambil data ProgramDonation berdasarkan ID
-    var programDonation entities.ProgramDonation
-    if err := tx.Where("id = ?", programDonationID).First(&programDonation).Error; err != nil {
-        tx.Rollback()
-        return err
+    // Increment dilakukan langsung di database agar aman dari update yang bersamaan,
+    // dan ikut transaksi pemanggil jika ada
+    result := dbFromContext(ctx, pdr.DB).
+        Model(&entities.ProgramDonation{}).
+        Where("id = ?", programDonationID).
+        UpdateColumn("current_amount", gorm.Expr("COALESCE(current_amount, 0) + ?", amount))
+    if result.Error != nil {
+        return result.Error
     }
-
-    // Update currentAmount
-    programDonation.CurrentAmount += amount
-
-    // Simpan perubahan ke database
-    if err := tx.Save(&programDonation).Error; err != nil {
-        tx.Rollback()
-        return err
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
     }
 
-    // Commit transaction jika berhasil
-    return tx.Commit().Error
+    return nil
 }
 
 
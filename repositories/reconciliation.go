@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"tugas-akhir/entities"
+
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepository interface {
+	CreateRun(ctx context.Context, run *entities.ReconciliationRun) error
+	GetLastRun(ctx context.Context) (*entities.ReconciliationRun, error)
+}
+
+type reconciliationRepo struct {
+	DB *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepo{
+		DB: db,
+	}
+}
+
+// CreateRun menyimpan hasil rekonsiliasi beserta item-itemnya
+func (rr *reconciliationRepo) CreateRun(ctx context.Context, run *entities.ReconciliationRun) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, rr.DB).Create(run).Error
+}
+
+// GetLastRun mengambil rekonsiliasi terakhir, nil jika belum pernah dijalankan
+func (rr *reconciliationRepo) GetLastRun(ctx context.Context) (*entities.ReconciliationRun, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var run entities.ReconciliationRun
+	if err := rr.DB.WithContext(ctx).Preload("Items").Order("started_at DESC").First(&run).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
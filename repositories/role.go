@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"tugas-akhir/entities"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	GetRoles(ctx context.Context) ([]entities.Role, error)
+	GetRole(ctx context.Context, name string) (*entities.Role, error)
+	GetPermissions(ctx context.Context) ([]entities.Permission, error)
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
+	HasPermission(ctx context.Context, role string, permission string) (bool, error)
+	SetRolePermissions(ctx context.Context, role string, permissions []string) error
+}
+
+type roleRepo struct {
+	DB *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepo{
+		DB: db,
+	}
+}
+
+func (rr *roleRepo) GetRoles(ctx context.Context) ([]entities.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var roles []entities.Role
+	if err := dbFromContext(ctx, rr.DB).Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (rr *roleRepo) GetRole(ctx context.Context, name string) (*entities.Role, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var role entities.Role
+	if err := dbFromContext(ctx, rr.DB).Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (rr *roleRepo) GetPermissions(ctx context.Context) ([]entities.Permission, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var permissions []entities.Permission
+	if err := dbFromContext(ctx, rr.DB).Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// GetRolePermissions mengambil nama hak akses yang tersimpan untuk satu role
+func (rr *roleRepo) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var permissions []string
+	if err := dbFromContext(ctx, rr.DB).Model(&entities.RolePermission{}).
+		Where("role_name = ?", role).
+		Order("permission_name").
+		Pluck("permission_name", &permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (rr *roleRepo) HasPermission(ctx context.Context, role string, permission string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := dbFromContext(ctx, rr.DB).Model(&entities.RolePermission{}).
+		Where("role_name = ? AND permission_name = ?", role, permission).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SetRolePermissions mengganti seluruh hak akses role. Dipanggil di dalam transaksi
+// agar role tidak sempat kehilangan semua hak aksesnya.
+func (rr *roleRepo) SetRolePermissions(ctx context.Context, role string, permissions []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db := dbFromContext(ctx, rr.DB)
+	if err := db.Where("role_name = ?", role).Delete(&entities.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	rolePermissions := make([]entities.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		rolePermissions = append(rolePermissions, entities.RolePermission{RoleName: role, PermissionName: permission})
+	}
+	return db.Create(&rolePermissions).Error
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *entities.Session) error
+	FindByRefreshTokenHashForUpdate(ctx context.Context, tokenHash string) (*entities.Session, error)
+	Update(ctx context.Context, session *entities.Session) error
+	IsRevoked(ctx context.Context, id uuid.UUID) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAll(ctx context.Context, subjectID uuid.UUID, exceptID uuid.UUID) (int64, error)
+}
+
+type sessionRepo struct {
+	DB *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepo{
+		DB: db,
+	}
+}
+
+func (sr *sessionRepo) CreateSession(ctx context.Context, session *entities.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, sr.DB).Create(session).Error
+}
+
+// FindByRefreshTokenHashForUpdate mencari sesi dari refresh token yang berlaku maupun
+// refresh token sebelumnya, lalu mengunci barisnya sampai transaksi selesai
+func (sr *sessionRepo) FindByRefreshTokenHashForUpdate(ctx context.Context, tokenHash string) (*entities.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var session entities.Session
+	err := dbFromContext(ctx, sr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("refresh_token_hash = ? OR previous_token_hash = ?", tokenHash, tokenHash).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (sr *sessionRepo) Update(ctx context.Context, session *entities.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, sr.DB).Save(session).Error
+}
+
+// IsRevoked dipanggil pada setiap request yang membawa access token. Hanya sesi yang
+// masih aktif yang diterima, sehingga sesi yang tidak dikenal, sudah dihapus, atau
+// sudah kedaluwarsa diperlakukan sama dengan sesi yang dicabut.
+func (sr *sessionRepo) IsRevoked(ctx context.Context, id uuid.UUID) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := dbFromContext(ctx, sr.DB).Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, time.Now()).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+func (sr *sessionRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, sr.DB).Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAll mencabut semua sesi milik satu akun kecuali exceptID. Isi exceptID dengan
+// uuid.Nil untuk mencabut semuanya.
+func (sr *sessionRepo) RevokeAll(ctx context.Context, subjectID uuid.UUID, exceptID uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	result := dbFromContext(ctx, sr.DB).Model(&entities.Session{}).
+		Where("subject_id = ? AND id <> ? AND revoked_at IS NULL", subjectID, exceptID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
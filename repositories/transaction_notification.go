@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"tugas-akhir/entities"
+	err_util "tugas-akhir/utils/error"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -11,6 +13,8 @@ import (
 
 type TransactionNotificationRepository interface {
 	CreateNotification(ctx context.Context, notification *entities.TransactionNotification) error
+	ExistsByTransactionStatus(ctx context.Context, transactionID string, transactionStatus string) (bool, error)
+	GetSavedTokenByDonationID(ctx context.Context, donationID uuid.UUID) (string, error)
 }
 
 type transactionNotificationRepo struct {
@@ -32,9 +36,42 @@ func (t *transactionNotificationRepo) CreateNotification(ctx context.Context, no
 	}
 
 	log.Infof("Inserting transaction notification into DB: %+v", notification)
-	if err := t.DB.WithContext(ctx).Create(notification).Error; err != nil {
+	if err := dbFromContext(ctx, t.DB).Create(notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err_util.ErrDuplicateNotification
+		}
 		log.WithError(err).Error("Failed to insert transaction notification into DB")
 		return err
 	}
 	return nil
 }
+
+// ExistsByTransactionStatus memeriksa apakah notifikasi dengan transaction_id dan status
+// yang sama sudah pernah diproses
+func (t *transactionNotificationRepo) ExistsByTransactionStatus(ctx context.Context, transactionID string, transactionStatus string) (bool, error) {
+	var count int64
+	if err := dbFromContext(ctx, t.DB).
+		Model(&entities.TransactionNotification{}).
+		Where("transaction_id = ? AND transaction_status = ?", transactionID, transactionStatus).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetSavedTokenByDonationID mengambil token kartu tersimpan terbaru dari notifikasi
+// semua percobaan pembayaran sebuah donasi, kosong jika tidak ada
+func (t *transactionNotificationRepo) GetSavedTokenByDonationID(ctx context.Context, donationID uuid.UUID) (string, error) {
+	var tokens []string
+	if err := t.DB.WithContext(ctx).Model(&entities.TransactionNotification{}).
+		Where("(order_id = ? OR order_id LIKE ?) AND saved_token_id <> ''", donationID.String(), donationID.String()+"-%").
+		Order("created_at DESC").
+		Limit(1).
+		Pluck("saved_token_id", &tokens).Error; err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", nil
+	}
+	return tokens[0], nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferProofRepository interface {
+	CreateProof(ctx context.Context, proof *entities.TransferProof) error
+	FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.TransferProof, error)
+	Update(ctx context.Context, proof *entities.TransferProof) error
+	GetProofs(ctx context.Context, status string) ([]entities.TransferProof, error)
+	HasPendingProof(ctx context.Context, donationID uuid.UUID) (bool, error)
+}
+
+type transferProofRepo struct {
+	DB *gorm.DB
+}
+
+func NewTransferProofRepository(db *gorm.DB) TransferProofRepository {
+	return &transferProofRepo{
+		DB: db,
+	}
+}
+
+func (tr *transferProofRepo) CreateProof(ctx context.Context, proof *entities.TransferProof) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, tr.DB).Create(proof).Error
+}
+
+// FindByIdForUpdate mengunci bukti transfer agar satu bukti tidak diverifikasi dua kali
+func (tr *transferProofRepo) FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.TransferProof, error) {
+	var proof entities.TransferProof
+	if err := dbFromContext(ctx, tr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).First(&proof, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
+func (tr *transferProofRepo) Update(ctx context.Context, proof *entities.TransferProof) error {
+	return dbFromContext(ctx, tr.DB).Save(proof).Error
+}
+
+// GetProofs mengambil bukti transfer dengan status tertentu, atau semua jika status kosong
+func (tr *transferProofRepo) GetProofs(ctx context.Context, status string) ([]entities.TransferProof, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := tr.DB.WithContext(ctx).Order("created_at ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var proofs []entities.TransferProof
+	if err := query.Find(&proofs).Error; err != nil {
+		return nil, err
+	}
+	return proofs, nil
+}
+
+func (tr *transferProofRepo) HasPendingProof(ctx context.Context, donationID uuid.UUID) (bool, error) {
+	var count int64
+	if err := tr.DB.WithContext(ctx).Model(&entities.TransferProof{}).
+		Where("donation_id = ? AND status = ?", donationID, entities.TransferProofPending).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
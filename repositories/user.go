@@ -7,12 +7,16 @@ import (
 
 	dto_base "tugas-akhir/dto/base"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *entities.User) error
 	GetUserAll(ctx context.Context, req *dto_base.PaginationRequest) ([]entities.User, int64, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
+	GetRegisteredUserByEmail(ctx context.Context, email string) (*entities.User, error)
+	UpdateUser(ctx context.Context, user *entities.User) error
 }
 
 type userRepo struct {
@@ -29,7 +33,7 @@ func (ur *userRepo) CreateUser(ctx context.Context, user *entities.User) error {
     if err := ctx.Err(); err != nil {
         return err
     }
-    if err := ur.DB.Create(user).Error; err != nil {
+    if err := dbFromContext(ctx, ur.DB).Create(user).Error; err != nil {
         return err
     }
     return nil
@@ -69,3 +73,35 @@ func (ur *userRepo) GetUserAll(ctx context.Context, req *dto_base.PaginationRequ
 
 	return users, totalData, nil
 }
+
+func (ur *userRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var user entities.User
+	if err := dbFromContext(ctx, ur.DB).First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetRegisteredUserByEmail mencari akun donatur yang sudah mendaftar berdasarkan email
+func (ur *userRepo) GetRegisteredUserByEmail(ctx context.Context, email string) (*entities.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var user entities.User
+	if err := dbFromContext(ctx, ur.DB).First(&user, "email = ? AND password <> ''", email).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (ur *userRepo) UpdateUser(ctx context.Context, user *entities.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, ur.DB).Save(user).Error
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	dto_base "tugas-akhir/dto/base"
+	"tugas-akhir/entities"
+	"tugas-akhir/utils/phone"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WhatsAppRepository interface {
+	Enqueue(ctx context.Context, message *entities.WhatsAppMessage) (bool, error)
+	GetQueuedMessages(ctx context.Context, now time.Time, limit int) ([]entities.WhatsAppMessage, error)
+	FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.WhatsAppMessage, error)
+	FindByProviderIDForUpdate(ctx context.Context, providerMessageID string) (*entities.WhatsAppMessage, error)
+	Update(ctx context.Context, message *entities.WhatsAppMessage) error
+	GetMessages(ctx context.Context, status string, req *dto_base.PaginationRequest) ([]entities.WhatsAppMessage, int64, error)
+	GetUpdateRecipients(ctx context.Context, template string, reference string) ([]entities.WhatsAppUpdateRecipient, error)
+	IsOptedOut(ctx context.Context, number phone.Number) (bool, error)
+	OptOut(ctx context.Context, number phone.Number, source string) error
+	OptIn(ctx context.Context, number phone.Number) error
+}
+
+type whatsAppRepo struct {
+	DB *gorm.DB
+}
+
+func NewWhatsAppRepository(db *gorm.DB) WhatsAppRepository {
+	return &whatsAppRepo{
+		DB: db,
+	}
+}
+
+// Enqueue memasukkan pesan ke antrean. Pesan dengan template, nomor, dan referensi yang
+// sama dilewati dan mengembalikan false.
+func (wr *whatsAppRepo) Enqueue(ctx context.Context, message *entities.WhatsAppMessage) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	result := dbFromContext(ctx, wr.DB).Clauses(clause.OnConflict{DoNothing: true}).Create(message)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetQueuedMessages mengambil pesan yang sudah waktunya dikirim, terlama lebih dulu
+func (wr *whatsAppRepo) GetQueuedMessages(ctx context.Context, now time.Time, limit int) ([]entities.WhatsAppMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var messages []entities.WhatsAppMessage
+	if err := wr.DB.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", entities.WhatsAppStatusQueued, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (wr *whatsAppRepo) FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.WhatsAppMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var message entities.WhatsAppMessage
+	if err := dbFromContext(ctx, wr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).First(&message, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (wr *whatsAppRepo) FindByProviderIDForUpdate(ctx context.Context, providerMessageID string) (*entities.WhatsAppMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var message entities.WhatsAppMessage
+	if err := dbFromContext(ctx, wr.DB).Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&message, "provider_message_id = ?", providerMessageID).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (wr *whatsAppRepo) Update(ctx context.Context, message *entities.WhatsAppMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, wr.DB).Save(message).Error
+}
+
+// GetMessages mengambil riwayat pesan untuk admin, terbaru lebih dulu. Status kosong
+// berarti semua status.
+func (wr *whatsAppRepo) GetMessages(ctx context.Context, status string, req *dto_base.PaginationRequest) ([]entities.WhatsAppMessage, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	query := wr.DB.WithContext(ctx).Model(&entities.WhatsAppMessage{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var totalData int64
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []entities.WhatsAppMessage
+	offset := (req.Page - 1) * req.Limit
+	if err := query.Order("created_at DESC").Limit(req.Limit).Offset(offset).Find(&messages).Error; err != nil {
+		return nil, 0, err
+	}
+	return messages, totalData, nil
+}
+
+// GetUpdateRecipients mengambil nomor donatur yang donasinya sudah diterima beserta
+// program yang pernah didukung, termasuk program dari alokasi donasi. Nomor yang
+// opt-out atau sudah mendapat pesan dengan template dan referensi yang sama dilewati.
+func (wr *whatsAppRepo) GetUpdateRecipients(ctx context.Context, template string, reference string) ([]entities.WhatsAppUpdateRecipient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db := wr.DB.WithContext(ctx)
+	var recipients []entities.WhatsAppUpdateRecipient
+	err := db.Model(&entities.Donation{}).
+		Select("donations.no_wa AS phone, MAX(donations.name) AS name, COALESCE(donation_allocations.program_donation_id, donations.program_donation_id) AS program_donation_id").
+		Joins("LEFT JOIN donation_allocations ON donation_allocations.donation_id = donations.id").
+		Where("donations.status IN ? AND donations.no_wa <> ''", entities.DonationCollectedStatuses).
+		Where("donations.no_wa NOT IN (?)", db.Model(&entities.WhatsAppOptOut{}).Select("phone")).
+		Where("donations.no_wa NOT IN (?)", db.Model(&entities.WhatsAppMessage{}).Select("phone").Where("template = ? AND reference = ?", template, reference)).
+		Group("donations.no_wa, COALESCE(donation_allocations.program_donation_id, donations.program_donation_id)").
+		Order("donations.no_wa").
+		Scan(&recipients).Error
+	if err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+func (wr *whatsAppRepo) IsOptedOut(ctx context.Context, number phone.Number) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := dbFromContext(ctx, wr.DB).Model(&entities.WhatsAppOptOut{}).Where("phone = ?", number).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// OptOut mencatat nomor yang berhenti berlangganan dan membatalkan pesan yang masih
+// mengantre untuk nomor tersebut
+func (wr *whatsAppRepo) OptOut(ctx context.Context, number phone.Number, source string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db := dbFromContext(ctx, wr.DB)
+	optOut := entities.WhatsAppOptOut{Phone: number, Source: source, CreatedAt: time.Now()}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&optOut).Error; err != nil {
+		return err
+	}
+	return db.Model(&entities.WhatsAppMessage{}).
+		Where("phone = ? AND status = ?", number, entities.WhatsAppStatusQueued).
+		Updates(map[string]interface{}{
+			"status":     entities.WhatsAppStatusFailed,
+			"error":      "recipient opted out",
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (wr *whatsAppRepo) OptIn(ctx context.Context, number phone.Number) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return dbFromContext(ctx, wr.DB).Where("phone = ?", number).Delete(&entities.WhatsAppOptOut{}).Error
+}
@@ -1,9 +1,12 @@
 package admin
 
 import (
+	"tugas-akhir/config"
 	"tugas-akhir/controllers"
+	"tugas-akhir/drivers/mail"
 	"tugas-akhir/repositories"
 	"tugas-akhir/usecases"
+	"tugas-akhir/utils/mailtemplate"
 	"tugas-akhir/utils/password"
 	"tugas-akhir/utils/token"
 	"tugas-akhir/utils/validation"
@@ -16,13 +19,39 @@ func InitAdminRoute(g *echo.Group, db *gorm.DB, v *validation.Validator) {
 
 	passwordUtil := password.NewPasswordUtil()
 	tokenUtil := token.NewTokenUtil()
+	adminConfig := config.InitConfigAdmin()
+	mailConfig := config.InitConfigMail()
+	notifier := usecases.NewAdminNotifier(mail.NewTransport(mailConfig), mailtemplate.NewRenderer(mailConfig.FromName), adminConfig)
 
 	adminRepo := repositories.NewAdminRepository(db)
-	adminUseCase := usecases.NewAdminUsecase(adminRepo, passwordUtil,  tokenUtil)
+	invitationRepo := repositories.NewAdminInvitationRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	transactionManager := repositories.NewTransactionManager(db)
+	sessionUsecase := usecases.NewSessionUsecase(repositories.NewSessionRepository(db), adminRepo, repositories.NewUserRepository(db), transactionManager, tokenUtil)
+	roleUsecase := usecases.NewRoleUsecase(roleRepo, adminRepo, sessionUsecase, transactionManager)
+	adminUseCase := usecases.NewAdminUsecase(adminRepo, invitationRepo, roleRepo, roleUsecase, sessionUsecase, transactionManager, passwordUtil, notifier, adminConfig)
 	adminController := controllers.NewAdminController(adminUseCase, v, tokenUtil)
+	roleController := controllers.NewRoleController(roleUsecase, v, tokenUtil)
 
-	// Public routes
+	// Public routes. Pendaftaran admin hanya dengan token undangan.
 	g.POST("/admin/login", adminController.Login)
 	g.POST("/admin/register", adminController.Register)
 
+	// Akun admin yang sedang login
+	g.PUT("/admin/me/password", adminController.ChangePassword)
+
+	// Pengelolaan akun admin
+	g.GET("/admin/admins", adminController.GetAdmins)
+	g.POST("/admin/invitations", adminController.InviteAdmin)
+	g.PUT("/admin/admins/:id", adminController.UpdateAdmin)
+	g.POST("/admin/admins/:id/deactivate", adminController.DeactivateAdmin)
+	g.POST("/admin/admins/:id/activate", adminController.ActivateAdmin)
+	g.DELETE("/admin/admins/:id", adminController.DeleteAdmin)
+
+	// Role dan hak akses admin
+	g.GET("/admin/roles", roleController.GetRoles)
+	g.GET("/admin/permissions", roleController.GetPermissions)
+	g.PUT("/admin/roles/:name/permissions", roleController.UpdateRolePermissions)
+	g.PUT("/admin/admins/:id/role", roleController.AssignRole)
+
 }
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"tugas-akhir/controllers"
+	"tugas-akhir/repositories"
+	"tugas-akhir/usecases"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func InitAuthRoute(g *echo.Group, db *gorm.DB, v *validation.Validator) {
+	tokenUtil := token.NewTokenUtil()
+
+	sessionUsecase := usecases.NewSessionUsecase(
+		repositories.NewSessionRepository(db),
+		repositories.NewAdminRepository(db),
+		repositories.NewUserRepository(db),
+		repositories.NewTransactionManager(db),
+		tokenUtil,
+	)
+	authController := controllers.NewAuthController(sessionUsecase, v, tokenUtil)
+
+	// Sesi login donatur dan admin. Login tetap lewat /donors/login dan /admin/login.
+	g.POST("/auth/refresh", authController.Refresh)
+	g.POST("/auth/logout", authController.Logout)
+	g.POST("/auth/logout-all", authController.LogoutAll)
+}
@@ -1,12 +1,24 @@
 package donation
 
 import (
+	"context"
+	"log"
 	"tugas-akhir/config"
 	"tugas-akhir/controllers"
+	"tugas-akhir/drivers/broker"
+	"tugas-akhir/drivers/cloudinary"
+	"tugas-akhir/drivers/mail"
+	"tugas-akhir/drivers/payment"
+	"tugas-akhir/drivers/redis"
+	"tugas-akhir/drivers/whatsapp"
+	"tugas-akhir/middlewares"
 	"tugas-akhir/repositories"
 	"tugas-akhir/usecases"
+	"tugas-akhir/utils/mailtemplate"
+	"tugas-akhir/utils/scheduler"
+	"tugas-akhir/utils/token"
 	"tugas-akhir/utils/validation"
-	midtrans "tugas-akhir/utils/midtrans"
+	"tugas-akhir/utils/watemplate"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -14,33 +26,170 @@ import (
 
 func InitDonationRoute(g *echo.Group, db *gorm.DB, v *validation.Validator) {
 
-	// Inisialisasi konfigurasi Midtrans
-	config := config.InitConfigMidtrans()
-
-	// Membuat client Midtrans berdasarkan konfigurasi
-	midtransClient := midtrans.NewClient(config)
+	// Inisialisasi konfigurasi Midtrans dan payment gateway yang dipakai
+	midtransConfig := config.InitConfigMidtrans()
+	paymentGateway, err := payment.NewPaymentGateway(config.InitConfigPayment(), midtransConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	donationConfig := config.InitConfigDonation()
+	receiptConfig := config.InitConfigReceipt()
+	moderationConfig := config.InitConfigModeration()
+	mailConfig := config.InitConfigMail()
+	whatsAppConfig := config.InitConfigWhatsApp()
+	cloudinaryInstance, _ := config.SetupCloudinary()
+	cloudinaryService := cloudinary.NewCloudinaryService(cloudinaryInstance)
 
 	// Inisialisasi repository-repository yang diperlukan
 	donationRepo := repositories.NewDonationRepository(db)
 	programDonationRepo := repositories.NewProgramDonationRepository(db)
 	transactionNotificationRepo := repositories.NewTransactionNotificationRepository(db)
-	
+	transactionManager := repositories.NewTransactionManager(db)
+	reconciliationRepo := repositories.NewReconciliationRepository(db)
+	donationRefundRepo := repositories.NewDonationRefundRepository(db)
+	transferProofRepo := repositories.NewTransferProofRepository(db)
+	donationAllocationRepo := repositories.NewDonationAllocationRepository(db)
+	donationSubscriptionRepo := repositories.NewDonationSubscriptionRepository(db)
+	donationReceiptRepo := repositories.NewDonationReceiptRepository(db)
+	donorProfileRepo := repositories.NewDonorProfileRepository(db)
+	donationMessageRepo := repositories.NewDonationMessageRepository(db)
+	adminRepo := repositories.NewAdminRepository(db)
+	donationNotificationRepo := repositories.NewDonationNotificationRepository(db)
+	whatsAppRepo := repositories.NewWhatsAppRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+
+	// Broker dalam proses untuk feed donasi real-time
+	eventBroker := broker.NewMemoryBroker()
+
+	// Email dikirim lewat antrean asinkron sesuai MAIL_TRANSPORT
+	mailTransport := mail.NewTransport(mailConfig)
+	mailRenderer := mailtemplate.NewRenderer(mailConfig.FromName)
+
+	// Pesan WhatsApp hanya dimasukkan ke antrean di sini, pengirimannya oleh worker di route WhatsApp
+	whatsAppProvider := whatsapp.NewProvider(whatsAppConfig)
+	whatsAppRenderer := watemplate.NewRenderer(mailConfig.FromName)
+
 	// Inisialisasi Usecase untuk Donation
-	donationUsecase := usecases.NewDonationUsecase(donationRepo,config,  programDonationRepo, transactionNotificationRepo)
+	donationFeedUsecase := usecases.NewDonationFeedUsecase(eventBroker, donationRepo, programDonationRepo)
+	sessionUsecase := usecases.NewSessionUsecase(repositories.NewSessionRepository(db), adminRepo, userRepo, transactionManager, token.NewTokenUtil())
+	donationReceiptUsecase := usecases.NewDonationReceiptUsecase(donationReceiptRepo, donationRepo, programDonationRepo, donationAllocationRepo, transactionManager, receiptConfig)
+	donationMailer := usecases.NewDonationMailer(mailTransport, mailRenderer, donationRepo, programDonationRepo, adminRepo, donationReceiptUsecase, mailConfig)
+	whatsAppUsecase := usecases.NewWhatsAppUsecase(whatsAppProvider, whatsAppRenderer, whatsAppRepo, donationRepo, programDonationRepo, userRepo, transactionManager, whatsAppConfig)
+	donationUsecase := usecases.NewDonationUsecase(donationRepo, paymentGateway, programDonationRepo, transactionNotificationRepo, transactionManager, donationAllocationRepo, donationReceiptUsecase, donationFeedUsecase, donationMailer, whatsAppUsecase)
+
+	reconciliationUsecase := usecases.NewReconciliationUsecase(donationUsecase, donationRepo, reconciliationRepo, paymentGateway, donationConfig)
+	donationRefundUsecase := usecases.NewDonationRefundUsecase(donationRepo, programDonationRepo, donationRefundRepo, donationAllocationRepo, paymentGateway, transactionManager, donationFeedUsecase, donationConfig)
+	donationExpiryUsecase := usecases.NewDonationExpiryUsecase(donationUsecase, donationRepo, transferProofRepo, paymentGateway, donationConfig)
+	offlineDonationUsecase := usecases.NewOfflineDonationUsecase(donationUsecase, donationRepo, programDonationRepo, transferProofRepo, transactionManager)
+	donationSubscriptionUsecase := usecases.NewDonationSubscriptionUsecase(donationUsecase, donationRepo, donationSubscriptionRepo, programDonationRepo, transactionNotificationRepo, transactionManager, usecases.NewMailSubscriptionNotifier(mailTransport, mailRenderer, programDonationRepo), donationConfig)
+	donorDirectoryUsecase := usecases.NewDonorDirectoryUsecase(donorProfileRepo, transactionManager)
+	donationMessageUsecase := usecases.NewDonationMessageUsecase(donationRepo, donationMessageRepo, transactionManager, moderationConfig)
+	donationNotificationUsecase := usecases.NewDonationNotificationUsecase(donationNotificationRepo)
 
 	// Inisialisasi Controller untuk Donation
-	donationController := controllers.NewDonationController(donationUsecase, v, midtransClient)
+	donationController := controllers.NewDonationController(donationUsecase, v)
+	reconciliationController := controllers.NewReconciliationController(reconciliationUsecase)
+	donationRefundController := controllers.NewDonationRefundController(donationRefundUsecase, v, token.NewTokenUtil())
+	offlineDonationController := controllers.NewOfflineDonationController(offlineDonationUsecase, v, cloudinaryService, token.NewTokenUtil())
+	donationSubscriptionController := controllers.NewDonationSubscriptionController(donationSubscriptionUsecase, v)
+	donationReceiptController := controllers.NewDonationReceiptController(donationReceiptUsecase, token.NewTokenUtil())
+	donorDirectoryController := controllers.NewDonorDirectoryController(donorDirectoryUsecase, v, token.NewTokenUtil())
+	donationMessageController := controllers.NewDonationMessageController(donationMessageUsecase, v, token.NewTokenUtil())
+	donationFeedController := controllers.NewDonationFeedController(donationFeedUsecase, sessionUsecase)
+	donationNotificationController := controllers.NewDonationNotificationController(donationNotificationUsecase, token.NewTokenUtil())
+
+	// Worker rekonsiliasi untuk donasi pending yang webhook-nya hilang
+	scheduler.Every(context.Background(), "donation-reconciliation", donationConfig.ReconcileInterval, func(ctx context.Context) {
+		reconciliationUsecase.Run(ctx, "scheduler")
+	})
+
+	// Worker yang melanjutkan refund gateway yang terhenti sebelum selesai dicatat
+	scheduler.Every(context.Background(), "donation-refund", donationConfig.ReconcileInterval, func(ctx context.Context) {
+		donationRefundUsecase.ResumePendingRefunds(ctx)
+	})
+
+	// Worker untuk mengubah donasi pending yang ditinggalkan menjadi expired
+	scheduler.Every(context.Background(), "donation-expiry", donationConfig.ExpireInterval, func(ctx context.Context) {
+		donationExpiryUsecase.ExpireStaleDonations(ctx)
+	})
+
+	// Worker penagihan donasi rutin dan tagih ulang pembayaran yang gagal
+	scheduler.Every(context.Background(), "donation-subscription", donationConfig.SubscriptionInterval, func(ctx context.Context) {
+		donationSubscriptionUsecase.ProcessCycles(ctx)
+	})
+
+	// Worker yang mengelompokkan donasi baru ke profil donatur
+	scheduler.Every(context.Background(), "donor-directory", donationConfig.DonorDirectoryInterval, func(ctx context.Context) {
+		donorDirectoryUsecase.GroupDonations(ctx)
+	})
+
+	// Worker yang memeriksa pesan donatur baru dengan filter kata dan tautan
+	scheduler.Every(context.Background(), "donation-moderation", moderationConfig.Interval, func(ctx context.Context) {
+		donationMessageUsecase.ScreenMessages(ctx)
+	})
 
 	// Daftarkan route POST untuk membuat donasi
-	g.POST("/donations", donationController.CreateDonation)
+	// Header Idempotency-Key mencegah double submit membuat donasi dan transaksi ganda
+	g.POST("/donations", donationController.CreateDonation, middlewares.Idempotency(redis.NewRedisClient(), donationConfig.IdempotencyLockTTL, donationConfig.IdempotencyTTL))
+	g.POST("/donations/:id/resume", donationController.ResumeDonation)
+	g.POST("/donations/:id/transfer-proof", offlineDonationController.SubmitTransferProof)
+
+	// Donasi rutin, dikelola donatur dengan token pada header X-Subscription-Token
+	g.POST("/donations/subscriptions", donationSubscriptionController.CreateSubscription, middlewares.Idempotency(redis.NewRedisClient(), donationConfig.IdempotencyLockTTL, donationConfig.IdempotencyTTL))
+	g.POST("/donations/subscriptions/:id/pause", donationSubscriptionController.PauseSubscription)
+	g.POST("/donations/subscriptions/:id/resume", donationSubscriptionController.ResumeSubscription)
+	g.POST("/donations/subscriptions/:id/cancel", donationSubscriptionController.CancelSubscription)
 
 	// Daftarkan route POST untuk menerima webhook dari Midtrans
 	g.POST("/midtrans-webhook", donationController.MidtransWebhook)
 	g.GET("/donations-all", donationController.GetDonations)
 	g.GET("/donations/:id", donationController.GetDonationByID)
+	g.GET("/donations/:id/public", donationController.GetPublicDonation)
+	g.GET("/donations/:id/receipt", donationReceiptController.GetReceipt)
+	g.GET("/donations/receipts/verify", donationReceiptController.VerifyReceipt)
 	g.GET("/donations-user", donationController.GetDonationsLanding)
 	g.GET("/donations-chart", donationController.GetChartDonation)
-	g.GET("/donations-notifikasi", donationController.GetDonaturNotifikasi)
 	g.GET("/donations-program/:id", donationController.GetDonaturByProgramDonation)
+	g.GET("/donations/prayers", donationMessageController.GetPrayers)
+	g.GET("/donations/stream/public", donationFeedController.StreamPublic)
+
+	// Riwayat donasi, kuitansi, dan donasi rutin milik donatur yang login
+	g.GET("/donors/me/donations", donationController.GetDonorDonations)
+	g.GET("/donors/me/donations/:id/receipt", donationReceiptController.GetDonorReceipt)
+	g.GET("/donors/me/subscriptions", donationSubscriptionController.GetDonorSubscriptions)
+
+	// Rekonsiliasi manual, laporan terakhir, refund, dan donasi offline khusus admin
+	g.POST("/donations/reconciliation", reconciliationController.RunReconciliation)
+	g.GET("/donations/reconciliation/last", reconciliationController.GetLastReconciliation)
+	g.POST("/donations/:id/refund", donationRefundController.RefundDonation)
+	g.GET("/donations/:id/refunds", donationRefundController.GetRefunds)
+	g.POST("/donations/offline", offlineDonationController.RecordOfflineDonation)
+	g.GET("/donations/transfer-proofs", offlineDonationController.GetTransferProofs)
+	g.POST("/donations/transfer-proofs/:id/approve", offlineDonationController.ApproveTransferProof)
+	g.POST("/donations/transfer-proofs/:id/reject", offlineDonationController.RejectTransferProof)
+	g.GET("/donations/subscriptions", donationSubscriptionController.GetSubscriptions)
+
+	// Notifikasi donasi masuk dengan status baca per admin
+	g.GET("/donations-notifikasi", donationNotificationController.GetNotifications)
 	g.GET("/donations-notifikasi-id/:id", donationController.GetNotifikasi)
+	g.GET("/donations-notifikasi/unread-count", donationNotificationController.CountUnread)
+	g.POST("/donations-notifikasi/read-all", donationNotificationController.MarkAllRead)
+	g.POST("/donations-notifikasi/:id/read", donationNotificationController.MarkRead)
+
+	// Antrean moderasi pesan donatur
+	g.GET("/donations/messages", donationMessageController.GetMessages)
+	g.POST("/donations/:id/message/approve", donationMessageController.ApproveMessage)
+	g.POST("/donations/:id/message/hide", donationMessageController.HideMessage)
+	g.PUT("/donations/:id/message", donationMessageController.EditMessage)
+
+	// Feed donasi real-time untuk dashboard admin. Token boleh dikirim lewat query ?token=
+	// karena EventSource di browser tidak bisa mengirim header Authorization.
+	g.GET("/donations/stream", donationFeedController.StreamAdmin)
+
+	// Direktori donatur: tinjau dugaan profil ganda, gabungkan profil, dan ekspor
+	g.GET("/donors/profiles", donorDirectoryController.GetProfiles)
+	g.GET("/donors/profiles/export", donorDirectoryController.ExportProfiles)
+	g.POST("/donors/profiles/:id/merge", donorDirectoryController.MergeProfiles)
+	g.GET("/donors/duplicates", donorDirectoryController.GetDuplicates)
+	g.POST("/donors/duplicates/:id/dismiss", donorDirectoryController.DismissDuplicate)
 }
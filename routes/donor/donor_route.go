@@ -0,0 +1,53 @@
+package donor
+
+import (
+	"tugas-akhir/config"
+	"tugas-akhir/controllers"
+	"tugas-akhir/drivers/mail"
+	"tugas-akhir/drivers/whatsapp"
+	"tugas-akhir/repositories"
+	"tugas-akhir/usecases"
+	"tugas-akhir/utils/mailtemplate"
+	"tugas-akhir/utils/password"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+	"tugas-akhir/utils/watemplate"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func InitDonorRoute(g *echo.Group, db *gorm.DB, v *validation.Validator) {
+	tokenUtil := token.NewTokenUtil()
+	mailConfig := config.InitConfigMail()
+	mailTransport := mail.NewTransport(mailConfig)
+	mailRenderer := mailtemplate.NewRenderer(mailConfig.FromName)
+	whatsAppConfig := config.InitConfigWhatsApp()
+	whatsAppRenderer := watemplate.NewRenderer(mailConfig.FromName)
+
+	userRepo := repositories.NewUserRepository(db)
+	verificationRepo := repositories.NewDonorVerificationRepository(db)
+	donationRepo := repositories.NewDonationRepository(db)
+	subscriptionRepo := repositories.NewDonationSubscriptionRepository(db)
+	transactionManager := repositories.NewTransactionManager(db)
+	whatsAppRepo := repositories.NewWhatsAppRepository(db)
+	programDonationRepo := repositories.NewProgramDonationRepository(db)
+
+	// Kode verifikasi nomor dikirim lewat WhatsApp
+	whatsAppUsecase := usecases.NewWhatsAppUsecase(whatsapp.NewProvider(whatsAppConfig), whatsAppRenderer, whatsAppRepo, donationRepo, programDonationRepo, userRepo, transactionManager, whatsAppConfig)
+
+	sessionUsecase := usecases.NewSessionUsecase(repositories.NewSessionRepository(db), repositories.NewAdminRepository(db), userRepo, transactionManager, tokenUtil)
+	donorUsecase := usecases.NewDonorUsecase(userRepo, verificationRepo, donationRepo, subscriptionRepo, transactionManager, password.NewPasswordUtil(), sessionUsecase, usecases.NewDonorNotifier(mailTransport, mailRenderer, whatsAppUsecase))
+	donorController := controllers.NewDonorController(donorUsecase, v, tokenUtil)
+
+	// Public routes
+	g.POST("/donors/register", donorController.Register)
+	g.POST("/donors/login", donorController.Login)
+	g.POST("/donors/verify-email", donorController.VerifyEmail)
+	g.POST("/donors/resend-verification", donorController.ResendVerification)
+
+	// Akun donatur yang sedang login. Riwayat donasi dan donasi rutin ada di route donasi.
+	g.GET("/donors/me", donorController.GetProfile)
+	g.POST("/donors/me/phone-verification", donorController.RequestPhoneVerification)
+	g.POST("/donors/me/verify-phone", donorController.VerifyPhone)
+}
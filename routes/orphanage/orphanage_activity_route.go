@@ -4,13 +4,10 @@ import (
 	"tugas-akhir/config"
 	"tugas-akhir/controllers"
 	"tugas-akhir/drivers/cloudinary"
-	"tugas-akhir/middlewares"
 	"tugas-akhir/repositories"
 	"tugas-akhir/usecases"
-	"tugas-akhir/utils/token"
 	"tugas-akhir/utils/validation"
 
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
@@ -28,7 +25,4 @@ func InitActivityRoute(g *echo.Group, db *gorm.DB, v *validation.Validator) {
 	g.POST("/activities", activityController.CreateActivity)
 	g.PUT("/activities/:id", activityController.UpdateActivity)
 	g.DELETE("/activities/:id", activityController.DeleteActivity)
-	
-	g.Use(echojwt.WithConfig(token.GetJWTConfig()), middlewares.IsAdmin)
-	
 }
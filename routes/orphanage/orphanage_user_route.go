@@ -4,13 +4,10 @@ import (
 	"tugas-akhir/config"
 	"tugas-akhir/controllers"
 	"tugas-akhir/drivers/cloudinary"
-	"tugas-akhir/middlewares"
 	"tugas-akhir/repositories"
 	"tugas-akhir/usecases"
-	"tugas-akhir/utils/token"
 	"tugas-akhir/utils/validation"
 
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
@@ -30,8 +27,6 @@ func InitOrphanageUserRoute(g *echo.Group, db *gorm.DB, v *validation.Validator)
 	g.POST("/users", orphanageUserController.CreateUser)
 	g.PUT("/users/:id", orphanageUserController.UpdateOrphanageUser)
 	g.DELETE("/users/:id", orphanageUserController.DeleteOrphanageUser)
-	
-	g.Use(echojwt.WithConfig(token.GetJWTConfig()), middlewares.IsAdmin)
 
 
 }
@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"net/http"
+	"tugas-akhir/entities"
+	"tugas-akhir/middlewares"
+	"tugas-akhir/utils/token"
+)
+
+// Prefix semua route API
+const apiPrefix = "/api/v1"
+
+// routePolicies adalah satu-satunya daftar aturan akses endpoint dan diterapkan oleh
+// middlewares.EnforcePolicies. Setiap route baru wajib didaftarkan di sini, route yang
+// terdaftar di Echo tanpa policy akan selalu ditolak.
+var routePolicies = middlewares.RoutePolicies{
+	// Data donatur lama
+	route(http.MethodPost, "/donation"): middlewares.Public(),
+	route(http.MethodGet, "/donation"):  middlewares.Permission(entities.PermissionDonorRead),
+
+	// Kegiatan panti
+	route(http.MethodGet, "/activities"):        middlewares.Public(),
+	route(http.MethodGet, "/activities/:id"):    middlewares.Public(),
+	route(http.MethodPost, "/activities"):       middlewares.Permission(entities.PermissionContentManage),
+	route(http.MethodPut, "/activities/:id"):    middlewares.Permission(entities.PermissionContentManage),
+	route(http.MethodDelete, "/activities/:id"): middlewares.Permission(entities.PermissionContentManage),
+
+	// Pengurus dan anak panti
+	route(http.MethodGet, "/users"):                    middlewares.Public(),
+	route(http.MethodGet, "/users/:id"):                middlewares.Public(),
+	route(http.MethodGet, "/users/position/:position"): middlewares.Public(),
+	route(http.MethodPost, "/users"):                   middlewares.Permission(entities.PermissionContentManage),
+	route(http.MethodPut, "/users/:id"):                middlewares.Permission(entities.PermissionContentManage),
+	route(http.MethodDelete, "/users/:id"):             middlewares.Permission(entities.PermissionContentManage),
+
+	// Sesi login donatur dan admin
+	route(http.MethodPost, "/auth/refresh"):    middlewares.Public(),
+	route(http.MethodPost, "/auth/logout"):     middlewares.Authenticated(),
+	route(http.MethodPost, "/auth/logout-all"): middlewares.Authenticated(),
+
+	// Akun admin. Register menerima token undangan, bukan pendaftaran terbuka.
+	route(http.MethodPost, "/admin/login"):      middlewares.Public(),
+	route(http.MethodPost, "/admin/register"):   middlewares.Public(),
+	route(http.MethodPut, "/admin/me/password"): middlewares.Admin(),
+
+	// Pengelolaan akun admin
+	route(http.MethodGet, "/admin/admins"):                 middlewares.Permission(entities.PermissionAdminManage),
+	route(http.MethodPost, "/admin/invitations"):           middlewares.Permission(entities.PermissionAdminManage),
+	route(http.MethodPut, "/admin/admins/:id"):             middlewares.Permission(entities.PermissionAdminManage),
+	route(http.MethodPost, "/admin/admins/:id/deactivate"): middlewares.Permission(entities.PermissionAdminManage),
+	route(http.MethodPost, "/admin/admins/:id/activate"):   middlewares.Permission(entities.PermissionAdminManage),
+	route(http.MethodDelete, "/admin/admins/:id"):          middlewares.Permission(entities.PermissionAdminManage),
+
+	// Role dan hak akses admin
+	route(http.MethodGet, "/admin/roles"):                   middlewares.Permission(entities.PermissionRoleManage),
+	route(http.MethodGet, "/admin/permissions"):             middlewares.Permission(entities.PermissionRoleManage),
+	route(http.MethodPut, "/admin/roles/:name/permissions"): middlewares.Permission(entities.PermissionRoleManage),
+	route(http.MethodPut, "/admin/admins/:id/role"):         middlewares.Permission(entities.PermissionRoleManage),
+
+	// Program donasi
+	route(http.MethodGet, "/program-donations"):        middlewares.Public(),
+	route(http.MethodGet, "/program-donations/:id"):    middlewares.Public(),
+	route(http.MethodGet, "/dashboard-donations"):      middlewares.Public(),
+	route(http.MethodPost, "/program-donations"):       middlewares.Permission(entities.PermissionContentManage),
+	route(http.MethodPut, "/program-donations/:id"):    middlewares.Permission(entities.PermissionContentManage),
+	route(http.MethodDelete, "/program-donations/:id"): middlewares.Permission(entities.PermissionContentManage),
+
+	// Donasi publik. Donasi dari donatur yang login tercatat di akunnya.
+	route(http.MethodPost, "/donations"):                          middlewares.Optional(),
+	route(http.MethodPost, "/donations/:id/resume"):               middlewares.Public(),
+	route(http.MethodPost, "/donations/:id/transfer-proof"):       middlewares.Public(),
+	route(http.MethodPost, "/donations/subscriptions"):            middlewares.Optional(),
+	route(http.MethodPost, "/donations/subscriptions/:id/pause"):  middlewares.Public(),
+	route(http.MethodPost, "/donations/subscriptions/:id/resume"): middlewares.Public(),
+	route(http.MethodPost, "/donations/subscriptions/:id/cancel"): middlewares.Public(),
+	route(http.MethodPost, "/midtrans-webhook"):                   middlewares.Public(),
+	route(http.MethodGet, "/donations/:id/public"):                middlewares.Public(),
+	route(http.MethodGet, "/donations/receipts/verify"):           middlewares.Public(),
+	route(http.MethodGet, "/donations-user"):                      middlewares.Public(),
+	route(http.MethodGet, "/donations-chart"):                     middlewares.Public(),
+	route(http.MethodGet, "/donations-program/:id"):               middlewares.Public(),
+	route(http.MethodGet, "/donations/prayers"):                   middlewares.Public(),
+	route(http.MethodGet, "/donations/stream/public"):             middlewares.Public(),
+
+	// Pengelolaan donasi oleh admin, dibatasi hak akses role admin
+	route(http.MethodGet, "/donations-all"):                          middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodGet, "/donations/:id"):                          middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodPost, "/donations/reconciliation"):              middlewares.Permission(entities.PermissionDonationManage),
+	route(http.MethodGet, "/donations/reconciliation/last"):          middlewares.Permission(entities.PermissionReportRead),
+	route(http.MethodPost, "/donations/:id/refund"):                  middlewares.Permission(entities.PermissionRefundManage),
+	route(http.MethodGet, "/donations/:id/refunds"):                  middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodGet, "/donations/:id/receipt"):                  middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodPost, "/donations/offline"):                     middlewares.Permission(entities.PermissionDonationManage),
+	route(http.MethodGet, "/donations/transfer-proofs"):              middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodPost, "/donations/transfer-proofs/:id/approve"): middlewares.Permission(entities.PermissionDonationManage),
+	route(http.MethodPost, "/donations/transfer-proofs/:id/reject"):  middlewares.Permission(entities.PermissionDonationManage),
+	route(http.MethodGet, "/donations/subscriptions"):                middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodGet, "/donations-notifikasi"):                   middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodGet, "/donations-notifikasi-id/:id"):            middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodGet, "/donations-notifikasi/unread-count"):      middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodPost, "/donations-notifikasi/read-all"):         middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodPost, "/donations-notifikasi/:id/read"):         middlewares.Permission(entities.PermissionDonationRead),
+	route(http.MethodGet, "/donations/messages"):                     middlewares.Permission(entities.PermissionMessageModerate),
+	route(http.MethodPost, "/donations/:id/message/approve"):         middlewares.Permission(entities.PermissionMessageModerate),
+	route(http.MethodPost, "/donations/:id/message/hide"):            middlewares.Permission(entities.PermissionMessageModerate),
+	route(http.MethodPut, "/donations/:id/message"):                  middlewares.Permission(entities.PermissionMessageModerate),
+	route(http.MethodGet, "/donations/stream"):                       middlewares.Permission(entities.PermissionDonationRead).WithJWTConfig(token.GetStreamJWTConfig()),
+	route(http.MethodGet, "/donors/profiles"):                        middlewares.Permission(entities.PermissionDonorRead),
+	route(http.MethodGet, "/donors/profiles/export"):                 middlewares.Permission(entities.PermissionReportRead),
+	route(http.MethodPost, "/donors/profiles/:id/merge"):             middlewares.Permission(entities.PermissionDonorManage),
+	route(http.MethodGet, "/donors/duplicates"):                      middlewares.Permission(entities.PermissionDonorRead),
+	route(http.MethodPost, "/donors/duplicates/:id/dismiss"):         middlewares.Permission(entities.PermissionDonorManage),
+
+	// Akun donatur
+	route(http.MethodPost, "/donors/register"):                middlewares.Public(),
+	route(http.MethodPost, "/donors/login"):                   middlewares.Public(),
+	route(http.MethodPost, "/donors/verify-email"):            middlewares.Public(),
+	route(http.MethodPost, "/donors/resend-verification"):     middlewares.Public(),
+	route(http.MethodGet, "/donors/me"):                       middlewares.Donor(),
+	route(http.MethodPost, "/donors/me/phone-verification"):   middlewares.Donor(),
+	route(http.MethodPost, "/donors/me/verify-phone"):         middlewares.Donor(),
+	route(http.MethodGet, "/donors/me/donations"):             middlewares.Donor(),
+	route(http.MethodGet, "/donors/me/donations/:id/receipt"): middlewares.Donor(),
+	route(http.MethodGet, "/donors/me/subscriptions"):         middlewares.Donor(),
+	route(http.MethodGet, "/donors/me/whatsapp"):              middlewares.Donor(),
+	route(http.MethodPut, "/donors/me/whatsapp"):              middlewares.Donor(),
+
+	// WhatsApp. Webhook gateway diamankan dengan header X-Webhook-Token.
+	route(http.MethodPost, "/whatsapp/webhook"): middlewares.Public(),
+	route(http.MethodGet, "/whatsapp/messages"): middlewares.Permission(entities.PermissionDonorRead),
+}
+
+func route(method string, path string) middlewares.RouteKey {
+	return middlewares.RouteKey{Method: method, Path: apiPrefix + path}
+}
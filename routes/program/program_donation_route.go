@@ -4,13 +4,10 @@ import (
 	"tugas-akhir/config"
 	"tugas-akhir/controllers"
 	"tugas-akhir/drivers/cloudinary"
-	"tugas-akhir/middlewares"
 	"tugas-akhir/repositories"
 	"tugas-akhir/usecases"
 	"tugas-akhir/utils/validation"
-	"tugas-akhir/utils/token"
 
-	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
@@ -30,6 +27,4 @@ func InitProgramDonationRoute(g *echo.Group, db *gorm.DB, v *validation.Validato
 	g.POST("/program-donations", programDonationController.CreateProgramDonation)
 	g.PUT("/program-donations/:id", programDonationController.UpdateProgramDonation)
 	g.DELETE("/program-donations/:id", programDonationController.DeleteProgramDonation)
-	
-	g.Use(echojwt.WithConfig(token.GetJWTConfig()), middlewares.IsAdmin)
 }
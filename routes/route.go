@@ -1,32 +1,63 @@
 package routes
 
 import (
+	"tugas-akhir/middlewares"
+	"tugas-akhir/repositories"
+	"tugas-akhir/usecases"
+	"tugas-akhir/utils/token"
 	"tugas-akhir/utils/validation"
 
 	"tugas-akhir/routes/admin"
+	"tugas-akhir/routes/auth"
 	"tugas-akhir/routes/donation"
+	"tugas-akhir/routes/donor"
 	"tugas-akhir/routes/orphanage"
 	"tugas-akhir/routes/user"
 	"tugas-akhir/routes/program"
+	"tugas-akhir/routes/whatsapp"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
 func InitRoute(e *echo.Echo, db *gorm.DB, v *validation.Validator) {
-	baseRoute := e.Group("/api/v1")
+	// Aturan akses semua route diterapkan dari satu tabel di policy.go. Hak akses role
+	// admin dibaca dari database lewat RoleUsecase, sesi yang sudah dicabut ditolak lewat
+	// SessionUsecase.
+	sessionUsecase := usecases.NewSessionUsecase(
+		repositories.NewSessionRepository(db),
+		repositories.NewAdminRepository(db),
+		repositories.NewUserRepository(db),
+		repositories.NewTransactionManager(db),
+		token.NewTokenUtil(),
+	)
+	roleUsecase := usecases.NewRoleUsecase(
+		repositories.NewRoleRepository(db),
+		repositories.NewAdminRepository(db),
+		sessionUsecase,
+		repositories.NewTransactionManager(db),
+	)
+	e.Use(middlewares.EnforcePolicies(routePolicies, roleUsecase, sessionUsecase))
+
+	baseRoute := e.Group(apiPrefix)
 
 	userRoute := baseRoute.Group("")
 	activityRoute := baseRoute.Group("")
 	orphanageUserRoute := baseRoute.Group("")
+	authRoute := baseRoute.Group("")
 	adminRoute := baseRoute.Group("")
 	programDonationRoute := baseRoute.Group("")
 	donationRoute := baseRoute.Group("")
+	donorRoute := baseRoute.Group("")
+	whatsAppRoute := baseRoute.Group("")
 
 	user.InitUserRoute(userRoute, db, v)
 	orphanage.InitActivityRoute(activityRoute, db, v)
 	orphanage.InitOrphanageUserRoute(orphanageUserRoute, db, v)
+	auth.InitAuthRoute(authRoute, db, v)
 	admin.InitAdminRoute(adminRoute, db, v)
 	program.InitProgramDonationRoute(programDonationRoute, db, v)
 	donation.InitDonationRoute(donationRoute, db, v)
+	donor.InitDonorRoute(donorRoute, db, v)
+	whatsapp.InitWhatsAppRoute(whatsAppRoute, db, v)
 }
@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"tugas-akhir/entities"
+	"tugas-akhir/middlewares"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestServer mendaftarkan semua route dengan database dry run dan FakeGateway,
+// sehingga tidak membutuhkan Postgres, Redis, maupun Midtrans. Query dry run tidak
+// mengembalikan baris, kecuali pemeriksaan sesi aktif yang dijawab dari sessions.
+func newTestServer(t *testing.T, sessions ...entities.Session) *echo.Echo {
+	t.Helper()
+	t.Setenv("PAYMENT_GATEWAY", "fake")
+	t.Setenv("JWT_KEY", "test-jwt-key")
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("test:sessions", activeSessionCount(sessions)); err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	InitRoute(e, db, validation.NewValidator())
+	return e
+}
+
+// activeSessionCount mengisi hasil hitung sesi aktif pada SessionRepository.IsRevoked
+// sesuai sesi yang disemai, memakai Session.IsActive sebagai aturan sesi aktif
+func activeSessionCount(sessions []entities.Session) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		count, ok := tx.Statement.Dest.(*int64)
+		if !ok || tx.Statement.Table != "sessions" || len(tx.Statement.Vars) == 0 {
+			return
+		}
+		for _, session := range sessions {
+			if tx.Statement.Vars[0] == session.ID && session.IsActive(time.Now()) {
+				// Count memakai RowsAffected sebagai hasil jika nilainya bukan 1
+				*count, tx.RowsAffected = 1, 1
+			}
+		}
+	}
+}
+
+// newSessionToken membuat access token beserta sesi login pemiliknya
+func newSessionToken(t *testing.T, role string, expiresAt time.Time, revokedAt *time.Time) (string, entities.Session) {
+	t.Helper()
+	session := entities.Session{
+		ID:        uuid.New(),
+		SubjectID: uuid.New(),
+		Role:      role,
+		ExpiresAt: expiresAt,
+		RevokedAt: revokedAt,
+	}
+	accessToken, _, err := token.NewTokenUtil().GenerateToken(session.SubjectID, role, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return accessToken, session
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func TestEveryRouteHasPolicy(t *testing.T) {
+	e := newTestServer(t)
+
+	registered := map[middlewares.RouteKey]bool{}
+	for _, r := range e.Routes() {
+		key := middlewares.RouteKey{Method: r.Method, Path: r.Path}
+		registered[key] = true
+
+		if _, ok := routePolicies[key]; !ok {
+			if isMutating(r.Method) {
+				t.Errorf("mutating route %s %s has no policy in routes/policy.go", r.Method, r.Path)
+			} else {
+				t.Errorf("route %s %s has no policy in routes/policy.go", r.Method, r.Path)
+			}
+		}
+	}
+
+	for key := range routePolicies {
+		if !registered[key] {
+			t.Errorf("policy for %s %s does not match any registered route", key.Method, key.Path)
+		}
+	}
+}
+
+func TestPoliciesAreEnforced(t *testing.T) {
+	// JWT_KEY dipasang lebih dulu karena token dibuat sebelum server test
+	t.Setenv("JWT_KEY", "test-jwt-key")
+
+	sessionExpiry := time.Now().Add(time.Hour)
+	donorToken, donorSession := newSessionToken(t, entities.RoleDonor, sessionExpiry, nil)
+	viewerToken, viewerSession := newSessionToken(t, entities.RoleViewer, sessionExpiry, nil)
+	superAdminToken, superAdminSession := newSessionToken(t, entities.RoleSuperAdmin, sessionExpiry, nil)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	revokedToken, revokedSession := newSessionToken(t, entities.RoleSuperAdmin, sessionExpiry, &revokedAt)
+	expiredToken, expiredSession := newSessionToken(t, entities.RoleSuperAdmin, time.Now().Add(-time.Minute), nil)
+	// Sesi yang tidak tersimpan, misalnya sudah dihapus, diperlakukan seperti sesi dicabut
+	unknownToken, _ := newSessionToken(t, entities.RoleSuperAdmin, sessionExpiry, nil)
+
+	e := newTestServer(t, donorSession, viewerSession, superAdminSession, revokedSession, expiredSession)
+
+	// Token lama tanpa sesi tidak bisa dicabut sehingga selalu ditolak
+	sessionlessToken, _, err := token.NewTokenUtil().GenerateToken(uuid.New(), entities.RoleSuperAdmin, uuid.Nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"admin route without token", http.MethodPost, "/api/v1/activities", "", http.StatusUnauthorized},
+		{"admin route with donor token", http.MethodDelete, "/api/v1/program-donations/" + uuid.NewString(), donorToken, http.StatusForbidden},
+		{"permission route without stored permission", http.MethodPut, "/api/v1/admin/admins/" + uuid.NewString() + "/role", viewerToken, http.StatusForbidden},
+		{"permission route as super admin", http.MethodGet, "/api/v1/admin/permissions", superAdminToken, http.StatusOK},
+		{"admin registration without invitation", http.MethodPost, "/api/v1/admin/register", "", http.StatusBadRequest},
+		{"admin management without stored permission", http.MethodGet, "/api/v1/admin/admins", viewerToken, http.StatusForbidden},
+		{"donor route without token", http.MethodGet, "/api/v1/donors/me", "", http.StatusUnauthorized},
+		{"receipt without token", http.MethodGet, "/api/v1/donations/" + uuid.NewString() + "/receipt", "", http.StatusUnauthorized},
+		{"receipt with donor token", http.MethodGet, "/api/v1/donations/" + uuid.NewString() + "/receipt", donorToken, http.StatusForbidden},
+		{"admin donation detail without token", http.MethodGet, "/api/v1/donations/" + uuid.NewString(), "", http.StatusUnauthorized},
+		{"admin donation detail with donor token", http.MethodGet, "/api/v1/donations/" + uuid.NewString(), donorToken, http.StatusForbidden},
+		{"gateway notification without token", http.MethodGet, "/api/v1/donations-notifikasi-id/" + uuid.NewString(), "", http.StatusUnauthorized},
+		{"gateway notification with donor token", http.MethodGet, "/api/v1/donations-notifikasi-id/" + uuid.NewString(), donorToken, http.StatusForbidden},
+		{"token without session", http.MethodGet, "/api/v1/admin/permissions", sessionlessToken, http.StatusUnauthorized},
+		{"token of revoked session", http.MethodGet, "/api/v1/admin/permissions", revokedToken, http.StatusUnauthorized},
+		{"token of expired session", http.MethodGet, "/api/v1/admin/permissions", expiredToken, http.StatusUnauthorized},
+		{"token of unknown session", http.MethodGet, "/api/v1/admin/permissions", unknownToken, http.StatusUnauthorized},
+		{"logout without token", http.MethodPost, "/api/v1/auth/logout", "", http.StatusUnauthorized},
+		{"refresh without refresh token", http.MethodPost, "/api/v1/auth/refresh", "", http.StatusBadRequest},
+		{"unknown path", http.MethodGet, "/api/v1/unknown", "", http.StatusNotFound},
+		{"unknown method", http.MethodPatch, "/api/v1/activities", "", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("%s %s returned %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
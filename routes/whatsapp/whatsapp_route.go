@@ -0,0 +1,50 @@
+package whatsapp
+
+import (
+	"context"
+	"tugas-akhir/config"
+	"tugas-akhir/controllers"
+	"tugas-akhir/drivers/whatsapp"
+	"tugas-akhir/repositories"
+	"tugas-akhir/usecases"
+	"tugas-akhir/utils/scheduler"
+	"tugas-akhir/utils/token"
+	"tugas-akhir/utils/validation"
+	"tugas-akhir/utils/watemplate"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func InitWhatsAppRoute(g *echo.Group, db *gorm.DB, v *validation.Validator) {
+	whatsAppConfig := config.InitConfigWhatsApp()
+	mailConfig := config.InitConfigMail()
+
+	whatsAppRepo := repositories.NewWhatsAppRepository(db)
+	donationRepo := repositories.NewDonationRepository(db)
+	programDonationRepo := repositories.NewProgramDonationRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	transactionManager := repositories.NewTransactionManager(db)
+
+	whatsAppUsecase := usecases.NewWhatsAppUsecase(whatsapp.NewProvider(whatsAppConfig), watemplate.NewRenderer(mailConfig.FromName), whatsAppRepo, donationRepo, programDonationRepo, userRepo, transactionManager, whatsAppConfig)
+	whatsAppController := controllers.NewWhatsAppController(whatsAppUsecase, v, token.NewTokenUtil(), whatsAppConfig.WebhookToken)
+
+	// Worker yang mengirim antrean pesan WhatsApp ke gateway
+	scheduler.Every(context.Background(), "whatsapp-dispatch", whatsAppConfig.DispatchInterval, func(ctx context.Context) {
+		whatsAppUsecase.DispatchMessages(ctx)
+	})
+
+	// Worker kabar perkembangan program bulanan untuk donatur
+	scheduler.Every(context.Background(), "whatsapp-program-update", whatsAppConfig.UpdateInterval, func(ctx context.Context) {
+		whatsAppUsecase.SendProgramUpdates(ctx)
+	})
+
+	// Webhook gateway, diamankan dengan header X-Webhook-Token
+	g.POST("/whatsapp/webhook", whatsAppController.Webhook)
+
+	// Preferensi WhatsApp donatur yang sedang login
+	g.GET("/donors/me/whatsapp", whatsAppController.GetPreference)
+	g.PUT("/donors/me/whatsapp", whatsAppController.UpdatePreference)
+
+	g.GET("/whatsapp/messages", whatsAppController.GetMessages)
+}
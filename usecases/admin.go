@@ -2,88 +2,435 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/mail"
 	dto "tugas-akhir/dto/admin"
+	dto_base "tugas-akhir/dto/base"
 	"tugas-akhir/entities"
 	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+	"tugas-akhir/utils/mailtemplate"
 	"tugas-akhir/utils/password"
-	"tugas-akhir/utils/token"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
-	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
+// AdminNotifier mengirim undangan akun admin
+type AdminNotifier interface {
+	SendInvitation(ctx context.Context, invitation *entities.AdminInvitation, invitedBy string, token string) error
+}
+
+type adminNotifier struct {
+	transport   mail.Transport
+	renderer    *mailtemplate.Renderer
+	adminConfig config.AdminConfig
+}
+
+func NewAdminNotifier(transport mail.Transport, renderer *mailtemplate.Renderer, adminConfig config.AdminConfig) AdminNotifier {
+	return &adminNotifier{
+		transport:   transport,
+		renderer:    renderer,
+		adminConfig: adminConfig,
+	}
+}
+
+// SendInvitation mengirim token undangan. Jika ADMIN_INVITATION_URL diisi, token
+// ditambahkan sebagai query token pada tautan halaman penerimaan undangan.
+func (n *adminNotifier) SendInvitation(ctx context.Context, invitation *entities.AdminInvitation, invitedBy string, token string) error {
+	data := mailtemplate.AdminInvitationData{
+		Name:      invitation.Name,
+		Role:      invitation.Role,
+		InvitedBy: invitedBy,
+		Token:     token,
+		ValidFor:  fmt.Sprintf("%.0f jam", n.adminConfig.InvitationTTL.Hours()),
+	}
+
+	if n.adminConfig.InvitationURL != "" {
+		invitationURL, err := url.Parse(n.adminConfig.InvitationURL)
+		if err != nil {
+			return err
+		}
+		query := invitationURL.Query()
+		query.Set("token", token)
+		invitationURL.RawQuery = query.Encode()
+		data.URL = invitationURL.String()
+	}
+
+	return sendMail(ctx, n.transport, n.renderer, mailtemplate.AdminInvitation, []string{invitation.Email}, data, nil)
+}
+
 type AdminUseCase interface {
-	Register(c echo.Context, req *dto.RegisterRequest) error
+	Register(c echo.Context, req *dto.RegisterRequest) (*dto.AdminResponse, error)
 	Login(c echo.Context, req *dto.LoginRequest) (*dto.LoginResponse, error)
+	GetAdmins(c echo.Context, req *dto_base.PaginationRequest) (*[]dto.AdminResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
+	InviteAdmin(c echo.Context, actorID uuid.UUID, req *dto.InviteAdminRequest) (*dto.InvitationResponse, error)
+	UpdateAdmin(c echo.Context, id uuid.UUID, actorID uuid.UUID, req *dto.UpdateAdminRequest) (*dto.AdminResponse, error)
+	SetAdminActive(c echo.Context, id uuid.UUID, actorID uuid.UUID, active bool) (*dto.AdminResponse, error)
+	DeleteAdmin(c echo.Context, id uuid.UUID, actorID uuid.UUID) error
+	ChangePassword(c echo.Context, id uuid.UUID, sessionID uuid.UUID, req *dto.ChangePasswordRequest) error
 }
 
 type adminUsecase struct {
-	adminRepo    repositories.AdminRepository
-	passwordUtil password.PasswordUtil
-	tokenUtil    token.TokenUtil
+	adminRepo          repositories.AdminRepository
+	invitationRepo     repositories.AdminInvitationRepository
+	roleRepo           repositories.RoleRepository
+	roleUsecase        RoleUsecase
+	sessionUsecase     SessionUsecase
+	transactionManager repositories.TransactionManager
+	passwordUtil       password.PasswordUtil
+	notifier           AdminNotifier
+	adminConfig        config.AdminConfig
 }
 
-func NewAdminUsecase(adminRepo repositories.AdminRepository, passwordUtil password.PasswordUtil, tokenUtil token.TokenUtil) AdminUseCase {
+func NewAdminUsecase(adminRepo repositories.AdminRepository, invitationRepo repositories.AdminInvitationRepository, roleRepo repositories.RoleRepository, roleUsecase RoleUsecase, sessionUsecase SessionUsecase, transactionManager repositories.TransactionManager, passwordUtil password.PasswordUtil, notifier AdminNotifier, adminConfig config.AdminConfig) AdminUseCase {
 	return &adminUsecase{
-		adminRepo:    adminRepo,
-		passwordUtil: passwordUtil,
-		tokenUtil:    tokenUtil,
+		adminRepo:          adminRepo,
+		invitationRepo:     invitationRepo,
+		roleRepo:           roleRepo,
+		roleUsecase:        roleUsecase,
+		sessionUsecase:     sessionUsecase,
+		transactionManager: transactionManager,
+		passwordUtil:       passwordUtil,
+		notifier:           notifier,
+		adminConfig:        adminConfig,
 	}
 }
 
-func (au *adminUsecase) Register(c echo.Context, req *dto.RegisterRequest) error {
+// Register menerima undangan admin. Akun dibuat dengan email dan role dari undangan,
+// lalu token undangan tidak bisa dipakai lagi.
+func (au *adminUsecase) Register(c echo.Context, req *dto.RegisterRequest) (*dto.AdminResponse, error) {
 	ctx, cancel := context.WithCancel(c.Request().Context())
 	defer cancel()
 
-	log := logrus.New()
-
 	hashedPassword, err := au.passwordUtil.HashPassword(req.Password)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var admin entities.Admin
+	err = au.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		invitation, err := au.invitationRepo.FindActiveByTokenHash(ctx, hashSecretToken(req.Token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return err_util.ErrInvalidInvitation
+			}
+			return err
+		}
 
-	admin := &entities.Admin{
-		ID:       uuid.New(),
-		Name:     req.Name,
-		Email:    req.Email,
-		Role:     "admin",
-		Password: hashedPassword,
-	}
+		name := invitation.Name
+		if req.Name != "" {
+			name = req.Name
+		}
 
-	err = au.adminRepo.CreateAdmin(ctx, admin)
+		admin = entities.Admin{
+			ID:        uuid.New(),
+			Name:      name,
+			Email:     invitation.Email,
+			Role:      invitation.Role,
+			Password:  hashedPassword,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := au.adminRepo.CreateAdmin(ctx, &admin); err != nil {
+			return err
+		}
+
+		acceptedAt := time.Now()
+		invitation.AcceptedAt = &acceptedAt
+		return au.invitationRepo.Update(ctx, invitation)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, err_util.ErrEmailRegistered
+	}
 	if err != nil {
-		log.Error(err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	response := toAdminResponse(&admin)
+	return &response, nil
 }
 
 func (au *adminUsecase) Login(c echo.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
 	ctx, cancel := context.WithCancel(c.Request().Context())
 	defer cancel()
 
-	admin, err := au.adminRepo.GetAdmin(ctx, &entities.Admin{Email: req.Email})
+	admin, err := au.adminRepo.GetAdmin(ctx, &entities.Admin{Email: normalizeEmail(req.Email)})
 	if err != nil {
 		return nil, err
 	}
 	if err := au.passwordUtil.VerifyPassword(req.Password, admin.Password); err != nil {
 		return nil, err
 	}
+	if !admin.IsActive() {
+		return nil, err_util.ErrAdminDeactivated
+	}
 
-	var token string
+	session, err := au.sessionUsecase.CreateSession(c, admin.ID, admin.Role)
+	if err != nil {
+		return nil, err
+	}
 
-	token, err = au.tokenUtil.GenerateToken(admin.ID, admin.Role)
+	permissions, err := au.roleUsecase.GetPermissions(ctx, admin.Role)
 	if err != nil {
 		return nil, err
 	}
 
 	return &dto.LoginResponse{
-		Name:  admin.Name,
-		Email: admin.Email,
-		Token: token,
-		Role:  admin.Role,
+		ID:           admin.ID.String(),
+		Name:         admin.Name,
+		Email:        admin.Email,
+		Token:        session.Token,
+		ExpiresAt:    session.ExpiresAt,
+		RefreshToken: session.RefreshToken,
+		Role:         admin.Role,
+		Permissions:  permissions,
+	}, nil
+}
+
+func (au *adminUsecase) GetAdmins(c echo.Context, req *dto_base.PaginationRequest) (*[]dto.AdminResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
+	ctx := c.Request().Context()
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	admins, totalData, err := au.adminRepo.GetAdmins(ctx, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	totalPage := int(math.Ceil(float64(totalData) / float64(req.Limit)))
+	if totalData > 0 && req.Page > totalPage {
+		return nil, nil, nil, err_util.ErrPageNotFound
+	}
+
+	responses := []dto.AdminResponse{}
+	for i := range admins {
+		responses = append(responses, toAdminResponse(&admins[i]))
+	}
+
+	metadata, link := paginationResult(c, req, "", totalData, totalPage)
+	return &responses, metadata, link, nil
+}
+
+// InviteAdmin mengirim undangan ke email yang belum menjadi admin. Undangan role super
+// admin hanya bisa dibuat oleh super admin.
+func (au *adminUsecase) InviteAdmin(c echo.Context, actorID uuid.UUID, req *dto.InviteAdminRequest) (*dto.InvitationResponse, error) {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	actor, err := au.adminRepo.GetAdminByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := au.roleRepo.GetRole(ctx, req.Role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err_util.ErrUnknownRole
+		}
+		return nil, err
+	}
+	if req.Role == entities.RoleSuperAdmin && actor.Role != entities.RoleSuperAdmin {
+		return nil, err_util.ErrSuperAdminRequired
+	}
+
+	email := normalizeEmail(req.Email)
+	_, err = au.adminRepo.GetAdmin(ctx, &entities.Admin{Email: email})
+	if err == nil {
+		return nil, err_util.ErrEmailRegistered
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	secret, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := entities.AdminInvitation{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: hashSecretToken(secret),
+		InvitedBy: actor.ID,
+		ExpiresAt: time.Now().Add(au.adminConfig.InvitationTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := au.invitationRepo.CreateInvitation(ctx, &invitation); err != nil {
+		return nil, err
+	}
+
+	// Token hanya dikirim lewat email, jadi undangan yang gagal dikirim dilaporkan sebagai
+	// kegagalan agar admin bisa mengundang ulang
+	if err := au.notifier.SendInvitation(ctx, &invitation, actor.Name, secret); err != nil {
+		return nil, err
+	}
+
+	return &dto.InvitationResponse{
+		ID:        invitation.ID.String(),
+		Name:      invitation.Name,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
 	}, nil
 }
+
+func (au *adminUsecase) UpdateAdmin(c echo.Context, id uuid.UUID, actorID uuid.UUID, req *dto.UpdateAdminRequest) (*dto.AdminResponse, error) {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	admin, err := au.getManagedAdmin(ctx, id, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := normalizeEmail(req.Email)
+	if email != admin.Email {
+		_, err := au.adminRepo.GetAdmin(ctx, &entities.Admin{Email: email})
+		if err == nil {
+			return nil, err_util.ErrEmailRegistered
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	admin.Name = req.Name
+	admin.Email = email
+	admin.UpdatedAt = time.Now()
+	err = au.adminRepo.UpdateAdmin(ctx, admin.ID, &entities.Admin{Name: admin.Name, Email: admin.Email, UpdatedAt: admin.UpdatedAt})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, err_util.ErrEmailRegistered
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAdminResponse(admin)
+	return &response, nil
+}
+
+// SetAdminActive menonaktifkan atau mengaktifkan kembali akun admin lain. Admin yang
+// dinonaktifkan tidak bisa login dan semua sesinya langsung dicabut.
+func (au *adminUsecase) SetAdminActive(c echo.Context, id uuid.UUID, actorID uuid.UUID, active bool) (*dto.AdminResponse, error) {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	if id == actorID {
+		return nil, err_util.ErrCannotManageSelf
+	}
+
+	admin, err := au.getManagedAdmin(ctx, id, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	admin.DeactivatedAt = nil
+	if !active {
+		deactivatedAt := time.Now()
+		admin.DeactivatedAt = &deactivatedAt
+	}
+	err = au.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := au.adminRepo.SetDeactivatedAt(ctx, admin.ID, admin.DeactivatedAt); err != nil {
+			return err
+		}
+		if active {
+			return nil
+		}
+		return au.sessionUsecase.RevokeSessions(ctx, admin.ID, uuid.Nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAdminResponse(admin)
+	return &response, nil
+}
+
+func (au *adminUsecase) DeleteAdmin(c echo.Context, id uuid.UUID, actorID uuid.UUID) error {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	if id == actorID {
+		return err_util.ErrCannotManageSelf
+	}
+
+	admin, err := au.getManagedAdmin(ctx, id, actorID)
+	if err != nil {
+		return err
+	}
+
+	return au.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := au.sessionUsecase.RevokeSessions(ctx, admin.ID, uuid.Nil); err != nil {
+			return err
+		}
+		return au.adminRepo.DeleteAdmin(ctx, admin.ID)
+	})
+}
+
+// ChangePassword mengganti password admin yang sedang login setelah password lama diperiksa.
+// Sesi lain milik admin dicabut, sesi yang sedang dipakai tetap berlaku.
+func (au *adminUsecase) ChangePassword(c echo.Context, id uuid.UUID, sessionID uuid.UUID, req *dto.ChangePasswordRequest) error {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	admin, err := au.adminRepo.GetAdminByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := au.passwordUtil.VerifyPassword(req.OldPassword, admin.Password); err != nil {
+		return err_util.ErrInvalidCurrentPassword
+	}
+
+	hashedPassword, err := au.passwordUtil.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	return au.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := au.adminRepo.UpdateAdmin(ctx, admin.ID, &entities.Admin{Password: hashedPassword, UpdatedAt: time.Now()}); err != nil {
+			return err
+		}
+		return au.sessionUsecase.RevokeSessions(ctx, admin.ID, sessionID)
+	})
+}
+
+// getManagedAdmin mengambil admin yang akan dikelola. Akun super admin hanya bisa
+// dikelola oleh super admin lain.
+func (au *adminUsecase) getManagedAdmin(ctx context.Context, id uuid.UUID, actorID uuid.UUID) (*entities.Admin, error) {
+	admin, err := au.adminRepo.GetAdminByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if admin.Role != entities.RoleSuperAdmin {
+		return admin, nil
+	}
+
+	actor, err := au.adminRepo.GetAdminByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != entities.RoleSuperAdmin {
+		return nil, err_util.ErrSuperAdminRequired
+	}
+	return admin, nil
+}
+
+func toAdminResponse(admin *entities.Admin) dto.AdminResponse {
+	return dto.AdminResponse{
+		ID:        admin.ID.String(),
+		Name:      admin.Name,
+		Email:     admin.Email,
+		Role:      admin.Role,
+		Active:    admin.IsActive(),
+		CreatedAt: admin.CreatedAt,
+	}
+}
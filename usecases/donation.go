@@ -7,32 +7,52 @@ import (
 	"math"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 	"tugas-akhir/config"
+	"tugas-akhir/drivers/payment"
 	dto_base "tugas-akhir/dto/base"
 	dto "tugas-akhir/dto/donation"
 	"tugas-akhir/entities"
 	"tugas-akhir/repositories"
 	err_util "tugas-akhir/utils/error"
-	midtran "tugas-akhir/utils/midtrans"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/snap"
 	"github.com/sirupsen/logrus"
 )
 
 type DonationUsecase interface {
 	CreateDonation(c echo.Context, request dto.DonationRequest) (dto.DonationResponse, error)
-	UpdateDonationStatus(c echo.Context, notification midtran.Notification) error
-	GetDonations(c echo.Context, programDonationID uuid.UUID, searchName string, req *dto_base.PaginationRequest) (*[]dto.DonationResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
+	ResumeDonation(c echo.Context, donationID uuid.UUID) (dto.DonationResumeResponse, error)
+	CreateSubscriptionDonation(ctx context.Context, subscription *entities.DonationSubscription) (*entities.Donation, error)
+	ChargeSubscriptionDonation(ctx context.Context, subscription *entities.DonationSubscription, donationID uuid.UUID) (*entities.Donation, error)
+	UpdateDonationStatus(c echo.Context, notification payment.TransactionStatus) error
+	ApplyTransactionStatus(ctx context.Context, status payment.TransactionStatus) (*DonationStatusChange, error)
+	RecordTransactionStatus(ctx context.Context, status payment.TransactionStatus) (*DonationStatusChange, error)
+	NotifyStatusChange(ctx context.Context, change *DonationStatusChange)
+	GetDonations(c echo.Context, programDonationID uuid.UUID, searchName string, includeExpired bool, req *dto_base.PaginationRequest) (*[]dto.DonationResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
 	GetDonationByID(c echo.Context, donationID uuid.UUID) (*dto.DonationResponse, error)
+	GetPublicDonation(c echo.Context, donationID uuid.UUID) (*dto.DonationPublicResponse, error)
     GetDonationLanding(c echo.Context) (*[]dto.DonationLandingResponse, error)
     GetChartDonation(c echo.Context) (*[]dto.DonationChartResponse, error)
-    GetDonaturNotifikasi(c echo.Context) (*[]dto.DonaturNotifikasiResponse, error)
     GetDonaturByProgramDonation(c echo.Context, programDonationID uuid.UUID) (*[]dto.DonationLandingResponse, error)
     GetNotifikasiStatus(c echo.Context, donationID uuid.UUID) (*entities.TransactionNotification, error) 
+	GetDonorDonations(c echo.Context, userID uuid.UUID, req *dto_base.PaginationRequest) (*[]dto.DonationHistoryResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
+}
+
+// DonationStatusChange adalah hasil penerapan status transaksi gateway ke sebuah donasi.
+// DuplicateOrderID diisi jika donasi yang sudah lunas dibayar lagi lewat order lain,
+// sehingga pembayaran kedua harus dikembalikan admin.
+type DonationStatusChange struct {
+	DonationID       uuid.UUID
+	From             entities.DonationStatus
+	To               entities.DonationStatus
+	DuplicateOrderID string
+}
+
+func (sc DonationStatusChange) Changed() bool {
+	return sc.From != sc.To
 }
 
 func init() {
@@ -43,17 +63,29 @@ func init() {
 
 type donationUsecase struct {
 	donationRepository                repositories.DonationRepository
-	config                            config.MidtransConfig
+	paymentGateway                    payment.PaymentGateway
 	programDonation                   repositories.ProgramDonationRepository
 	transactionNotificationRepository repositories.TransactionNotificationRepository
+	transactionManager                repositories.TransactionManager
+	donationAllocationRepository      repositories.DonationAllocationRepository
+	donationReceiptUsecase            DonationReceiptUsecase
+	donationFeedUsecase               DonationFeedUsecase
+	donationMailer                    DonationMailer
+	whatsAppUsecase                   WhatsAppUsecase
 }
 
-func NewDonationUsecase(donationRepository repositories.DonationRepository, config config.MidtransConfig, programDonation repositories.ProgramDonationRepository, transactionNotificationRepository repositories.TransactionNotificationRepository) DonationUsecase {
+func NewDonationUsecase(donationRepository repositories.DonationRepository, paymentGateway payment.PaymentGateway, programDonation repositories.ProgramDonationRepository, transactionNotificationRepository repositories.TransactionNotificationRepository, transactionManager repositories.TransactionManager, donationAllocationRepository repositories.DonationAllocationRepository, donationReceiptUsecase DonationReceiptUsecase, donationFeedUsecase DonationFeedUsecase, donationMailer DonationMailer, whatsAppUsecase WhatsAppUsecase) DonationUsecase {
 	return &donationUsecase{
 		donationRepository:                donationRepository,
-		config:                            config,
+		paymentGateway:                    paymentGateway,
 		programDonation:                   programDonation,
 		transactionNotificationRepository: transactionNotificationRepository,
+		transactionManager:                transactionManager,
+		donationAllocationRepository:      donationAllocationRepository,
+		donationReceiptUsecase:            donationReceiptUsecase,
+		donationFeedUsecase:               donationFeedUsecase,
+		donationMailer:                    donationMailer,
+		whatsAppUsecase:                   whatsAppUsecase,
 	}
 }
 
@@ -65,7 +97,7 @@ func (d *donationUsecase) CreateDonation(c echo.Context, request dto.DonationReq
 	// Initialize logger
 	log := logrus.New()
 
-       if request.ProgramID == uuid.Nil {
+       if request.ProgramID == uuid.Nil && len(request.Allocations) == 0 {
         firstProgram, err := d.programDonation.GetFirstProgramDonation(ctx)
         if err != nil {
             log.WithError(err).Error("Failed to get first program donation")
@@ -74,6 +106,17 @@ func (d *donationUsecase) CreateDonation(c echo.Context, request dto.DonationReq
         request.ProgramID = firstProgram.ID
     }
 
+	// Verifikasi setiap ProgramDonation yang dipilih (pastikan program ID valid)
+	allocations, programs, err := d.buildAllocations(ctx, request)
+	if err != nil {
+		log.WithError(err).Error("Invalid donation allocations")
+		return dto.DonationResponse{}, err
+	}
+	program := programs[allocations[0].ProgramDonationID]
+	totalAmount := 0
+	for _, allocation := range allocations {
+		totalAmount += allocation.Amount
+	}
 
 	// Initialize the donation transaction from request
 	transactionDonation := entities.Donation{
@@ -83,131 +126,540 @@ func (d *donationUsecase) CreateDonation(c echo.Context, request dto.DonationReq
 		NoWA:              request.NoWA,
 		Email:             request.Email,
 		Message:           request.Message,
-		Status:            0, // default to 0 (pending)
+		Anonymous:         request.Anonymous,
+		DisplayName:       publicDisplayName(request.DisplayName),
+		Status:            entities.DonationStatusPending,
 		SnapURL:           "",
-		ProgramDonationID: request.ProgramID, // Setting ProgramDonationID directly from the request
-		Amount:            request.Amount,    // Set the Amount to the value from the request
+		PaymentAttempt:    1,
+		PaymentChannel:    d.paymentGateway.Name(),
+		ProgramDonationID: program.ID, // Program utama adalah alokasi pertama
+		Amount:            totalAmount, // Jumlah seluruh alokasi
+		UserID:            request.UserID,
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
 	}
+	for i := range allocations {
+		allocations[i].DonationID = transactionDonation.ID
+	}
 
-	// Verifikasi ProgramDonation yang dipilih (pastikan program ID valid)
-	program, err := d.programDonation.GetProgramDonationByID(ctx, request.ProgramID)
+	// Membuat transaksi di payment gateway
+	transactionDonation.OrderID = entities.DonationOrderID(transactionDonation.ID, transactionDonation.PaymentAttempt)
+	if err := d.createCharge(ctx, &transactionDonation, d.chargeRequest(&transactionDonation, allocations, programs)); err != nil {
+		log.WithError(err).Errorf("Failed to create %s transaction", d.paymentGateway.Name())
+		return dto.DonationResponse{}, err
+	}
+
+	// Insert the transaction donation and its allocations into the repository
+	if err := d.saveDonation(ctx, &transactionDonation, allocations); err != nil {
+		log.WithError(err).Error("Failed to insert donation transaction into the database")
+		return dto.DonationResponse{}, err
+	}
+	d.donationFeedUsecase.Publish(ctx, DonationEventCreated, &transactionDonation)
+	d.donationMailer.SendDonationCreated(ctx, &transactionDonation)
+	d.whatsAppUsecase.SendPaymentLink(ctx, &transactionDonation)
+
+	// Return the response struct with donation details, including the SnapURL.
+	// Nomor WhatsApp disamarkan.
+	return dto.DonationResponse{
+		ID:          transactionDonation.ID.String(),
+		Name:        transactionDonation.Name,
+		Address:     transactionDonation.Address,
+		NoWA:        transactionDonation.NoWA.Masked(),
+		Email:       transactionDonation.Email,
+		Amount:      transactionDonation.Amount,
+		Message:     transactionDonation.Message,
+		Status:      int(transactionDonation.Status),
+		StatusLabel: transactionDonation.Status.String(),
+		SnapURL:     transactionDonation.SnapURL, // Ensure SnapURL is returned
+		ProgramID:   program.ID.String(),
+		ProgramTitle: program.Title,
+		Anonymous:    transactionDonation.Anonymous,
+		DisplayName:  transactionDonation.DisplayName,
+		Allocations:  toAllocationResponses(allocations, programs),
+	}, nil
+}
+
+// buildAllocations menyusun alokasi program dari request. Request lama dengan satu
+// ProgramID dan Amount menjadi satu alokasi, sedangkan program yang sama digabung.
+// Setiap alokasi harus bernilai lebih dari 0.
+func (d *donationUsecase) buildAllocations(ctx context.Context, request dto.DonationRequest) ([]entities.DonationAllocation, map[uuid.UUID]*entities.ProgramDonation, error) {
+	requested := request.Allocations
+	if len(requested) == 0 {
+		requested = []dto.AllocationRequest{{ProgramID: request.ProgramID, Amount: request.Amount}}
+	}
+
+	var allocations []entities.DonationAllocation
+	index := map[uuid.UUID]int{}
+	programs := map[uuid.UUID]*entities.ProgramDonation{}
+	for _, item := range requested {
+		if item.Amount <= 0 {
+			return nil, nil, err_util.ErrInvalidAllocationAmount
+		}
+		if i, exists := index[item.ProgramID]; exists {
+			allocations[i].Amount += item.Amount
+			continue
+		}
+
+		program, err := d.programDonation.GetProgramDonationByID(ctx, item.ProgramID)
+		if err != nil {
+			return nil, nil, errors.New("program donation not found")
+		}
+		programs[program.ID] = program
+		index[program.ID] = len(allocations)
+		allocations = append(allocations, entities.DonationAllocation{
+			ID:                uuid.New(),
+			ProgramDonationID: program.ID,
+			Amount:            item.Amount,
+			CreatedAt:         time.Now(),
+		})
+	}
+	return allocations, programs, nil
+}
+
+// ResumeDonation mengembalikan link pembayaran donasi pending yang masih berlaku. Jika
+// link sudah kadaluarsa, transaksi baru dibuat dengan order id berakhiran nomor percobaan
+// yang tetap terhubung ke donasi yang sama.
+func (d *donationUsecase) ResumeDonation(c echo.Context, donationID uuid.UUID) (dto.DonationResumeResponse, error) {
+	log := logrus.New()
+
+	var donation *entities.Donation
+	err := d.transactionManager.WithinTransaction(c.Request().Context(), func(ctx context.Context) error {
+		var err error
+		// Baris donasi dikunci agar dua permintaan bersamaan tidak membuat dua transaksi baru
+		donation, err = d.donationRepository.FindByIdForUpdate(ctx, donationID)
+		if err != nil {
+			return err
+		}
+
+		if donation.Status != entities.DonationStatusPending {
+			return err_util.ErrDonationNotResumable
+		}
+
+		if donation.SnapURL != "" && (donation.SnapExpiresAt == nil || time.Now().Before(*donation.SnapExpiresAt)) {
+			return nil
+		}
+
+		allocations, err := loadAllocations(ctx, d.donationAllocationRepository, donation)
+		if err != nil {
+			return err
+		}
+		programs, err := d.programsOf(ctx, allocations)
+		if err != nil {
+			return err
+		}
+
+		// Transaksi lama dibatalkan agar tidak bisa dibayar bersamaan dengan yang baru
+		if err := d.paymentGateway.Cancel(ctx, donation.CurrentOrderID()); err != nil {
+			log.WithError(err).Warnf("Failed to cancel %s transaction for order %s", d.paymentGateway.Name(), donation.CurrentOrderID())
+		}
+
+		donation.PaymentAttempt++
+		donation.OrderID = entities.DonationOrderID(donation.ID, donation.PaymentAttempt)
+		if err := d.createCharge(ctx, donation, d.chargeRequest(donation, allocations, programs)); err != nil {
+			log.WithError(err).Errorf("Failed to create %s transaction", d.paymentGateway.Name())
+			return err
+		}
+
+		log.Infof("Donation %s resumed with order %s", donation.ID, donation.OrderID)
+		return d.donationRepository.Update(ctx, donation)
+	})
 	if err != nil {
-		log.WithError(err).Error("Program not found")
-		return dto.DonationResponse{}, errors.New("program donation not found")
+		return dto.DonationResumeResponse{}, err
 	}
 
-	// Create Midtrans request
-	req := &snap.Request{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  transactionDonation.ID.String(),
-			GrossAmt: int64(request.Amount), // Ensure that Amount is greater than 0
+	return dto.DonationResumeResponse{
+		Status:      int(donation.Status),
+		StatusLabel: donation.Status.String(),
+		SnapURL:     donation.SnapURL,
+	}, nil
+}
+
+// programsOf mengambil program dari setiap alokasi
+func (d *donationUsecase) programsOf(ctx context.Context, allocations []entities.DonationAllocation) (map[uuid.UUID]*entities.ProgramDonation, error) {
+	programs := map[uuid.UUID]*entities.ProgramDonation{}
+	for _, allocation := range allocations {
+		if _, exists := programs[allocation.ProgramDonationID]; exists {
+			continue
+		}
+		program, err := d.programDonation.GetProgramDonationByID(ctx, allocation.ProgramDonationID)
+		if err != nil {
+			return nil, err
+		}
+		programs[program.ID] = program
+	}
+	return programs, nil
+}
+
+// chargeRequest menyusun request gateway untuk donation.OrderID dengan satu item per
+// program. Pengaturan expiry dan metode pembayaran mengikuti program utama (alokasi
+// pertama), dan donasi rutin meminta kartu donatur disimpan.
+func (d *donationUsecase) chargeRequest(donation *entities.Donation, allocations []entities.DonationAllocation, programs map[uuid.UUID]*entities.ProgramDonation) payment.ChargeRequest {
+	items := make([]payment.Item, 0, len(allocations))
+	for _, allocation := range allocations {
+		program := programs[allocation.ProgramDonationID]
+		items = append(items, payment.Item{
+			ID:       program.ID.String(),
+			Name:     program.Title,
+			Price:    int64(allocation.Amount),
+			Quantity: 1,
+		})
+	}
+	primary := programs[allocations[0].ProgramDonationID]
+
+	return payment.ChargeRequest{
+		OrderID: donation.OrderID,
+		Amount:  int64(donation.Amount), // Ensure that Amount is greater than 0
+		Customer: payment.Customer{
+			Name:  donation.Name,
+			Email: donation.Email,
+			Phone: donation.NoWA.String(),
 		},
+		Items:           items,
+		ExpiryMinutes:   primary.PaymentExpiryMinutes,
+		EnabledPayments: config.SplitList(primary.EnabledPayments),
+		SaveCard:        donation.SubscriptionID != nil,
 	}
+}
 
-	var client snap.Client
-	client.New(d.config.ServerKey, midtrans.Sandbox)
+// createCharge membuat transaksi gateway lalu menyimpan link pembayaran dan batas
+// waktunya ke donation
+func (d *donationUsecase) createCharge(ctx context.Context, donation *entities.Donation, req payment.ChargeRequest) error {
+	charge, err := d.paymentGateway.CreateCharge(ctx, req)
+	if err != nil {
+		return err
+	}
 
-	snapResp, err := client.CreateTransaction(req)
-	if snapResp == nil {
-		return dto.DonationResponse{}, err
+	donation.SnapURL = charge.RedirectURL
+	donation.SnapExpiresAt = nil
+	if !charge.ExpiresAt.IsZero() {
+		donation.SnapExpiresAt = &charge.ExpiresAt
 	}
+	return nil
+}
 
-	transactionDonation.SnapURL = snapResp.RedirectURL
+// saveDonation menyimpan donasi baru beserta alokasinya dalam satu transaksi
+func (d *donationUsecase) saveDonation(ctx context.Context, donation *entities.Donation, allocations []entities.DonationAllocation) error {
+	return d.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := d.donationRepository.CreateDonation(ctx, donation); err != nil {
+			return err
+		}
+		return d.donationAllocationRepository.CreateAllocations(ctx, allocations)
+	})
+}
 
-	// Insert the transaction donation into the repository
-	err = d.donationRepository.CreateDonation(ctx, &transactionDonation)
+// CreateSubscriptionDonation menyimpan donasi untuk satu siklus donasi rutin dan dipanggil
+// di dalam transaksi siklus. Transaksi gateway tidak dibuat di sini agar transaksi database
+// tidak tertahan selama panggilan jaringan. Penagihan kartu atau pembuatan link pembayaran
+// dilakukan ChargeSubscriptionDonation setelah transaksi siklus tersimpan, agar tidak ada
+// kartu yang ditagih untuk donasi yang batal disimpan.
+func (d *donationUsecase) CreateSubscriptionDonation(ctx context.Context, subscription *entities.DonationSubscription) (*entities.Donation, error) {
+	program, err := d.programDonation.GetProgramDonationByID(ctx, subscription.ProgramDonationID)
 	if err != nil {
-		log.WithError(err).Error("Failed to insert donation transaction into the database")
-		return dto.DonationResponse{}, err
+		return nil, err
 	}
 
-	// Return the response struct with donation details, including the SnapURL
-	return dto.DonationResponse{
-		ID:        transactionDonation.ID.String(),
-		Name:      transactionDonation.Name,
-		Address:   transactionDonation.Address,
-		NoWA:      transactionDonation.NoWA,
-		Email:     transactionDonation.Email,
-		Amount:    transactionDonation.Amount,
-		Message:   transactionDonation.Message,
-		Status:    transactionDonation.Status,
-		SnapURL:   transactionDonation.SnapURL, // Ensure SnapURL is returned
-		ProgramID: program.ID.String(),
-	}, nil
+	donation := entities.Donation{
+		ID:                uuid.New(),
+		Name:              subscription.Name,
+		Address:           subscription.Address,
+		NoWA:              subscription.NoWA,
+		Email:             subscription.Email,
+		Message:           subscription.Message,
+		Anonymous:         subscription.Anonymous,
+		DisplayName:       subscription.DisplayName,
+		Status:            entities.DonationStatusPending,
+		PaymentAttempt:    1,
+		PaymentChannel:    d.paymentGateway.Name(),
+		ProgramDonationID: program.ID,
+		Amount:            subscription.Amount,
+		SubscriptionID:    &subscription.ID,
+		UserID:            subscription.UserID,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+	donation.OrderID = entities.DonationOrderID(donation.ID, donation.PaymentAttempt)
+	allocations := []entities.DonationAllocation{{
+		ID:                uuid.New(),
+		DonationID:        donation.ID,
+		ProgramDonationID: program.ID,
+		Amount:            subscription.Amount,
+		CreatedAt:         time.Now(),
+	}}
+
+	if err := d.saveDonation(ctx, &donation, allocations); err != nil {
+		return nil, err
+	}
+	return &donation, nil
 }
 
-func (d *donationUsecase) UpdateDonationStatus(c echo.Context, notification midtran.Notification) error {
+// ChargeSubscriptionDonation menagih kartu tersimpan untuk donasi siklus yang sudah
+// disimpan CreateSubscriptionDonation, atau membuat link pembayaran jika tidak ada kartu.
+// Penagihan memakai order id donasi dan hanya dilakukan selama donasi masih pending tanpa
+// link, sehingga pemanggilan ulang tidak menagih dua kali. Link pembayaran dikirim ke
+// donatur oleh pemanggil.
+func (d *donationUsecase) ChargeSubscriptionDonation(ctx context.Context, subscription *entities.DonationSubscription, donationID uuid.UUID) (*entities.Donation, error) {
+	log := logrus.New()
+
+	donation, err := d.donationRepository.GetDonationByID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	if donation.Status != entities.DonationStatusPending || donation.SnapURL != "" {
+		return donation, nil
+	}
+
+	d.donationFeedUsecase.Publish(ctx, DonationEventCreated, donation)
+	allocations, err := loadAllocations(ctx, d.donationAllocationRepository, donation)
+	if err != nil {
+		return nil, err
+	}
+	program, err := d.programDonation.GetProgramDonationByID(ctx, donation.ProgramDonationID)
+	if err != nil {
+		return nil, err
+	}
+	programs := map[uuid.UUID]*entities.ProgramDonation{program.ID: program}
+
+	if d.canChargeSavedToken(subscription) {
+		charger := d.paymentGateway.(payment.TokenCharger)
+		status, err := charger.ChargeSavedToken(ctx, d.chargeRequest(donation, allocations, programs), subscription.SavedTokenID)
+		if err != nil {
+			// Penagihan yang gagal di jaringan bisa saja sudah sampai ke gateway, jadi status
+			// order diperiksa dulu sebelum donatur diberi link pembayaran
+			log.WithError(err).Warnf("Failed to charge saved card for subscription %s", subscription.ID)
+			status, err = d.paymentGateway.QueryStatus(ctx, donation.OrderID)
+			if err != nil && !errors.Is(err, payment.ErrTransactionNotFound) {
+				// Status belum pasti, donasi dibiarkan pending untuk rekonsiliasi dan job kedaluwarsa
+				return nil, err
+			}
+		}
+		if status != nil {
+			if _, err := d.ApplyTransactionStatus(ctx, *status); err != nil {
+				return nil, err
+			}
+			return d.donationRepository.GetDonationByID(ctx, donation.ID)
+		}
+
+		// Kartu tidak pernah ditagih, donatur membayar lewat link dengan order id percobaan berikutnya
+		donation.PaymentAttempt++
+		donation.OrderID = entities.DonationOrderID(donation.ID, donation.PaymentAttempt)
+	}
+
+	if err := d.createCharge(ctx, donation, d.chargeRequest(donation, allocations, programs)); err != nil {
+		return nil, err
+	}
+	if err := d.donationRepository.Update(ctx, donation); err != nil {
+		return nil, err
+	}
+	return donation, nil
+}
+
+// canChargeSavedToken bernilai true jika gateway mendukung penagihan kartu tersimpan
+// dan donasi rutin memiliki kartu tersimpan
+func (d *donationUsecase) canChargeSavedToken(subscription *entities.DonationSubscription) bool {
+	_, canCharge := d.paymentGateway.(payment.TokenCharger)
+	return canCharge && subscription.SavedTokenID != ""
+}
+
+func (d *donationUsecase) UpdateDonationStatus(c echo.Context, notification payment.TransactionStatus) error {
     log := logrus.New()
     log.Infof("Processing notification: %+v", notification)
 
-    // Mengonversi OrderID (string) menjadi uuid.UUID
-    donationID, err := uuid.Parse(notification.OrderID)
+    if err := d.paymentGateway.VerifyNotification(c.Request().Context(), notification); err != nil {
+        log.WithError(err).Error("Failed to verify notification")
+        return err
+    }
+
+    _, err := d.ApplyTransactionStatus(c.Request().Context(), notification)
+    return err
+}
+
+// ApplyTransactionStatus menerapkan status transaksi dari gateway ke donasi terkait lalu
+// mengirim notifikasi perubahan statusnya. Dipakai oleh webhook maupun worker rekonsiliasi
+// agar aturan perubahan status sama.
+func (d *donationUsecase) ApplyTransactionStatus(ctx context.Context, notification payment.TransactionStatus) (*DonationStatusChange, error) {
+    change, err := d.RecordTransactionStatus(ctx, notification)
     if err != nil {
-        log.WithError(err).Error("Failed to parse donation ID")
-        return errors.New("invalid donation ID")
+        return nil, err
     }
+    d.NotifyStatusChange(ctx, change)
+    return change, nil
+}
+
+// RecordTransactionStatus menerapkan status transaksi tanpa mengirim notifikasi. Dipakai
+// pemanggil yang membungkusnya dalam transaksi sendiri, yang harus memanggil
+// NotifyStatusChange setelah transaksinya di-commit agar feed, email, dan WhatsApp membaca
+// donasi yang sudah tersimpan.
+func (d *donationUsecase) RecordTransactionStatus(ctx context.Context, notification payment.TransactionStatus) (*DonationStatusChange, error) {
+    log := logrus.New()
 
-    // Cari donasi berdasarkan ID (uuid.UUID)
-    donation, err := d.donationRepository.FindById(c.Request().Context(), donationID)
+    // Mengonversi OrderID (string) menjadi uuid.UUID
+    donationID, err := entities.ParseDonationOrderID(notification.OrderID)
     if err != nil {
-        log.WithError(err).Error("Donation not found")
-        return errors.New("donation not found")
+        log.WithError(err).Error("Failed to parse donation ID")
+        return nil, errors.New("invalid donation ID")
     }
+    change := &DonationStatusChange{DonationID: donationID}
 
-    log.Infof("Found donation: %+v", donation)
-
-    // Tentukan status berdasarkan transaction_status
-    previousStatus := donation.Status
-    switch notification.TransactionStatus {
-    case "capture", "settlement":
-        donation.Status = 1 // Pembayaran berhasil
-        // Update currentAmount di ProgramDonation
-        if err := d.programDonation.UpdateCurrentAmount(c.Request().Context(), donation.ProgramDonationID, donation.Amount); err != nil {
-            log.WithError(err).Error("Failed to update currentAmount in ProgramDonation")
-            return errors.New("failed to update currentAmount")
-        }
-    case "pending":
-        donation.Status = 0 // Pembayaran pending
-    case "deny", "expire", "cancel", "failure":
-        donation.Status = 2 // Pembayaran gagal
-    default:
+    nextStatus, known := notification.DonationStatus()
+    if !known {
         log.Warnf("Unknown transaction status: %s", notification.TransactionStatus)
     }
 
-    log.Infof("Updating donation status from %d to %d", previousStatus, donation.Status)
+    // Perubahan status, currentAmount, dan catatan notifikasi disimpan dalam satu transaksi
+    err = d.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+        // Baris donasi dikunci agar notifikasi yang datang bersamaan diproses berurutan
+        donation, err := d.donationRepository.FindByIdForUpdate(ctx, donationID)
+        if err != nil {
+            log.WithError(err).Error("Donation not found")
+            return errors.New("donation not found")
+        }
 
-    // Simpan status donasi yang baru
-    err = d.donationRepository.Update(c.Request().Context(), donation)
-    if err != nil {
-        log.WithError(err).Error("Failed to update donation status")
-        return err
-    }
+        if notification.TransactionID != "" {
+            processed, err := d.transactionNotificationRepository.ExistsByTransactionStatus(ctx, notification.TransactionID, notification.TransactionStatus)
+            if err != nil {
+                return err
+            }
+            if processed {
+                return err_util.ErrDuplicateNotification
+            }
+        }
 
-    // Simpan notifikasi ke dalam database untuk audit
-    transactionNotification := entities.TransactionNotification{
-        ID:                uuid.New(),
-        OrderID:           notification.OrderID,
-        TransactionStatus: notification.TransactionStatus,
-        GrossAmount:       notification.GrossAmount,
-        TransactionTime:   notification.TransactionTime,
-        SignatureKey:      notification.SignatureKey,
-    }
+        previousStatus := donation.Status
+        change.From, change.To = previousStatus, previousStatus
 
-    err = d.transactionNotificationRepository.CreateNotification(c.Request().Context(), &transactionNotification)
+        // Notifikasi dari percobaan pembayaran lama hanya dicatat, kecuali donatur
+        // ternyata sudah membayar lewat link lama
+        stale := notification.OrderID != donation.CurrentOrderID() && nextStatus != entities.DonationStatusPaid
+        if stale {
+            log.Infof("Ignoring %s for superseded order %s", notification.TransactionStatus, notification.OrderID)
+        }
+
+        // Donatur bisa membayar link lama dan link baru sekaligus. Pembayaran kedua tidak
+        // mengubah donasi tetapi harus dikembalikan, jadi admin diberi tahu.
+        duplicate := nextStatus == entities.DonationStatusPaid && notification.OrderID != donation.CurrentOrderID() &&
+            (previousStatus.IsCollected() || previousStatus == entities.DonationStatusRefunded)
+        if duplicate {
+            log.Errorf("Duplicate payment of %s donation %s through order %s, paid order is %s", previousStatus, donation.ID, notification.OrderID, donation.CurrentOrderID())
+            change.DuplicateOrderID = notification.OrderID
+        }
+
+        // Refund sebagian dari gateway tidak menyertakan nominalnya, sehingga dana donasi
+        // hanya dikurangi oleh alur refund yang mencatat nominal tersebut
+        partialRefund := nextStatus == entities.DonationStatusPartiallyRefunded
+        if partialRefund {
+            log.Infof("Partial refund of donation %s is applied by the refund flow", donation.ID)
+        }
+
+        if known && !stale && !duplicate && !partialRefund && nextStatus != previousStatus {
+            // Dana hanya dicatat jika nominal transaksi sama dengan nominal donasi
+            if nextStatus == entities.DonationStatusPaid {
+                grossAmount, err := notification.Amount()
+                if err != nil || grossAmount != donation.Amount {
+                    log.Errorf("Gross amount %q of order %s does not match donation amount %d", notification.GrossAmount, notification.OrderID, donation.Amount)
+                    return err_util.ErrGrossAmountMismatch
+                }
+            }
+
+            lateSettlement := nextStatus == entities.DonationStatusPaid && previousStatus.AcceptsLateSettlement()
+            if !previousStatus.CanTransitionTo(nextStatus) && !lateSettlement {
+                return fmt.Errorf("%w: %s to %s", err_util.ErrIllegalStatusTransition, previousStatus, nextStatus)
+            }
+            if lateSettlement {
+                log.Warnf("Recording late settlement of %s donation %s", previousStatus, donation.ID)
+            }
+
+            // currentAmount hanya berubah saat donasi masuk ke status paid atau di-refund penuh,
+            // dan dibagi ke setiap program sesuai alokasinya
+            allocations, err := loadAllocations(ctx, d.donationAllocationRepository, donation)
+            if err != nil {
+                return err
+            }
+
+            switch nextStatus {
+            case entities.DonationStatusPaid:
+                if err := updateProgramAmounts(ctx, d.programDonation, allocations, 0, donation.Amount); err != nil {
+                    log.WithError(err).Error("Failed to update currentAmount in ProgramDonation")
+                    return errors.New("failed to update currentAmount")
+                }
+                if donation.PaidAt == nil {
+                    paidAt, err := time.ParseInLocation(payment.TimeLayout, notification.SettlementTime, time.Local)
+                    if err != nil {
+                        paidAt = time.Now()
+                    }
+                    donation.PaidAt = &paidAt
+                }
+                if notification.PaymentType != "" {
+                    donation.PaymentChannel = notification.PaymentType
+                }
+                // Donatur membayar lewat link lama, refund dan webhook berikutnya memakai order tersebut
+                if notification.OrderID != donation.CurrentOrderID() {
+                    log.Infof("Donation %s paid through superseded order %s", donation.ID, notification.OrderID)
+                    donation.OrderID = notification.OrderID
+                }
+            case entities.DonationStatusRefunded:
+                remaining := donation.Amount - donation.RefundedAmount
+                if err := updateProgramAmounts(ctx, d.programDonation, allocations, donation.RefundedAmount, -remaining); err != nil {
+                    log.WithError(err).Error("Failed to update currentAmount in ProgramDonation")
+                    return errors.New("failed to update currentAmount")
+                }
+                donation.RefundedAmount = donation.Amount
+            }
+
+            log.Infof("Updating donation status from %s to %s", previousStatus, nextStatus)
+            donation.Status = nextStatus
+            if err := d.donationRepository.Update(ctx, donation); err != nil {
+                log.WithError(err).Error("Failed to update donation status")
+                return err
+            }
+            change.To = nextStatus
+
+            // Kuitansi diterbitkan dalam transaksi yang sama agar nomornya tidak berlubang
+            if nextStatus == entities.DonationStatusPaid {
+                if _, err := d.donationReceiptUsecase.IssueReceipt(ctx, donation, allocations); err != nil {
+                    log.WithError(err).Error("Failed to issue donation receipt")
+                    return err
+                }
+            }
+        }
+
+        // Simpan notifikasi ke dalam database untuk audit dan deduplikasi
+        transactionNotification := entities.TransactionNotification{
+            ID:                uuid.New(),
+            OrderID:           notification.OrderID,
+            TransactionID:     notification.TransactionID,
+            TransactionStatus: notification.TransactionStatus,
+            GrossAmount:       notification.GrossAmount,
+            TransactionTime:   notification.TransactionTime,
+            SignatureKey:      notification.SignatureKey,
+            SavedTokenID:      notification.SavedTokenID,
+        }
+        return d.transactionNotificationRepository.CreateNotification(ctx, &transactionNotification)
+    })
+    if errors.Is(err, err_util.ErrDuplicateNotification) {
+        log.Infof("Notification %s (%s) already processed, skipping", notification.TransactionID, notification.TransactionStatus)
+        change.To = change.From
+        return change, nil
+    }
     if err != nil {
-        log.WithError(err).Error("Failed to save transaction notification")
+        log.WithError(err).Error("Failed to process notification")
+        return nil, err
     }
 
-    log.Infof("Donation status updated successfully: %+v", donation)
-    return nil
+    log.Infof("Donation %s processed successfully", donationID)
+    return change, nil
 }
 
+// NotifyStatusChange mengirim perubahan status donasi ke feed, email, dan WhatsApp
+func (d *donationUsecase) NotifyStatusChange(ctx context.Context, change *DonationStatusChange) {
+    d.donationFeedUsecase.PublishStatusChange(ctx, change)
+    d.donationMailer.NotifyStatusChange(ctx, change)
+    d.whatsAppUsecase.NotifyStatusChange(ctx, change)
+}
 
 
 
-func (du *donationUsecase) GetDonations(c echo.Context, programDonationID uuid.UUID, searchName string, req *dto_base.PaginationRequest) (*[]dto.DonationResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
+
+func (du *donationUsecase) GetDonations(c echo.Context, programDonationID uuid.UUID, searchName string, includeExpired bool, req *dto_base.PaginationRequest) (*[]dto.DonationResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
     ctx, cancel := context.WithCancel(c.Request().Context())
     defer cancel()
 
@@ -232,7 +684,7 @@ func (du *donationUsecase) GetDonations(c echo.Context, programDonationID uuid.U
     }
 
     // Validasi parameter
-    donations, totalData, err := du.donationRepository.GetDonations(ctx, programDonationID, searchName, req)
+    donations, totalData, err := du.donationRepository.GetDonations(ctx, programDonationID, searchName, includeExpired, req)
     if err != nil {
         return nil, nil, nil, err
     }
@@ -260,13 +712,19 @@ func (du *donationUsecase) GetDonations(c echo.Context, programDonationID uuid.U
             ID:          donation.ID.String(),
             Name:        donation.Name,
             Address:     donation.Address,
-            NoWA:        donation.NoWA,
+            NoWA:        donation.NoWA.String(),
             Email:       donation.Email,
             ProgramTitle: programTitle, // Menambahkan title dari ProgramDonation
             ProgramID:   donation.ProgramDonationID.String(),
             Amount:      donation.Amount,
-            Status:      donation.Status,
+            Status:      int(donation.Status),
+            StatusLabel: donation.Status.String(),
             Message:     donation.Message,
+            Anonymous:      donation.Anonymous,
+            DisplayName:    donation.DisplayName,
+            PaymentChannel: donation.PaymentChannel,
+            PaidAt:         formatPaidAt(donation.PaidAt),
+            ProofImageURL:  donation.ProofImageURL,
         })
     }
 
@@ -313,25 +771,64 @@ func (du *donationUsecase) GetDonationByID(c echo.Context, donationID uuid.UUID)
 
     // Mengonversi donasi ke bentuk DTO response
     donationResponse := &dto.DonationResponse{
-		ID:      donation.ID.String(),
-		Name:    donation.Name,
-		Address: donation.Address,
-		NoWA:    donation.NoWA,
-		Email:   donation.Email,
-		ProgramID: donation.ProgramDonationID.String(),
-		Amount:  donation.Amount,
-		Status:  donation.Status,
-		Message: donation.Message,
+		ID:          donation.ID.String(),
+		Name:        donation.Name,
+		Address:     donation.Address,
+		NoWA:        donation.NoWA.String(),
+		Email:       donation.Email,
+		ProgramID:   donation.ProgramDonationID.String(),
+		Amount:      donation.Amount,
+		Status:      int(donation.Status),
+		StatusLabel: donation.Status.String(),
+		Message:     donation.Message,
+		Anonymous:      donation.Anonymous,
+		DisplayName:    donation.DisplayName,
+		PaymentChannel: donation.PaymentChannel,
+		PaidAt:         formatPaidAt(donation.PaidAt),
+		ProofImageURL:  donation.ProofImageURL,
+    }
+
+    donationResponse.Allocations, err = du.allocationResponses(ctx, donation)
+    if err != nil {
+        return nil, err
     }
 
     return donationResponse, nil
 }
 
+// GetPublicDonation mengambil detail donasi untuk endpoint publik tanpa identitas lengkap
+// donatur. Detail lengkap hanya tersedia bagi admin lewat GetDonationByID.
+func (du *donationUsecase) GetPublicDonation(c echo.Context, donationID uuid.UUID) (*dto.DonationPublicResponse, error) {
+	ctx := c.Request().Context()
+
+	donation, err := du.donationRepository.GetDonationByID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DonationPublicResponse{
+		ID:          donation.ID.String(),
+		Name:        donation.PublicName(),
+		Amount:      donation.Amount,
+		Message:     donation.PublicMessageText(),
+		Status:      int(donation.Status),
+		StatusLabel: donation.Status.String(),
+		ProgramID:   donation.ProgramDonationID.String(),
+		PaidAt:      formatPaidAt(donation.PaidAt),
+	}
+
+	response.Allocations, err = du.allocationResponses(ctx, donation)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 func (du *donationUsecase) GetDonationLanding(c echo.Context) (*[]dto.DonationLandingResponse, error) {
 	// Mengambil konteks dari Echo
 	ctx := c.Request().Context()
 
-	// Memanggil repository untuk mendapatkan donasi dengan status 1
+	// Memanggil repository untuk mendapatkan donasi yang sudah dibayar
 	donations, err := du.donationRepository.GetDonationsLanding(ctx)
 	if err != nil {
 		return nil, err
@@ -340,11 +837,16 @@ func (du *donationUsecase) GetDonationLanding(c echo.Context) (*[]dto.DonationLa
 	// Anda mungkin ingin mengonversi data entities.Donation ke DTO (misalnya, DonationLandingResponse)
 	var response []dto.DonationLandingResponse
 	for _, donation := range *donations {
+		allocations, err := du.allocationResponses(ctx, &donation)
+		if err != nil {
+			return nil, err
+		}
+
 		response = append(response, dto.DonationLandingResponse{
-            ID:        donation.ID.String(),
-			Name:      donation.Name,
+			Name:      donation.PublicName(),
 			Amount:    donation.Amount,
-			Message:   donation.Message,
+			Message:   donation.PublicMessageText(),
+			Allocations: allocations,
 		})
 	}
 
@@ -356,7 +858,7 @@ func (du *donationUsecase) GetChartDonation(c echo.Context) (*[]dto.DonationChar
 	// Mengambil konteks dari Echo
 	ctx := c.Request().Context()
 
-    // Memanggil repository untuk mendapatkan donasi dengan status 1
+    // Memanggil repository untuk mendapatkan semua donasi, hanya yang sudah dibayar yang ditampilkan
     donations, err := du.donationRepository.GetDonation(ctx)
     if err != nil {
         return nil, err
@@ -365,7 +867,7 @@ func (du *donationUsecase) GetChartDonation(c echo.Context) (*[]dto.DonationChar
     var response []dto.DonationChartResponse
     for _, donation := range *donations {
 
-        if donation.Status != 1 {
+        if !donation.Status.IsCollected() {
             continue
         }
 
@@ -376,7 +878,7 @@ func (du *donationUsecase) GetChartDonation(c echo.Context) (*[]dto.DonationChar
 
         response = append(response, dto.DonationChartResponse{
             ID:          donation.ID.String(),
-            Amount:      donation.Amount,
+            Amount:      donation.Amount - donation.RefundedAmount,
             Date:        donation.UpdatedAt.Format("2006-01-02"),
             ProgramDonation: program.Title,
         })
@@ -386,53 +888,6 @@ func (du *donationUsecase) GetChartDonation(c echo.Context) (*[]dto.DonationChar
 }
 
 
-func (du *donationUsecase) GetDonaturNotifikasi(c echo.Context) (*[]dto.DonaturNotifikasiResponse, error) {
-    // Mengambil konteks dari Echo
-    ctx := c.Request().Context()
-
-    // Memanggil repository untuk mendapatkan donasi
-    donations, err := du.donationRepository.GetNotifikasi(ctx)
-    if err != nil {
-        return nil, err
-    }
-
-    // Anda mungkin ingin mengonversi data entities.Donation ke DTO (misalnya, DonaturNotifikasiResponse)
-    var response []dto.DonaturNotifikasiResponse
-    for _, donation := range *donations {
-        if donation.TransactionStatus != "settlement" {
-            continue
-        }
-
-        programID, err := uuid.Parse(donation.OrderID)
-        if err != nil {
-            return nil, errors.New("ID program donasi tidak valid: " + donation.OrderID)
-        }
-
-        donatiUser, err := du.donationRepository.GetDonationByID(ctx, programID)
-        if err != nil {
-            return nil, err
-        }
-
-        program, err := du.programDonation.GetProgramDonationByID(ctx, donatiUser.ProgramDonationID)
-        if err != nil {
-            return nil, err
-        }
-
-        response = append(response, dto.DonaturNotifikasiResponse{
-            ID:        donation.ID.String(),
-            Name:      donatiUser.Name,
-            Amount:    donation.GrossAmount,
-            ProgramDonation: program.Title,
-            Message:   donatiUser.Message,
-            Date:      donation.CreatedAt.Format("2006-01-02"),
-            Status:    donation.TransactionStatus,
-        })
-
-    }
-
-    return &response, nil
-}
-
 func (du *donationUsecase) GetDonaturByProgramDonation(c echo.Context, programDonationID uuid.UUID) (*[]dto.DonationLandingResponse, error) {
     // Mengambil konteks dari Echo
     ctx := c.Request().Context()
@@ -447,11 +902,16 @@ func (du *donationUsecase) GetDonaturByProgramDonation(c echo.Context, programDo
     // Anda mungkin ingin mengonversi data entities.Donation ke DTO (misalnya, DonationLandingResponse)
     var response []dto.DonationLandingResponse
     for _, donation := range *donations {
+        allocations, err := du.allocationResponses(ctx, &donation)
+        if err != nil {
+            return nil, err
+        }
+
         response = append(response, dto.DonationLandingResponse{
-            ID:        donation.ID.String(),
-            Name:      donation.Name,
+            Name:      donation.PublicName(),
             Amount:    donation.Amount,
-            Message:   donation.Message,
+            Message:   donation.PublicMessageText(),
+            Allocations: allocations,
         })
     }
 
@@ -471,4 +931,122 @@ func (du *donationUsecase) GetNotifikasiStatus(c echo.Context, donationID uuid.U
     return notifications, nil
 }
 
+// publicDisplayName menentukan nama publik donasi baru. Hanya nama yang dipilih donatur
+// yang disimpan; tanpa pilihan itu halaman publik memakai nama anonim.
+func publicDisplayName(displayName string) string {
+	return strings.TrimSpace(displayName)
+}
+
+func formatPaidAt(paidAt *time.Time) string {
+	if paidAt == nil {
+		return ""
+	}
+	return paidAt.Format(time.RFC3339)
+}
+
+// allocationResponses mengambil alokasi program sebuah donasi beserta judul programnya.
+// Program yang sudah dihapus tetap ditampilkan tanpa judul.
+func (du *donationUsecase) allocationResponses(ctx context.Context, donation *entities.Donation) ([]dto.AllocationResponse, error) {
+	allocations, err := loadAllocations(ctx, du.donationAllocationRepository, donation)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := map[uuid.UUID]*entities.ProgramDonation{}
+	for _, allocation := range allocations {
+		if program, err := du.programDonation.GetProgramDonationByID(ctx, allocation.ProgramDonationID); err == nil {
+			programs[program.ID] = program
+		}
+	}
+	return toAllocationResponses(allocations, programs), nil
+}
+
+func toAllocationResponses(allocations []entities.DonationAllocation, programs map[uuid.UUID]*entities.ProgramDonation) []dto.AllocationResponse {
+	responses := make([]dto.AllocationResponse, 0, len(allocations))
+	for _, allocation := range allocations {
+		response := dto.AllocationResponse{
+			ProgramID: allocation.ProgramDonationID.String(),
+			Amount:    allocation.Amount,
+		}
+		if program, ok := programs[allocation.ProgramDonationID]; ok {
+			response.ProgramTitle = program.Title
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// GetDonorDonations mengambil riwayat donasi akun donatur beserta alokasi program dan kuitansinya
+func (du *donationUsecase) GetDonorDonations(c echo.Context, userID uuid.UUID, req *dto_base.PaginationRequest) (*[]dto.DonationHistoryResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
+	ctx := c.Request().Context()
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	donations, totalData, err := du.donationRepository.GetDonationsByUserID(ctx, userID, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	totalPage := int(math.Ceil(float64(totalData) / float64(req.Limit)))
+	if totalData > 0 && req.Page > totalPage {
+		return nil, nil, nil, err_util.ErrPageNotFound
+	}
 
+	responses := []dto.DonationHistoryResponse{}
+	for i := range donations {
+		donation := &donations[i]
+
+		allocations, err := du.allocationResponses(ctx, donation)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		response := dto.DonationHistoryResponse{
+			ID:             donation.ID.String(),
+			Amount:         donation.Amount,
+			Status:         int(donation.Status),
+			StatusLabel:    donation.Status.String(),
+			Message:        donation.Message,
+			PaymentChannel: donation.PaymentChannel,
+			PaidAt:         formatPaidAt(donation.PaidAt),
+			CreatedAt:      donation.CreatedAt.Format(time.RFC3339),
+			Allocations:    allocations,
+		}
+		if donation.Status == entities.DonationStatusPending {
+			response.SnapURL = donation.SnapURL
+		}
+		if donation.SubscriptionID != nil {
+			response.SubscriptionID = donation.SubscriptionID.String()
+		}
+
+		receipt, err := du.donationReceiptUsecase.GetReceipt(ctx, donation.ID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if receipt != nil {
+			response.Receipt = &dto.ReceiptSummary{
+				Number:   receipt.Number,
+				IssuedAt: receipt.IssuedAt.Format(time.RFC3339),
+				URL:      fmt.Sprintf("/api/v1/donors/me/donations/%s/receipt", donation.ID),
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	baseURL := fmt.Sprintf("%s?limit=%d&page=", c.Request().URL.Path, req.Limit)
+	link := &dto_base.Link{}
+	if req.Page > 1 {
+		link.Prev = baseURL + strconv.Itoa(req.Page-1)
+	}
+	if req.Page < totalPage {
+		link.Next = baseURL + strconv.Itoa(req.Page+1)
+	}
+
+	return &responses, &dto_base.PaginationMetadata{
+		TotalData:   totalData,
+		TotalPage:   totalPage,
+		CurrentPage: req.Page,
+	}, link, nil
+}
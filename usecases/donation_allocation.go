@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"context"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+)
+
+// loadAllocations mengambil alokasi program sebuah donasi. Donasi lama yang tidak
+// punya alokasi dianggap seluruhnya untuk ProgramDonationID.
+func loadAllocations(ctx context.Context, allocationRepository repositories.DonationAllocationRepository, donation *entities.Donation) ([]entities.DonationAllocation, error) {
+	allocations, err := allocationRepository.GetAllocationsByDonationID(ctx, donation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(allocations) == 0 {
+		allocations = []entities.DonationAllocation{{
+			DonationID:        donation.ID,
+			ProgramDonationID: donation.ProgramDonationID,
+			Amount:            donation.Amount,
+		}}
+	}
+	return allocations, nil
+}
+
+// updateProgramAmounts mengubah currentAmount setiap program sebesar bagiannya dari
+// rentang nominal donasi [from, from+|amount|). Nilai amount positif menambah dan
+// negatif mengurangi. Bagian dihitung dari posisi kumulatif agar beberapa refund
+// sebagian tetap berjumlah tepat sama dengan alokasi awal.
+func updateProgramAmounts(ctx context.Context, programDonation repositories.ProgramDonationRepository, allocations []entities.DonationAllocation, from int, amount int) error {
+	sign := 1
+	if amount < 0 {
+		sign, amount = -1, -amount
+	}
+
+	total := 0
+	for _, allocation := range allocations {
+		total += allocation.Amount
+	}
+	if total == 0 {
+		return nil
+	}
+
+	share := func(index int, position int) int {
+		if index < len(allocations)-1 {
+			return position * allocations[index].Amount / total
+		}
+		rest := position
+		for i := 0; i < len(allocations)-1; i++ {
+			rest -= position * allocations[i].Amount / total
+		}
+		return rest
+	}
+
+	for i, allocation := range allocations {
+		delta := share(i, from+amount) - share(i, from)
+		if delta == 0 {
+			continue
+		}
+		if err := programDonation.UpdateCurrentAmount(ctx, allocation.ProgramDonationID, sign*delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
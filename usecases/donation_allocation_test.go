@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateProgramAmounts(t *testing.T) {
+	type step struct {
+		from   int
+		amount int
+	}
+
+	tests := []struct {
+		name        string
+		allocations []int
+		steps       []step
+		want        []int
+	}{
+		{"single program", []int{100000}, []step{{0, 100000}}, []int{100000}},
+		{"proportional credit", []int{60000, 40000}, []step{{0, 100000}}, []int{60000, 40000}},
+		{"remainder goes to the last program", []int{1, 1, 1}, []step{{0, 2}}, []int{0, 0, 2}},
+		{"uneven split rounds down except the last", []int{33334, 33333, 33333}, []step{{0, 100000}}, []int{33334, 33333, 33333}},
+		{"small credit goes to the last program", []int{60000, 40000}, []step{{0, 1}}, []int{0, 1}},
+		{"full refund", []int{60000, 40000}, []step{{0, 100000}, {0, -100000}}, []int{0, 0}},
+		{"partial refund", []int{60000, 40000}, []step{{0, 100000}, {0, -50000}}, []int{30000, 20000}},
+		{"partial refunds add up to the allocations", []int{33334, 33333, 33333}, []step{{0, 100000}, {0, -1}, {1, -33332}, {33333, -2}, {33335, -66665}}, []int{0, 0, 0}},
+		{"odd partial refunds of an even split", []int{1, 1}, []step{{0, 2}, {0, -1}}, []int{1, 0}},
+		{"refund after a partial refund", []int{1, 1}, []step{{0, 2}, {0, -1}, {1, -1}}, []int{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			programs := &memProgramRepo{programs: map[uuid.UUID]entities.ProgramDonation{}}
+			allocations := make([]entities.DonationAllocation, 0, len(tt.allocations))
+			for _, amount := range tt.allocations {
+				program := newTestProgram("Program")
+				programs.programs[program.ID] = program
+				allocations = append(allocations, entities.DonationAllocation{ProgramDonationID: program.ID, Amount: amount})
+			}
+
+			for _, s := range tt.steps {
+				if err := updateProgramAmounts(context.Background(), programs, allocations, s.from, s.amount); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for i, allocation := range allocations {
+				if got := programs.programs[allocation.ProgramDonationID].CurrentAmount; got != tt.want[i] {
+					t.Errorf("program %d has %d, want %d", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateDonationRejectsNonPositiveAllocations(t *testing.T) {
+	education, food := newTestProgram("Pendidikan"), newTestProgram("Makanan")
+
+	tests := []struct {
+		name        string
+		amount      int
+		allocations []dto.AllocationRequest
+	}{
+		{"zero amount", 0, nil},
+		{"negative amount", -50000, nil},
+		{"zero allocation", 0, []dto.AllocationRequest{{ProgramID: education.ID, Amount: 50000}, {ProgramID: food.ID, Amount: 0}}},
+		{"negative allocation", 0, []dto.AllocationRequest{{ProgramID: education.ID, Amount: 50000}, {ProgramID: food.ID, Amount: -10000}}},
+		{"negative allocation merged into a positive one", 0, []dto.AllocationRequest{{ProgramID: education.ID, Amount: 50000}, {ProgramID: education.ID, Amount: -10000}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newDonationTestEnv(t, education, food)
+
+			request := testDonationRequest(education.ID, tt.amount)
+			request.Allocations = tt.allocations
+			if _, err := env.usecase.CreateDonation(newTestContext(), request); !errors.Is(err, err_util.ErrInvalidAllocationAmount) {
+				t.Errorf("CreateDonation returned %v, want %v", err, err_util.ErrInvalidAllocationAmount)
+			}
+			if len(env.donations.donations) != 0 {
+				t.Errorf("%d donations saved, want none", len(env.donations.donations))
+			}
+		})
+	}
+}
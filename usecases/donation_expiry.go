@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/payment"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type DonationExpiryUsecase interface {
+	ExpireStaleDonations(ctx context.Context) (int, error)
+}
+
+type donationExpiryUsecase struct {
+	donationUsecase    DonationUsecase
+	donationRepository repositories.DonationRepository
+	transferProof      repositories.TransferProofRepository
+	paymentGateway     payment.PaymentGateway
+	config             config.DonationConfig
+}
+
+func NewDonationExpiryUsecase(donationUsecase DonationUsecase, donationRepository repositories.DonationRepository, transferProof repositories.TransferProofRepository, paymentGateway payment.PaymentGateway, config config.DonationConfig) DonationExpiryUsecase {
+	return &donationExpiryUsecase{
+		donationUsecase:    donationUsecase,
+		donationRepository: donationRepository,
+		transferProof:      transferProof,
+		paymentGateway:     paymentGateway,
+		config:             config,
+	}
+}
+
+// ExpireStaleDonations mengubah donasi pending yang melewati PendingTTL menjadi expired.
+// Status di gateway dicek terlebih dahulu agar pembayaran yang webhook-nya hilang
+// tidak ikut kadaluarsa, lalu transaksi di gateway dibatalkan jika didukung. Donasi
+// dibaca per halaman dengan cursor sehingga donasi yang gagal diproses tidak
+// menghalangi donasi yang lebih baru.
+func (eu *donationExpiryUsecase) ExpireStaleDonations(ctx context.Context) (int, error) {
+	log := logrus.New()
+
+	expired := 0
+	now := time.Now()
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	for {
+		donations, err := eu.donationRepository.GetPendingDonationsBefore(ctx, now.Add(-eu.config.PendingTTL), now, after, afterID, eu.config.ReconcileBatch)
+		if err != nil {
+			log.WithError(err).Error("Failed to get stale pending donations")
+			return expired, err
+		}
+
+		for _, donation := range donations {
+			if eu.expireDonation(ctx, &donation, now) {
+				expired++
+			}
+		}
+
+		if len(donations) < eu.config.ReconcileBatch {
+			break
+		}
+		last := donations[len(donations)-1]
+		after, afterID = &last.CreatedAt, last.ID
+	}
+
+	if expired > 0 {
+		log.Infof("Expired %d stale pending donations", expired)
+	}
+	return expired, nil
+}
+
+// expireDonation mengedaluwarsakan satu donasi dan mengembalikan true jika statusnya berubah
+func (eu *donationExpiryUsecase) expireDonation(ctx context.Context, donation *entities.Donation, now time.Time) bool {
+	log := logrus.New()
+
+	// Bukti transfer bisa saja masuk setelah donasi dibaca
+	if awaitingReview, err := eu.transferProof.HasPendingProof(ctx, donation.ID); err != nil || awaitingReview {
+		return false
+	}
+
+	orderID := donation.CurrentOrderID()
+
+	status, err := eu.paymentGateway.QueryStatus(ctx, orderID)
+	if err == nil {
+		if next, known := status.DonationStatus(); known && next != entities.DonationStatusPending {
+			if _, err := eu.donationUsecase.ApplyTransactionStatus(ctx, *status); err != nil {
+				log.WithError(err).Warnf("Failed to apply gateway status %s to order %s", status.TransactionStatus, orderID)
+			}
+			return false
+		}
+	}
+
+	if err := eu.paymentGateway.Cancel(ctx, orderID); err != nil {
+		log.WithError(err).Warnf("Failed to cancel %s transaction for order %s", eu.paymentGateway.Name(), orderID)
+	}
+
+	change, err := eu.donationUsecase.ApplyTransactionStatus(ctx, payment.TransactionStatus{
+		OrderID:           orderID,
+		TransactionStatus: "expire",
+		TransactionTime:   now.Format(payment.TimeLayout),
+		GrossAmount:       strconv.Itoa(donation.Amount),
+		StatusMessage:     "expired by scheduler",
+	})
+	if err != nil {
+		log.WithError(err).Warnf("Failed to expire donation %s", orderID)
+		return false
+	}
+	return change.Changed()
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"tugas-akhir/drivers/broker"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Jenis event feed donasi
+const (
+	DonationEventCreated  = "donation.created"
+	DonationEventPaid     = "donation.paid"
+	DonationEventFailed   = "donation.failed"
+	DonationEventRefunded = "donation.refunded"
+)
+
+// Topik broker untuk feed dashboard admin dan feed publik halaman depan
+const (
+	donationFeedAdminTopic  = "donations.admin"
+	donationFeedPublicTopic = "donations.public"
+)
+
+type DonationFeedUsecase interface {
+	Publish(ctx context.Context, eventType string, donation *entities.Donation)
+	PublishStatusChange(ctx context.Context, change *DonationStatusChange)
+	Subscribe(ctx context.Context, public bool) (<-chan broker.Event, error)
+}
+
+type donationFeedUsecase struct {
+	broker             broker.Broker
+	donationRepository repositories.DonationRepository
+	programDonation    repositories.ProgramDonationRepository
+}
+
+func NewDonationFeedUsecase(broker broker.Broker, donationRepository repositories.DonationRepository, programDonation repositories.ProgramDonationRepository) DonationFeedUsecase {
+	return &donationFeedUsecase{
+		broker:             broker,
+		donationRepository: donationRepository,
+		programDonation:    programDonation,
+	}
+}
+
+// Publish mengirim event donasi ke feed admin. Donasi yang lunas juga dikirim ke feed
+// publik tanpa identitas donatur. Kegagalan hanya dicatat agar alur donasi tidak terganggu.
+func (fu *donationFeedUsecase) Publish(ctx context.Context, eventType string, donation *entities.Donation) {
+	log := logrus.New()
+
+	programTitle := ""
+	if program, err := fu.programDonation.GetProgramDonationByID(ctx, donation.ProgramDonationID); err == nil {
+		programTitle = program.Title
+	}
+
+	err := fu.publish(ctx, donationFeedAdminTopic, eventType, dto.DonationEventData{
+		DonationID:     donation.ID.String(),
+		Name:           donation.Name,
+		Email:          donation.Email,
+		Amount:         donation.Amount,
+		RefundedAmount: donation.RefundedAmount,
+		Status:         int(donation.Status),
+		StatusLabel:    donation.Status.String(),
+		PaymentChannel: donation.PaymentChannel,
+		ProgramID:      donation.ProgramDonationID.String(),
+		ProgramTitle:   programTitle,
+	})
+	if err != nil {
+		log.WithError(err).Warnf("Failed to publish %s event for donation %s", eventType, donation.ID)
+	}
+
+	if eventType != DonationEventPaid {
+		return
+	}
+	err = fu.publish(ctx, donationFeedPublicTopic, eventType, dto.PublicDonationEventData{
+		Name:         donation.PublicName(),
+		Amount:       donation.Amount,
+		ProgramTitle: programTitle,
+	})
+	if err != nil {
+		log.WithError(err).Warnf("Failed to publish public %s event", eventType)
+	}
+}
+
+// PublishStatusChange mengirim event sesuai status baru donasi. Perubahan ke status
+// yang tidak punya event (misalnya challenge) diabaikan.
+func (fu *donationFeedUsecase) PublishStatusChange(ctx context.Context, change *DonationStatusChange) {
+	if change == nil || !change.Changed() {
+		return
+	}
+
+	var eventType string
+	switch change.To {
+	case entities.DonationStatusPaid:
+		eventType = DonationEventPaid
+	case entities.DonationStatusFailed, entities.DonationStatusExpired:
+		eventType = DonationEventFailed
+	case entities.DonationStatusRefunded, entities.DonationStatusPartiallyRefunded:
+		eventType = DonationEventRefunded
+	default:
+		return
+	}
+
+	donation, err := fu.donationRepository.GetDonationByID(ctx, change.DonationID)
+	if err != nil {
+		logrus.New().WithError(err).Warnf("Failed to load donation %s for feed", change.DonationID)
+		return
+	}
+	fu.Publish(ctx, eventType, donation)
+}
+
+func (fu *donationFeedUsecase) Subscribe(ctx context.Context, public bool) (<-chan broker.Event, error) {
+	if public {
+		return fu.broker.Subscribe(ctx, donationFeedPublicTopic)
+	}
+	return fu.broker.Subscribe(ctx, donationFeedAdminTopic)
+}
+
+func (fu *donationFeedUsecase) publish(ctx context.Context, topic string, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return fu.broker.Publish(ctx, topic, broker.Event{
+		ID:      uuid.New().String(),
+		Type:    eventType,
+		Payload: payload,
+		Time:    time.Now(),
+	})
+}
@@ -0,0 +1,227 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/mail"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	"tugas-akhir/utils/mailtemplate"
+
+	"github.com/sirupsen/logrus"
+)
+
+const mailDateLayout = "02-01-2006 15:04"
+
+// DonationMailer mengirim email transaksional donasi: link pembayaran saat donasi dibuat,
+// ucapan terima kasih dengan lampiran kuitansi saat lunas, kabar donasi kadaluarsa, dan
+// kabar donasi besar ke admin.
+// Email hanya dimasukkan ke antrean transport, kegagalan dicatat tanpa mengganggu alur donasi.
+type DonationMailer interface {
+	SendDonationCreated(ctx context.Context, donation *entities.Donation)
+	NotifyStatusChange(ctx context.Context, change *DonationStatusChange)
+}
+
+type donationMailer struct {
+	transport          mail.Transport
+	renderer           *mailtemplate.Renderer
+	donationRepository repositories.DonationRepository
+	programDonation    repositories.ProgramDonationRepository
+	adminRepository    repositories.AdminRepository
+	receiptUsecase     DonationReceiptUsecase
+	config             config.MailConfig
+	changes            chan DonationStatusChange
+}
+
+// NewDonationMailer menjalankan config.Workers worker yang menyusun email perubahan status
+// dari antrean berukuran config.QueueSize
+func NewDonationMailer(transport mail.Transport, renderer *mailtemplate.Renderer, donationRepository repositories.DonationRepository, programDonation repositories.ProgramDonationRepository, adminRepository repositories.AdminRepository, receiptUsecase DonationReceiptUsecase, config config.MailConfig) DonationMailer {
+	queueSize, workers := max(config.QueueSize, 1), max(config.Workers, 1)
+	m := &donationMailer{
+		transport:          transport,
+		renderer:           renderer,
+		donationRepository: donationRepository,
+		programDonation:    programDonation,
+		adminRepository:    adminRepository,
+		receiptUsecase:     receiptUsecase,
+		config:             config,
+		changes:            make(chan DonationStatusChange, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+func (m *donationMailer) SendDonationCreated(ctx context.Context, donation *entities.Donation) {
+	// Donasi offline dan transfer manual tidak punya link pembayaran
+	if donation.SnapURL == "" {
+		return
+	}
+	m.send(ctx, mailtemplate.DonationCreated, []string{donation.Email}, m.donationData(ctx, donation), donation, nil)
+}
+
+// NotifyStatusChange mengantrekan perubahan status donasi yang perlu dikabarkan lewat
+// email. Donasi, program, dan kuitansi baru dimuat dan dirender di worker, sehingga webhook
+// gateway tidak menunggu PDF kuitansi selesai dibuat.
+func (m *donationMailer) NotifyStatusChange(ctx context.Context, change *DonationStatusChange) {
+	if change == nil {
+		return
+	}
+	if change.DuplicateOrderID == "" && (!change.Changed() || change.To != entities.DonationStatusPaid && change.To != entities.DonationStatusExpired) {
+		return
+	}
+
+	select {
+	case m.changes <- *change:
+	default:
+		logrus.New().WithError(mail.ErrQueueFull).Errorf("Failed to queue %s email for donation %s", change.To, change.DonationID)
+	}
+}
+
+func (m *donationMailer) work() {
+	for change := range m.changes {
+		m.sendStatusChange(context.Background(), &change)
+	}
+}
+
+// sendStatusChange mengirim email sesuai status baru donasi. Donasi rutin yang
+// kadaluarsa tidak dikabari di sini karena sudah ditangani notifikasi donasi rutin.
+// Pembayaran ganda dikabarkan ke admin agar dananya dikembalikan.
+func (m *donationMailer) sendStatusChange(ctx context.Context, change *DonationStatusChange) {
+	if change.DuplicateOrderID != "" {
+		m.notifyDuplicatePayment(ctx, change)
+	}
+	if !change.Changed() {
+		return
+	}
+	if change.To != entities.DonationStatusPaid && change.To != entities.DonationStatusExpired {
+		return
+	}
+
+	donation, err := m.donationRepository.GetDonationByID(ctx, change.DonationID)
+	if err != nil {
+		logrus.New().WithError(err).Warnf("Failed to load donation %s for email", change.DonationID)
+		return
+	}
+	data := m.donationData(ctx, donation)
+
+	switch change.To {
+	case entities.DonationStatusPaid:
+		attachments := m.receiptAttachments(ctx, donation, &data)
+		m.send(ctx, mailtemplate.PaymentReceived, []string{donation.Email}, data, donation, attachments)
+		if m.config.LargeDonationAmount > 0 && donation.Amount >= m.config.LargeDonationAmount {
+			m.send(ctx, mailtemplate.LargeDonation, m.adminRecipients(ctx), data, donation, nil)
+		}
+	case entities.DonationStatusExpired:
+		if donation.SubscriptionID == nil {
+			m.send(ctx, mailtemplate.PaymentExpired, []string{donation.Email}, data, donation, nil)
+		}
+	}
+}
+
+func (m *donationMailer) notifyDuplicatePayment(ctx context.Context, change *DonationStatusChange) {
+	donation, err := m.donationRepository.GetDonationByID(ctx, change.DonationID)
+	if err != nil {
+		logrus.New().WithError(err).Errorf("Failed to load donation %s for duplicate payment email", change.DonationID)
+		return
+	}
+	data := mailtemplate.DuplicatePaymentData{
+		DonationData:     m.donationData(ctx, donation),
+		DuplicateOrderID: change.DuplicateOrderID,
+	}
+	m.send(ctx, mailtemplate.DuplicatePayment, m.adminRecipients(ctx), data, donation, nil)
+}
+
+// receiptAttachments melampirkan kuitansi PDF pada email donasi lunas dan mengisi nomor
+// kuitansinya di data template. Kuitansi yang belum terbit atau gagal dimuat tidak
+// menahan email; donatur tetap bisa mengunduhnya dari riwayat donasi.
+func (m *donationMailer) receiptAttachments(ctx context.Context, donation *entities.Donation, data *mailtemplate.DonationData) []mail.Attachment {
+	receipt, document, err := m.receiptUsecase.GetIssuedReceiptPDF(ctx, donation.ID)
+	if err != nil {
+		logrus.New().WithError(err).Warnf("Failed to load receipt for donation %s", donation.ID)
+		return nil
+	}
+	if receipt == nil {
+		return nil
+	}
+
+	data.ReceiptNumber = receipt.Number
+	return []mail.Attachment{{
+		Filename:    "kuitansi-" + strings.ReplaceAll(receipt.Number, "/", "-") + ".pdf",
+		ContentType: "application/pdf",
+		Data:        document,
+	}}
+}
+
+func (m *donationMailer) donationData(ctx context.Context, donation *entities.Donation) mailtemplate.DonationData {
+	programTitle := ""
+	if program, err := m.programDonation.GetProgramDonationByID(ctx, donation.ProgramDonationID); err == nil {
+		programTitle = program.Title
+	}
+
+	date := donation.CreatedAt
+	if donation.PaidAt != nil {
+		date = *donation.PaidAt
+	}
+
+	return mailtemplate.DonationData{
+		Name:           donation.Name,
+		Email:          donation.Email,
+		Amount:         formatRupiah(donation.Amount),
+		ProgramTitle:   programTitle,
+		OrderID:        donation.CurrentOrderID(),
+		PaymentURL:     donation.SnapURL,
+		PaymentChannel: donation.PaymentChannel,
+		Message:        donation.Message,
+		Date:           date.Format(mailDateLayout),
+	}
+}
+
+// adminRecipients memakai MAIL_ADMIN_RECIPIENTS, atau semua akun admin jika tidak diisi
+func (m *donationMailer) adminRecipients(ctx context.Context) []string {
+	if len(m.config.AdminRecipients) > 0 {
+		return m.config.AdminRecipients
+	}
+
+	emails, err := m.adminRepository.GetAdminEmails(ctx)
+	if err != nil {
+		logrus.New().WithError(err).Warn("Failed to get admin emails")
+		return nil
+	}
+	return emails
+}
+
+func (m *donationMailer) send(ctx context.Context, template string, to []string, data any, donation *entities.Donation, attachments []mail.Attachment) {
+	if err := sendMail(ctx, m.transport, m.renderer, template, to, data, attachments); err != nil {
+		logrus.New().WithError(err).Warnf("Failed to send %s email for donation %s", template, donation.ID)
+	}
+}
+
+// sendMail merender template lalu memasukkan email ke antrean transport. Email tanpa
+// penerima dilewati, misalnya donasi tamu yang tidak mengisi email.
+func sendMail(ctx context.Context, transport mail.Transport, renderer *mailtemplate.Renderer, template string, to []string, data any, attachments []mail.Attachment) error {
+	recipients := make([]string, 0, len(to))
+	for _, address := range to {
+		if address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	email, err := renderer.Render(template, data)
+	if err != nil {
+		return err
+	}
+
+	return transport.Send(ctx, mail.Message{
+		To:          recipients,
+		Subject:     email.Subject,
+		Text:        email.Text,
+		HTML:        email.HTML,
+		Attachments: attachments,
+	})
+}
@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/mail"
+	"tugas-akhir/entities"
+	"tugas-akhir/utils/mailtemplate"
+
+	"github.com/google/uuid"
+)
+
+func TestNotifyStatusChangeRendersReceiptInWorker(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	programs := &memProgramRepo{programs: map[uuid.UUID]entities.ProgramDonation{program.ID: program}}
+	paidAt := time.Now()
+	donation := entities.Donation{ID: uuid.New(), Name: "Budi", Email: "budi@example.com", Amount: 100000, Status: entities.DonationStatusPaid, ProgramDonationID: program.ID, PaidAt: &paidAt}
+	donations := &memDonationRepo{donations: map[uuid.UUID]entities.Donation{donation.ID: donation}}
+
+	receipts := &slowReceipts{release: make(chan struct{})}
+	transport := &channelTransport{messages: make(chan mail.Message, 1)}
+	mailer := NewDonationMailer(transport, mailtemplate.NewRenderer("Test"), donations, programs, nil, receipts, config.MailConfig{QueueSize: 1, Workers: 1})
+
+	// Kuitansi yang lambat dibuat tidak boleh menahan pemanggil, misalnya webhook gateway
+	queued := make(chan struct{})
+	go func() {
+		mailer.NotifyStatusChange(context.Background(), &DonationStatusChange{DonationID: donation.ID, From: entities.DonationStatusPending, To: entities.DonationStatusPaid})
+		close(queued)
+	}()
+	select {
+	case <-queued:
+	case <-time.After(time.Second):
+		t.Fatal("NotifyStatusChange waited for the receipt to render")
+	}
+
+	close(receipts.release)
+	select {
+	case message := <-transport.messages:
+		if len(message.To) != 1 || message.To[0] != donation.Email {
+			t.Errorf("email sent to %v, want %s", message.To, donation.Email)
+		}
+		if len(message.Attachments) != 1 {
+			t.Errorf("email has %d attachments, want the receipt", len(message.Attachments))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no email sent after the receipt was rendered")
+	}
+}
+
+// slowReceipts menahan pembuatan PDF kuitansi sampai release ditutup
+type slowReceipts struct {
+	DonationReceiptUsecase
+	release chan struct{}
+}
+
+func (r *slowReceipts) GetIssuedReceiptPDF(ctx context.Context, donationID uuid.UUID) (*entities.DonationReceipt, []byte, error) {
+	<-r.release
+	return &entities.DonationReceipt{ID: uuid.New(), DonationID: donationID, Number: "001/KW/2026"}, []byte("%PDF-1.4"), nil
+}
+
+type channelTransport struct {
+	messages chan mail.Message
+}
+
+func (t *channelTransport) Name() string {
+	return "test"
+}
+
+func (t *channelTransport) Send(ctx context.Context, message mail.Message) error {
+	t.messages <- message
+	return nil
+}
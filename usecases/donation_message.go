@@ -0,0 +1,256 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tugas-akhir/config"
+	dto_base "tugas-akhir/dto/base"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+	"tugas-akhir/utils/moderation"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// Jumlah pesan yang diperiksa setiap kali worker moderasi berjalan
+const moderationBatch = 200
+
+type DonationMessageUsecase interface {
+	ScreenMessages(ctx context.Context)
+	GetMessages(c echo.Context, status string, req *dto_base.PaginationRequest) (*[]dto.DonationMessageResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
+	ApproveMessage(c echo.Context, donationID uuid.UUID, adminID uuid.UUID) (*dto.DonationMessageResponse, error)
+	HideMessage(c echo.Context, donationID uuid.UUID, adminID uuid.UUID) (*dto.DonationMessageResponse, error)
+	EditMessage(c echo.Context, donationID uuid.UUID, adminID uuid.UUID, req *dto.EditMessageRequest) (*dto.DonationMessageResponse, error)
+	GetPrayers(c echo.Context, programID *uuid.UUID, req *dto_base.PaginationRequest) (*[]dto.PrayerResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
+}
+
+type donationMessageUsecase struct {
+	donationRepository repositories.DonationRepository
+	messageRepository  repositories.DonationMessageRepository
+	transactionManager repositories.TransactionManager
+	filter             *moderation.Filter
+	running            sync.Mutex
+}
+
+func NewDonationMessageUsecase(donationRepository repositories.DonationRepository, messageRepository repositories.DonationMessageRepository, transactionManager repositories.TransactionManager, moderationConfig config.ModerationConfig) DonationMessageUsecase {
+	return &donationMessageUsecase{
+		donationRepository: donationRepository,
+		messageRepository:  messageRepository,
+		transactionManager: transactionManager,
+		filter:             moderation.NewFilter(moderationConfig.BlockedWords, moderationConfig.BlockLinks),
+	}
+}
+
+// ScreenMessages dijalankan scheduler: memeriksa pesan donasi yang masih pending dengan
+// filter kata dan tautan. Pesan aman langsung disetujui, sisanya masuk antrean admin.
+func (mu *donationMessageUsecase) ScreenMessages(ctx context.Context) {
+	if !mu.running.TryLock() {
+		return
+	}
+	defer mu.running.Unlock()
+
+	log := logrus.New()
+
+	donations, err := mu.messageRepository.GetPendingMessages(ctx, moderationBatch)
+	if err != nil {
+		log.WithError(err).Error("Failed to get donation messages to screen")
+		return
+	}
+
+	flagged := 0
+	for i := range donations {
+		donationID := donations[i].ID
+		err := mu.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			donation, err := mu.donationRepository.FindByIdForUpdate(ctx, donationID)
+			if err != nil {
+				return err
+			}
+			// Pesan yang sudah ditinjau admin sejak diambil tidak diperiksa ulang
+			if donation.MessageStatus != entities.MessagePending {
+				return nil
+			}
+
+			donation.PublicMessage = strings.TrimSpace(donation.Message)
+			donation.MessageStatus = entities.MessageApproved
+			if reason := mu.filter.Check(donation.Message); reason != "" {
+				donation.MessageStatus = entities.MessageFlagged
+				donation.MessageFlag = reason
+				flagged++
+			}
+			return mu.messageRepository.UpdateModeration(ctx, donation)
+		})
+		if err != nil {
+			log.WithError(err).Warnf("Failed to screen message of donation %s", donationID)
+		}
+	}
+
+	if flagged > 0 {
+		log.Infof("Flagged %d donation messages for review", flagged)
+	}
+}
+
+func (mu *donationMessageUsecase) GetMessages(c echo.Context, status string, req *dto_base.PaginationRequest) (*[]dto.DonationMessageResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
+	ctx := c.Request().Context()
+
+	if status == "" {
+		status = entities.MessageFlagged
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	donations, totalData, err := mu.messageRepository.GetMessages(ctx, status, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	totalPage := int(math.Ceil(float64(totalData) / float64(req.Limit)))
+	if totalData > 0 && req.Page > totalPage {
+		return nil, nil, nil, err_util.ErrPageNotFound
+	}
+
+	responses := []dto.DonationMessageResponse{}
+	for i := range donations {
+		responses = append(responses, toDonationMessageResponse(&donations[i]))
+	}
+
+	metadata, link := paginationResult(c, req, "status="+status, totalData, totalPage)
+	return &responses, metadata, link, nil
+}
+
+func (mu *donationMessageUsecase) ApproveMessage(c echo.Context, donationID uuid.UUID, adminID uuid.UUID) (*dto.DonationMessageResponse, error) {
+	return mu.reviewMessage(c, donationID, adminID, func(donation *entities.Donation) {
+		if donation.PublicMessage == "" {
+			donation.PublicMessage = strings.TrimSpace(donation.Message)
+		}
+		donation.MessageStatus = entities.MessageApproved
+	})
+}
+
+func (mu *donationMessageUsecase) HideMessage(c echo.Context, donationID uuid.UUID, adminID uuid.UUID) (*dto.DonationMessageResponse, error) {
+	return mu.reviewMessage(c, donationID, adminID, func(donation *entities.Donation) {
+		donation.MessageStatus = entities.MessageHidden
+	})
+}
+
+// EditMessage mengganti pesan yang tampil di halaman publik lalu menyetujuinya.
+// Pesan asli donatur tetap tersimpan di Message.
+func (mu *donationMessageUsecase) EditMessage(c echo.Context, donationID uuid.UUID, adminID uuid.UUID, req *dto.EditMessageRequest) (*dto.DonationMessageResponse, error) {
+	return mu.reviewMessage(c, donationID, adminID, func(donation *entities.Donation) {
+		donation.PublicMessage = strings.TrimSpace(req.Message)
+		donation.MessageStatus = entities.MessageApproved
+	})
+}
+
+// reviewMessage mengunci donasi, menerapkan keputusan admin, dan mencatat peninjaunya
+func (mu *donationMessageUsecase) reviewMessage(c echo.Context, donationID uuid.UUID, adminID uuid.UUID, apply func(donation *entities.Donation)) (*dto.DonationMessageResponse, error) {
+	var donation *entities.Donation
+	err := mu.transactionManager.WithinTransaction(c.Request().Context(), func(ctx context.Context) error {
+		var err error
+		donation, err = mu.donationRepository.FindByIdForUpdate(ctx, donationID)
+		if err != nil {
+			return err
+		}
+
+		apply(donation)
+		now := time.Now()
+		donation.MessageReviewedBy = &adminID
+		donation.MessageReviewedAt = &now
+		return mu.messageRepository.UpdateModeration(ctx, donation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toDonationMessageResponse(donation)
+	return &response, nil
+}
+
+// GetPrayers mengambil dinding doa: pesan donatur yang sudah disetujui, terbaru lebih dulu
+func (mu *donationMessageUsecase) GetPrayers(c echo.Context, programID *uuid.UUID, req *dto_base.PaginationRequest) (*[]dto.PrayerResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
+	ctx := c.Request().Context()
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	donations, totalData, err := mu.messageRepository.GetPrayers(ctx, programID, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	totalPage := int(math.Ceil(float64(totalData) / float64(req.Limit)))
+	if totalData > 0 && req.Page > totalPage {
+		return nil, nil, nil, err_util.ErrPageNotFound
+	}
+
+	responses := []dto.PrayerResponse{}
+	for _, donation := range donations {
+		responses = append(responses, dto.PrayerResponse{
+			Name:      donation.PublicName(),
+			Message:   donation.PublicMessageText(),
+			CreatedAt: donation.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	filter := ""
+	if programID != nil {
+		filter = "program_id=" + programID.String()
+	}
+	metadata, link := paginationResult(c, req, filter, totalData, totalPage)
+	return &responses, metadata, link, nil
+}
+
+// paginationResult menyusun metadata dan link halaman sebelum/berikutnya. Filter ikut
+// disertakan pada link agar halaman berikutnya memakai filter yang sama.
+func paginationResult(c echo.Context, req *dto_base.PaginationRequest, filter string, totalData int64, totalPage int) (*dto_base.PaginationMetadata, *dto_base.Link) {
+	baseURL := fmt.Sprintf("%s?limit=%d&page=", c.Request().URL.Path, req.Limit)
+	if filter != "" {
+		baseURL = fmt.Sprintf("%s?%s&limit=%d&page=", c.Request().URL.Path, filter, req.Limit)
+	}
+
+	link := &dto_base.Link{}
+	if req.Page > 1 {
+		link.Prev = baseURL + strconv.Itoa(req.Page-1)
+	}
+	if req.Page < totalPage {
+		link.Next = baseURL + strconv.Itoa(req.Page+1)
+	}
+
+	return &dto_base.PaginationMetadata{
+		TotalData:   totalData,
+		TotalPage:   totalPage,
+		CurrentPage: req.Page,
+	}, link
+}
+
+func toDonationMessageResponse(donation *entities.Donation) dto.DonationMessageResponse {
+	response := dto.DonationMessageResponse{
+		DonationID:     donation.ID.String(),
+		Name:           donation.Name,
+		PublicName:     donation.PublicName(),
+		Amount:         donation.Amount,
+		DonationStatus: donation.Status.String(),
+		Message:        donation.Message,
+		PublicMessage:  donation.PublicMessage,
+		Status:         donation.MessageStatus,
+		Flag:           donation.MessageFlag,
+		CreatedAt:      donation.CreatedAt.Format(time.RFC3339),
+	}
+	if donation.MessageReviewedBy != nil {
+		response.ReviewedBy = donation.MessageReviewedBy.String()
+	}
+	if donation.MessageReviewedAt != nil {
+		response.ReviewedAt = donation.MessageReviewedAt.Format(time.RFC3339)
+	}
+	return response
+}
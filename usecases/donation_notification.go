@@ -0,0 +1,163 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+	dto_base "tugas-akhir/dto/base"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type DonationNotificationUsecase interface {
+	GetNotifications(c echo.Context, adminID uuid.UUID, req *dto_base.CursorPaginationRequest) (*[]dto.DonaturNotifikasiResponse, *dto_base.CursorMetadata, error)
+	CountUnread(c echo.Context, adminID uuid.UUID) (*dto.UnreadNotificationResponse, error)
+	MarkRead(c echo.Context, adminID uuid.UUID, notificationID uuid.UUID) error
+	MarkAllRead(c echo.Context, adminID uuid.UUID) (*dto.MarkAllReadResponse, error)
+}
+
+type donationNotificationUsecase struct {
+	notificationRepository repositories.DonationNotificationRepository
+}
+
+func NewDonationNotificationUsecase(notificationRepository repositories.DonationNotificationRepository) DonationNotificationUsecase {
+	return &donationNotificationUsecase{
+		notificationRepository: notificationRepository,
+	}
+}
+
+// GetNotifications mengambil notifikasi donasi lunas terbaru lebih dulu beserta status
+// baca admin. Satu baris tambahan diambil untuk mengetahui masih ada halaman berikutnya.
+func (nu *donationNotificationUsecase) GetNotifications(c echo.Context, adminID uuid.UUID, req *dto_base.CursorPaginationRequest) (*[]dto.DonaturNotifikasiResponse, *dto_base.CursorMetadata, error) {
+	ctx := c.Request().Context()
+
+	var (
+		before   *time.Time
+		beforeID uuid.UUID
+	)
+	if req.Cursor != "" {
+		createdAt, id, err := decodeNotificationCursor(req.Cursor)
+		if err != nil {
+			return nil, nil, err_util.ErrInvalidCursor
+		}
+		before, beforeID = &createdAt, id
+	}
+
+	notifications, err := nu.notificationRepository.GetNotifications(ctx, adminID, before, beforeID, req.Limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata := &dto_base.CursorMetadata{}
+	if len(notifications) > req.Limit {
+		notifications = notifications[:req.Limit]
+		last := notifications[len(notifications)-1]
+		metadata.HasMore = true
+		metadata.NextCursor = encodeNotificationCursor(last.CreatedAt, last.ID)
+	}
+
+	donationIDs := make([]uuid.UUID, 0, len(notifications))
+	for _, notification := range notifications {
+		donationIDs = append(donationIDs, notification.DonationID)
+	}
+	allocations, err := nu.notificationRepository.GetAllocations(ctx, donationIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	allocationsByDonation := map[uuid.UUID][]dto.AllocationResponse{}
+	for _, allocation := range allocations {
+		allocationsByDonation[allocation.DonationID] = append(allocationsByDonation[allocation.DonationID], dto.AllocationResponse{
+			ProgramID:    allocation.ProgramDonationID.String(),
+			ProgramTitle: allocation.ProgramTitle,
+			Amount:       allocation.Amount,
+		})
+	}
+
+	responses := []dto.DonaturNotifikasiResponse{}
+	for _, notification := range notifications {
+		responses = append(responses, toNotificationResponse(notification, allocationsByDonation[notification.DonationID]))
+	}
+	return &responses, metadata, nil
+}
+
+func (nu *donationNotificationUsecase) CountUnread(c echo.Context, adminID uuid.UUID) (*dto.UnreadNotificationResponse, error) {
+	unread, err := nu.notificationRepository.CountUnread(c.Request().Context(), adminID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UnreadNotificationResponse{Unread: unread}, nil
+}
+
+func (nu *donationNotificationUsecase) MarkRead(c echo.Context, adminID uuid.UUID, notificationID uuid.UUID) error {
+	return nu.notificationRepository.MarkRead(c.Request().Context(), adminID, notificationID)
+}
+
+func (nu *donationNotificationUsecase) MarkAllRead(c echo.Context, adminID uuid.UUID) (*dto.MarkAllReadResponse, error) {
+	marked, err := nu.notificationRepository.MarkAllRead(c.Request().Context(), adminID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MarkAllReadResponse{Marked: marked}, nil
+}
+
+// toNotificationResponse menyusun respons notifikasi. Donasi lama tanpa baris alokasi
+// dianggap seluruhnya untuk program utamanya.
+func toNotificationResponse(notification entities.DonationNotificationSummary, allocations []dto.AllocationResponse) dto.DonaturNotifikasiResponse {
+	if len(allocations) == 0 {
+		allocations = []dto.AllocationResponse{{
+			ProgramID:    notification.ProgramDonationID.String(),
+			ProgramTitle: notification.ProgramTitle,
+			Amount:       notification.Amount,
+		}}
+	}
+
+	response := dto.DonaturNotifikasiResponse{
+		ID:              notification.ID.String(),
+		DonationID:      notification.DonationID.String(),
+		Name:            notification.Name,
+		Amount:          notification.GrossAmount,
+		ProgramDonation: notification.ProgramTitle,
+		Message:         notification.Message,
+		Date:            notification.CreatedAt.Format("2006-01-02"),
+		Status:          notification.TransactionStatus,
+		Read:            notification.ReadAt != nil,
+		CreatedAt:       notification.CreatedAt.Format(time.RFC3339),
+		Allocations:     allocations,
+	}
+	if notification.ReadAt != nil {
+		response.ReadAt = notification.ReadAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// Cursor berisi waktu dan ID notifikasi terakhir pada halaman sebelumnya. ID ikut
+// disimpan agar notifikasi dengan waktu yang sama tidak terlewat atau terulang.
+func encodeNotificationCursor(createdAt time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + id.String()))
+}
+
+func decodeNotificationCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, err_util.ErrInvalidCursor
+	}
+	parsedTime, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return parsedTime, parsedID, nil
+}
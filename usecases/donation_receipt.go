@@ -0,0 +1,336 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"tugas-akhir/config"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+	"tugas-akhir/utils/pdf"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const receiptDateLayout = "02-01-2006"
+
+type DonationReceiptUsecase interface {
+	IssueReceipt(ctx context.Context, donation *entities.Donation, allocations []entities.DonationAllocation) (*entities.DonationReceipt, error)
+	GetIssuedReceiptPDF(ctx context.Context, donationID uuid.UUID) (*entities.DonationReceipt, []byte, error)
+	GetReceipt(ctx context.Context, donationID uuid.UUID) (*entities.DonationReceipt, error)
+	GetReceiptPDF(c echo.Context, donationID uuid.UUID) (*entities.DonationReceipt, []byte, error)
+	GetDonorReceiptPDF(c echo.Context, userID uuid.UUID, donationID uuid.UUID) (*entities.DonationReceipt, []byte, error)
+	VerifyReceipt(c echo.Context, number string, code string) (*dto.ReceiptVerificationResponse, error)
+}
+
+type donationReceiptUsecase struct {
+	receiptRepository            repositories.DonationReceiptRepository
+	donationRepository           repositories.DonationRepository
+	programDonation              repositories.ProgramDonationRepository
+	donationAllocationRepository repositories.DonationAllocationRepository
+	transactionManager           repositories.TransactionManager
+	config                       config.ReceiptConfig
+}
+
+func NewDonationReceiptUsecase(receiptRepository repositories.DonationReceiptRepository, donationRepository repositories.DonationRepository, programDonation repositories.ProgramDonationRepository, donationAllocationRepository repositories.DonationAllocationRepository, transactionManager repositories.TransactionManager, config config.ReceiptConfig) DonationReceiptUsecase {
+	return &donationReceiptUsecase{
+		receiptRepository:            receiptRepository,
+		donationRepository:           donationRepository,
+		programDonation:              programDonation,
+		donationAllocationRepository: donationAllocationRepository,
+		transactionManager:           transactionManager,
+		config:                       config,
+	}
+}
+
+// IssueReceipt menerbitkan kuitansi untuk donasi yang baru lunas. Harus dipanggil di dalam
+// transaksi pelunasan agar nomor kuitansi ikut dibatalkan jika pelunasan gagal.
+func (ru *donationReceiptUsecase) IssueReceipt(ctx context.Context, donation *entities.Donation, allocations []entities.DonationAllocation) (*entities.DonationReceipt, error) {
+	existing, err := ru.receiptRepository.GetReceiptByDonationID(ctx, donation.ID)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	titles := make([]string, 0, len(allocations))
+	for _, allocation := range allocations {
+		program, err := ru.programDonation.GetProgramDonationByID(ctx, allocation.ProgramDonationID)
+		if err != nil {
+			return nil, err
+		}
+		titles = append(titles, program.Title)
+	}
+
+	code, err := generateVerificationCode()
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt := time.Now()
+	sequence, err := ru.receiptRepository.NextSequence(ctx, issuedAt.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	paidAt := issuedAt
+	if donation.PaidAt != nil {
+		paidAt = *donation.PaidAt
+	}
+
+	receipt := entities.DonationReceipt{
+		ID:               uuid.New(),
+		DonationID:       donation.ID,
+		Year:             issuedAt.Year(),
+		Sequence:         sequence,
+		Number:           fmt.Sprintf("%s/%d/%06d", ru.config.NumberPrefix, issuedAt.Year(), sequence),
+		VerificationCode: code,
+		DonorName:        donation.Name,
+		DonorEmail:       donation.Email,
+		DonorAddress:     donation.Address,
+		Amount:           donation.Amount,
+		ProgramTitles:    strings.Join(titles, ", "),
+		PaymentChannel:   donation.PaymentChannel,
+		PaidAt:           paidAt,
+		IssuedAt:         issuedAt,
+		CreatedAt:        issuedAt,
+	}
+	if err := ru.receiptRepository.CreateReceipt(ctx, &receipt); err != nil {
+		return nil, err
+	}
+
+	logrus.New().Infof("Receipt %s issued for donation %s", receipt.Number, donation.ID)
+	return &receipt, nil
+}
+
+// GetIssuedReceiptPDF mengembalikan kuitansi yang sudah terbit beserta PDF-nya untuk
+// dilampirkan pada email donasi lunas. Receipt nil berarti kuitansi belum terbit.
+func (ru *donationReceiptUsecase) GetIssuedReceiptPDF(ctx context.Context, donationID uuid.UUID) (*entities.DonationReceipt, []byte, error) {
+	receipt, err := ru.receiptRepository.GetReceiptByDonationID(ctx, donationID)
+	if err != nil || receipt == nil {
+		return nil, nil, err
+	}
+	return receipt, ru.renderReceipt(receipt), nil
+}
+
+// GetReceipt mengembalikan kuitansi donasi, atau nil jika belum terbit
+func (ru *donationReceiptUsecase) GetReceipt(ctx context.Context, donationID uuid.UUID) (*entities.DonationReceipt, error) {
+	return ru.receiptRepository.GetReceiptByDonationID(ctx, donationID)
+}
+
+// GetReceiptPDF mengembalikan kuitansi donasi dalam bentuk PDF. Donasi yang sudah lunas
+// sebelum fitur kuitansi ada akan diterbitkan kuitansinya saat pertama kali diminta.
+func (ru *donationReceiptUsecase) GetReceiptPDF(c echo.Context, donationID uuid.UUID) (*entities.DonationReceipt, []byte, error) {
+	ctx := c.Request().Context()
+
+	receipt, err := ru.receiptRepository.GetReceiptByDonationID(ctx, donationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if receipt == nil {
+		err = ru.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			donation, err := ru.donationRepository.FindByIdForUpdate(ctx, donationID)
+			if err != nil {
+				return gorm.ErrRecordNotFound
+			}
+			if donation.Status != entities.DonationStatusPaid && donation.Status != entities.DonationStatusPartiallyRefunded {
+				return err_util.ErrReceiptNotAvailable
+			}
+
+			allocations, err := loadAllocations(ctx, ru.donationAllocationRepository, donation)
+			if err != nil {
+				return err
+			}
+			receipt, err = ru.IssueReceipt(ctx, donation, allocations)
+			return err
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return receipt, ru.renderReceipt(receipt), nil
+}
+
+// GetDonorReceiptPDF mengembalikan kuitansi PDF hanya untuk donasi milik akun donatur.
+// Donasi milik orang lain diperlakukan seperti donasi yang tidak ada.
+func (ru *donationReceiptUsecase) GetDonorReceiptPDF(c echo.Context, userID uuid.UUID, donationID uuid.UUID) (*entities.DonationReceipt, []byte, error) {
+	donation, err := ru.donationRepository.GetDonationByID(c.Request().Context(), donationID)
+	if err != nil {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	if donation.UserID == nil || *donation.UserID != userID {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	return ru.GetReceiptPDF(c, donationID)
+}
+
+// VerifyReceipt memeriksa pasangan nomor kuitansi dan kode verifikasinya
+func (ru *donationReceiptUsecase) VerifyReceipt(c echo.Context, number string, code string) (*dto.ReceiptVerificationResponse, error) {
+	ctx := c.Request().Context()
+
+	receipt, err := ru.receiptRepository.GetReceiptByNumber(ctx, strings.TrimSpace(number))
+	if err != nil {
+		return nil, err_util.ErrInvalidReceipt
+	}
+
+	if subtle.ConstantTimeCompare([]byte(normalizeVerificationCode(code)), []byte(receipt.VerificationCode)) != 1 {
+		return nil, err_util.ErrInvalidReceipt
+	}
+
+	donation, err := ru.donationRepository.GetDonationByID(ctx, receipt.DonationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReceiptVerificationResponse{
+		Valid:          true,
+		Number:         receipt.Number,
+		DonorName:      receipt.DonorName,
+		Amount:         receipt.Amount,
+		ProgramTitles:  receipt.ProgramTitles,
+		PaidAt:         receipt.PaidAt.Format(receiptDateLayout),
+		IssuedAt:       receipt.IssuedAt.Format(receiptDateLayout),
+		DonationStatus: donation.Status.String(),
+	}, nil
+}
+
+func (ru *donationReceiptUsecase) renderReceipt(receipt *entities.DonationReceipt) []byte {
+	doc := pdf.New()
+	left, right := 60.0, pdf.PageWidth-60
+
+	y := 70.0
+	doc.Text(left, y, 18, true, ru.config.FoundationName)
+	for _, line := range []string{ru.config.FoundationAddress, contactLine(ru.config), taxLine(ru.config)} {
+		if line == "" {
+			continue
+		}
+		y += 15
+		doc.Text(left, y, 9, false, line)
+	}
+	y += 15
+	doc.Line(left, y, right, y, 1)
+
+	y += 35
+	doc.Text(left, y, 15, true, "KUITANSI DONASI")
+	doc.TextRight(right, y, 10, false, "No. "+receipt.Number)
+
+	y += 30
+	rows := [][2]string{
+		{"Telah terima dari", receipt.DonorName},
+		{"Email", receipt.DonorEmail},
+		{"Alamat", receipt.DonorAddress},
+		{"Untuk program", receipt.ProgramTitles},
+		{"Metode pembayaran", receipt.PaymentChannel},
+		{"Tanggal pembayaran", receipt.PaidAt.Format(receiptDateLayout)},
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		doc.Text(left, y, 10, false, row[0])
+		doc.Text(left+130, y, 10, false, ": "+row[1])
+		y += 20
+	}
+
+	y += 10
+	doc.Line(left, y, right, y, 0.5)
+	y += 25
+	doc.Text(left, y, 12, true, "Jumlah")
+	doc.TextRight(right, y, 12, true, formatRupiah(receipt.Amount))
+	y += 15
+	doc.Line(left, y, right, y, 0.5)
+
+	y += 35
+	doc.Text(left, y, 10, false, "Terima kasih atas donasi Anda. Semoga menjadi amal kebaikan.")
+	y += 40
+	doc.TextRight(right, y, 10, false, "Diterbitkan "+receipt.IssuedAt.Format(receiptDateLayout))
+	y += 15
+	doc.TextRight(right, y, 10, true, ru.config.FoundationName)
+
+	y = pdf.PageHeight - 70
+	doc.Line(left, y, right, y, 0.5)
+	y += 15
+	doc.Text(left, y, 8, false, "Kode verifikasi: "+formatVerificationCode(receipt.VerificationCode))
+	if ru.config.VerifyURL != "" {
+		y += 12
+		query := url.Values{"number": {receipt.Number}, "code": {receipt.VerificationCode}}
+		doc.Text(left, y, 8, false, "Periksa keaslian kuitansi di "+ru.config.VerifyURL+"?"+query.Encode())
+	}
+	y += 12
+	doc.Text(left, y, 8, false, "Kuitansi ini dibuat secara elektronik dan sah tanpa tanda tangan.")
+
+	return doc.Bytes()
+}
+
+func contactLine(cfg config.ReceiptConfig) string {
+	parts := []string{}
+	if cfg.FoundationPhone != "" {
+		parts = append(parts, "Telp. "+cfg.FoundationPhone)
+	}
+	if cfg.FoundationEmail != "" {
+		parts = append(parts, cfg.FoundationEmail)
+	}
+	return strings.Join(parts, " | ")
+}
+
+func taxLine(cfg config.ReceiptConfig) string {
+	if cfg.FoundationTaxID == "" {
+		return ""
+	}
+	return "NPWP " + cfg.FoundationTaxID
+}
+
+// formatRupiah menulis nominal dengan pemisah ribuan titik, contoh Rp 1.250.000
+func formatRupiah(amount int) string {
+	digits := strconv.Itoa(amount)
+	if amount < 0 {
+		digits = digits[1:]
+	}
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+
+	if amount < 0 {
+		return "-Rp " + b.String()
+	}
+	return "Rp " + b.String()
+}
+
+func generateVerificationCode() (string, error) {
+	code := make([]byte, 10)
+	if _, err := rand.Read(code); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(code), nil
+}
+
+// formatVerificationCode membagi kode menjadi kelompok empat karakter agar mudah dibaca
+func formatVerificationCode(code string) string {
+	groups := []string{}
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+	return strings.Join(append(groups, code), "-")
+}
+
+func normalizeVerificationCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
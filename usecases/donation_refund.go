@@ -0,0 +1,327 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/payment"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type DonationRefundUsecase interface {
+	RefundDonation(c echo.Context, donationID uuid.UUID, adminID uuid.UUID, req *dto.RefundRequest) (*dto.RefundResponse, error)
+	GetRefunds(c echo.Context, donationID uuid.UUID) (*[]dto.RefundResponse, error)
+	ResumePendingRefunds(ctx context.Context) (int, error)
+}
+
+type donationRefundUsecase struct {
+	donationRepository       repositories.DonationRepository
+	programDonation          repositories.ProgramDonationRepository
+	donationRefundRepository repositories.DonationRefundRepository
+	allocationRepository     repositories.DonationAllocationRepository
+	paymentGateway           payment.PaymentGateway
+	transactionManager       repositories.TransactionManager
+	donationFeedUsecase      DonationFeedUsecase
+	config                   config.DonationConfig
+}
+
+func NewDonationRefundUsecase(donationRepository repositories.DonationRepository, programDonation repositories.ProgramDonationRepository, donationRefundRepository repositories.DonationRefundRepository, allocationRepository repositories.DonationAllocationRepository, paymentGateway payment.PaymentGateway, transactionManager repositories.TransactionManager, donationFeedUsecase DonationFeedUsecase, config config.DonationConfig) DonationRefundUsecase {
+	return &donationRefundUsecase{
+		donationRepository:       donationRepository,
+		programDonation:          programDonation,
+		donationRefundRepository: donationRefundRepository,
+		allocationRepository:     allocationRepository,
+		paymentGateway:           paymentGateway,
+		transactionManager:       transactionManager,
+		donationFeedUsecase:      donationFeedUsecase,
+		config:                   config,
+	}
+}
+
+// RefundDonation mengembalikan sebagian atau seluruh dana donasi. Amount 0 berarti
+// seluruh sisa dana yang belum dikembalikan. Refund gateway berjalan dalam dua langkah:
+// refund dicatat pending dan nominalnya dipesan, gateway dipanggil di luar transaksi,
+// lalu dana donasi dikurangi saat refund diselesaikan. Refund yang terhenti setelah
+// dicatat dilanjutkan oleh ResumePendingRefunds dengan refund key yang sama.
+func (ru *donationRefundUsecase) RefundDonation(c echo.Context, donationID uuid.UUID, adminID uuid.UUID, req *dto.RefundRequest) (*dto.RefundResponse, error) {
+	log := logrus.New()
+	ctx := c.Request().Context()
+
+	var (
+		refund   entities.DonationRefund
+		donation *entities.Donation
+	)
+
+	err := ru.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		donation, err = ru.donationRepository.FindByIdForUpdate(ctx, donationID)
+		if err != nil {
+			return err
+		}
+
+		if !donation.Status.IsCollected() {
+			return err_util.ErrDonationNotRefundable
+		}
+
+		// Nominal refund gateway yang belum selesai sudah dipesan
+		pending, err := ru.donationRefundRepository.GetPendingAmount(ctx, donation.ID)
+		if err != nil {
+			return err
+		}
+
+		refundable := donation.Amount - donation.RefundedAmount - pending
+		amount := req.Amount
+		if amount == 0 {
+			amount = refundable
+		}
+		if amount <= 0 || amount > refundable {
+			return err_util.ErrInvalidRefundAmount
+		}
+
+		now := time.Now()
+		refund = entities.DonationRefund{
+			ID:         uuid.New(),
+			DonationID: donation.ID,
+			Amount:     amount,
+			Reason:     req.Reason,
+			Method:     entities.RefundMethodManual,
+			Status:     entities.RefundStatusCompleted,
+			AdminID:    adminID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+
+		if !req.Manual {
+			refund.Method = entities.RefundMethodGateway
+			refund.Status = entities.RefundStatusPending
+			refund.RefundKey = refund.ID.String()
+			return ru.donationRefundRepository.CreateRefund(ctx, &refund)
+		}
+
+		// Refund manual sudah dikembalikan di luar sistem sehingga langsung diterapkan
+		if err := ru.applyRefund(ctx, donation, amount); err != nil {
+			return err
+		}
+		return ru.donationRefundRepository.CreateRefund(ctx, &refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Refund manual langsung mengurangi dana donasi, refund gateway baru setelah selesai
+	applied := refund.Status == entities.RefundStatusCompleted
+	if refund.Status == entities.RefundStatusPending {
+		completed, updated, refundApplied, err := ru.processRefund(ctx, &refund, donation.CurrentOrderID())
+		switch {
+		case errors.Is(err, errRefundLeftPending):
+			// Hasil gateway belum pasti, refund dilanjutkan ResumePendingRefunds
+			log.WithError(err).Warnf("Refund %s of donation %s left pending", refund.ID, donation.ID)
+		case err != nil:
+			return nil, err
+		default:
+			refund, donation, applied = *completed, updated, refundApplied
+		}
+	}
+
+	log.Infof("Donation %s refunded %d by admin %s (%s, %s)", donation.ID, refund.Amount, adminID, refund.Method, refund.Status)
+	if applied {
+		ru.donationFeedUsecase.Publish(ctx, DonationEventRefunded, donation)
+	}
+	response := toRefundResponse(refund, donation)
+	return &response, nil
+}
+
+// ResumePendingRefunds melanjutkan refund gateway yang tertinggal di status pending,
+// misalnya karena proses berhenti setelah gateway dipanggil. Gateway dipanggil ulang
+// dengan refund key yang sama sehingga dana tidak dikembalikan dua kali.
+func (ru *donationRefundUsecase) ResumePendingRefunds(ctx context.Context) (int, error) {
+	log := logrus.New()
+
+	refunds, err := ru.donationRefundRepository.GetPendingRefundsBefore(ctx, time.Now().Add(-ru.config.ReconcileMinAge), ru.config.ReconcileBatch)
+	if err != nil {
+		log.WithError(err).Error("Failed to load pending refunds")
+		return 0, err
+	}
+
+	resumed := 0
+	for i := range refunds {
+		refund := &refunds[i]
+		donation, err := ru.donationRepository.GetDonationByID(ctx, refund.DonationID)
+		if err != nil {
+			log.WithError(err).Errorf("Failed to load donation of refund %s", refund.ID)
+			continue
+		}
+
+		completed, donation, applied, err := ru.processRefund(ctx, refund, donation.CurrentOrderID())
+		if err != nil {
+			log.WithError(err).Errorf("Failed to resume refund %s", refund.ID)
+			continue
+		}
+		resumed++
+		log.Infof("Resumed refund %s of donation %s (%s)", completed.ID, donation.ID, completed.Status)
+		if applied {
+			ru.donationFeedUsecase.Publish(ctx, DonationEventRefunded, donation)
+		}
+	}
+
+	if resumed > 0 {
+		log.Infof("Resumed %d pending refunds", resumed)
+	}
+	return resumed, nil
+}
+
+// errRefundLeftPending menandai refund gateway yang hasilnya belum pasti, misalnya karena
+// timeout, sehingga tetap pending untuk dicoba ulang dengan refund key yang sama
+var errRefundLeftPending = errors.New("refund left pending")
+
+// processRefund memanggil gateway untuk refund pending lalu menyelesaikannya. Hanya refund
+// yang pasti ditolak gateway yang ditandai failed sehingga nominalnya bisa di-refund ulang.
+// Error gateway lain dan kegagalan penyelesaian membiarkan refund tetap pending untuk
+// dilanjutkan ResumePendingRefunds. Nilai applied bernilai true jika refund ini yang
+// mengurangi dana donasi, bukan webhook refund yang datang lebih dulu.
+func (ru *donationRefundUsecase) processRefund(ctx context.Context, refund *entities.DonationRefund, orderID string) (*entities.DonationRefund, *entities.Donation, bool, error) {
+	log := logrus.New()
+	refundID, refundKey := refund.ID, refund.RefundKey
+
+	result, gatewayErr := ru.paymentGateway.Refund(ctx, orderID, payment.RefundRequest{
+		RefundKey: refundKey,
+		Amount:    int64(refund.Amount),
+		Reason:    refund.Reason,
+	})
+	if gatewayErr != nil && !errors.Is(gatewayErr, payment.ErrRefundRejected) {
+		log.WithError(gatewayErr).Warnf("Gateway refund %s for donation %s has unknown outcome, left pending", refundKey, refund.DonationID)
+		return nil, nil, false, fmt.Errorf("%w: %v", errRefundLeftPending, gatewayErr)
+	}
+	if gatewayErr != nil {
+		log.WithError(gatewayErr).Errorf("Gateway refund %s rejected for donation %s", refundKey, refund.DonationID)
+	}
+
+	var (
+		donation *entities.Donation
+		applied  bool
+	)
+	err := ru.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		refund, err = ru.donationRefundRepository.FindByIdForUpdate(ctx, refundID)
+		if err != nil {
+			return err
+		}
+		donation, err = ru.donationRepository.FindByIdForUpdate(ctx, refund.DonationID)
+		if err != nil {
+			return err
+		}
+		if refund.Status != entities.RefundStatusPending {
+			return nil
+		}
+
+		refund.UpdatedAt = time.Now()
+		if gatewayErr != nil {
+			refund.Status = entities.RefundStatusFailed
+			refund.FailureReason = gatewayErr.Error()
+			return ru.donationRefundRepository.Update(ctx, refund)
+		}
+
+		// Webhook refund dari gateway bisa lebih dulu mengubah donasi, sehingga hanya
+		// sisa dana yang belum tercatat yang dikurangi
+		amount := refund.Amount
+		if remaining := donation.Amount - donation.RefundedAmount; amount > remaining {
+			amount = remaining
+		}
+		if amount > 0 {
+			if err := ru.applyRefund(ctx, donation, amount); err != nil {
+				return err
+			}
+			applied = true
+		}
+
+		refund.Status = entities.RefundStatusCompleted
+		refund.GatewayStatus = result.Status
+		if result.RefundKey != "" {
+			refund.RefundKey = result.RefundKey
+		}
+		return ru.donationRefundRepository.Update(ctx, refund)
+	})
+	if err != nil {
+		if gatewayErr == nil {
+			// Dana sudah dikembalikan gateway, refund tetap pending dan akan dilanjutkan
+			log.WithError(err).Errorf("Refund %s succeeded at gateway but failed to complete, left pending", refundKey)
+		}
+		return nil, nil, false, err
+	}
+	if gatewayErr != nil {
+		return nil, nil, false, gatewayErr
+	}
+	return refund, donation, applied, nil
+}
+
+// applyRefund mengurangi dana donasi dan program sesuai alokasinya. Baris donasi harus
+// sudah dikunci oleh pemanggil.
+func (ru *donationRefundUsecase) applyRefund(ctx context.Context, donation *entities.Donation, amount int) error {
+	nextStatus := entities.DonationStatusPartiallyRefunded
+	if donation.RefundedAmount+amount == donation.Amount {
+		nextStatus = entities.DonationStatusRefunded
+	}
+	if nextStatus != donation.Status && !donation.Status.CanTransitionTo(nextStatus) {
+		return err_util.ErrIllegalStatusTransition
+	}
+
+	// Refund dibagi ke program sesuai alokasi donasi
+	allocations, err := loadAllocations(ctx, ru.allocationRepository, donation)
+	if err != nil {
+		return err
+	}
+	if err := updateProgramAmounts(ctx, ru.programDonation, allocations, donation.RefundedAmount, -amount); err != nil {
+		return err
+	}
+
+	donation.RefundedAmount += amount
+	donation.Status = nextStatus
+	return ru.donationRepository.Update(ctx, donation)
+}
+
+func (ru *donationRefundUsecase) GetRefunds(c echo.Context, donationID uuid.UUID) (*[]dto.RefundResponse, error) {
+	ctx := c.Request().Context()
+
+	donation, err := ru.donationRepository.GetDonationByID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := ru.donationRefundRepository.GetRefundsByDonationID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.RefundResponse{}
+	for _, refund := range refunds {
+		responses = append(responses, toRefundResponse(refund, donation))
+	}
+	return &responses, nil
+}
+
+func toRefundResponse(refund entities.DonationRefund, donation *entities.Donation) dto.RefundResponse {
+	return dto.RefundResponse{
+		ID:             refund.ID.String(),
+		DonationID:     refund.DonationID.String(),
+		Amount:         refund.Amount,
+		Reason:         refund.Reason,
+		Method:         refund.Method,
+		RefundKey:      refund.RefundKey,
+		GatewayStatus:  refund.GatewayStatus,
+		Status:         refund.Status,
+		FailureReason:  refund.FailureReason,
+		AdminID:        refund.AdminID.String(),
+		DonationStatus: donation.Status.String(),
+		RefundedAmount: donation.RefundedAmount,
+		CreatedAt:      refund.CreatedAt.Format(time.RFC3339),
+	}
+}
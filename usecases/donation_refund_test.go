@@ -0,0 +1,186 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"tugas-akhir/drivers/payment"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+)
+
+func TestRefundDonationThroughGateway(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+	donation := env.createPaidDonation(t, testDonationRequest(program.ID, 100000))
+
+	response, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Amount: 30000, Reason: "salah nominal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != entities.RefundStatusCompleted || response.DonationStatus != entities.DonationStatusPartiallyRefunded.String() {
+		t.Errorf("refund %s with donation %s, want completed and partially_refunded", response.Status, response.DonationStatus)
+	}
+	if got := env.currentAmount(t, program.ID); got != 70000 {
+		t.Errorf("program amount %d after refund, want 70000", got)
+	}
+
+	// Sisa dana dikembalikan dengan Amount 0
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Reason: "batal"}); err != nil {
+		t.Fatal(err)
+	}
+	updated := env.donations.get(t, donation.ID)
+	if updated.Status != entities.DonationStatusRefunded || updated.RefundedAmount != 100000 {
+		t.Errorf("donation %s with %d refunded, want refunded with 100000", updated.Status, updated.RefundedAmount)
+	}
+	if got := env.currentAmount(t, program.ID); got != 0 {
+		t.Errorf("program amount %d after full refund, want 0", got)
+	}
+	if got := len(env.gateway.Refunds()); got != 2 {
+		t.Errorf("gateway received %d refunds, want 2", got)
+	}
+	if got := env.feed.count(DonationEventRefunded); got != 2 {
+		t.Errorf("published %d refund events, want 2", got)
+	}
+
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Reason: "lagi"}); !errors.Is(err, err_util.ErrDonationNotRefundable) {
+		t.Errorf("refund of a refunded donation returned %v, want %v", err, err_util.ErrDonationNotRefundable)
+	}
+}
+
+func TestRefundDonationRejectedByGateway(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+	donation := env.createPaidDonation(t, testDonationRequest(program.ID, 100000))
+
+	env.gateway.FailRefunds(fmt.Errorf("%w: refund window closed", payment.ErrRefundRejected))
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Reason: "batal"}); !errors.Is(err, payment.ErrRefundRejected) {
+		t.Fatalf("RefundDonation returned %v, want %v", err, payment.ErrRefundRejected)
+	}
+	refunds, _ := env.refunds.GetRefundsByDonationID(context.Background(), donation.ID)
+	if len(refunds) != 1 || refunds[0].Status != entities.RefundStatusFailed {
+		t.Fatalf("got refunds %+v, want one failed refund", refunds)
+	}
+	if got := env.currentAmount(t, program.ID); got != 100000 {
+		t.Errorf("program amount %d after rejected refund, want 100000", got)
+	}
+
+	// Nominal refund yang ditolak bisa di-refund ulang
+	env.gateway.FailRefunds(nil)
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Reason: "batal"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := env.currentAmount(t, program.ID); got != 0 {
+		t.Errorf("program amount %d after retried refund, want 0", got)
+	}
+}
+
+func TestRefundDonationWithUnknownGatewayOutcome(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+	donation := env.createPaidDonation(t, testDonationRequest(program.ID, 100000))
+
+	env.gateway.FailRefunds(errors.New("midtrans: request timeout"))
+	response, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Reason: "batal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != entities.RefundStatusPending {
+		t.Fatalf("refund %s after a gateway timeout, want pending", response.Status)
+	}
+	if got := env.feed.count(DonationEventRefunded); got != 0 {
+		t.Errorf("published %d refund events for a pending refund, want 0", got)
+	}
+
+	// Nominal refund pending tetap dipesan sehingga tidak bisa di-refund dengan key baru
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Reason: "batal"}); !errors.Is(err, err_util.ErrInvalidRefundAmount) {
+		t.Errorf("second refund returned %v, want %v", err, err_util.ErrInvalidRefundAmount)
+	}
+
+	env.gateway.FailRefunds(nil)
+	for i := 0; i < 2; i++ {
+		if _, err := env.refundUsecase.ResumePendingRefunds(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refund := env.refunds.refunds[uuid.MustParse(response.ID)]
+	if refund.Status != entities.RefundStatusCompleted {
+		t.Errorf("resumed refund %s, want completed", refund.Status)
+	}
+	gatewayRefunds := env.gateway.Refunds()
+	if len(gatewayRefunds) != 1 || gatewayRefunds[0].RefundKey != response.RefundKey {
+		t.Errorf("gateway refunds %+v, want one refund with key %s", gatewayRefunds, response.RefundKey)
+	}
+	if got := env.currentAmount(t, program.ID); got != 0 {
+		t.Errorf("program amount %d after resumed refund, want 0", got)
+	}
+	if got := env.feed.count(DonationEventRefunded); got != 1 {
+		t.Errorf("published %d refund events, want 1", got)
+	}
+}
+
+func TestRefundWebhookBeforeRefundCompletes(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+	donation := env.createPaidDonation(t, testDonationRequest(program.ID, 100000))
+
+	env.gateway.FailRefunds(errors.New("midtrans: connection reset"))
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Reason: "batal"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Webhook refund dari gateway datang lebih dulu dan mencatat refund penuh
+	notification, err := env.gateway.SetStatus(donation.OrderID, "refund")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.usecase.ApplyTransactionStatus(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+
+	env.gateway.FailRefunds(nil)
+	if resumed, err := env.refundUsecase.ResumePendingRefunds(context.Background()); err != nil || resumed != 1 {
+		t.Fatalf("ResumePendingRefunds resumed %d refunds with error %v, want 1", resumed, err)
+	}
+
+	updated := env.donations.get(t, donation.ID)
+	if updated.Status != entities.DonationStatusRefunded || updated.RefundedAmount != 100000 {
+		t.Errorf("donation %s with %d refunded, want refunded with 100000", updated.Status, updated.RefundedAmount)
+	}
+	if got := env.currentAmount(t, program.ID); got != 0 {
+		t.Errorf("program amount %d, want the refund subtracted once", got)
+	}
+	if got := env.feed.count(DonationEventRefunded); got != 1 {
+		t.Errorf("published %d refund events, want 1", got)
+	}
+}
+
+func TestManualRefundSplitsAcrossAllocations(t *testing.T) {
+	education, food := newTestProgram("Pendidikan"), newTestProgram("Makanan")
+	env := newDonationTestEnv(t, education, food)
+
+	request := testDonationRequest(uuid.Nil, 0)
+	request.Allocations = []dto.AllocationRequest{
+		{ProgramID: education.ID, Amount: 60000},
+		{ProgramID: food.ID, Amount: 40000},
+	}
+	donation := env.createPaidDonation(t, request)
+
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Amount: 50000, Reason: "transfer ulang", Manual: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := env.currentAmount(t, education.ID); got != 30000 {
+		t.Errorf("education amount %d, want 30000", got)
+	}
+	if got := env.currentAmount(t, food.ID); got != 20000 {
+		t.Errorf("food amount %d, want 20000", got)
+	}
+	if got := len(env.gateway.Refunds()); got != 0 {
+		t.Errorf("manual refund called the gateway %d times", got)
+	}
+}
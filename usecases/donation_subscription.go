@@ -0,0 +1,451 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"sync"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/mail"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+	"tugas-akhir/utils/mailtemplate"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// SubscriptionNotifier mengirim pesan donasi rutin ke donatur
+type SubscriptionNotifier interface {
+	SendPaymentLink(ctx context.Context, subscription *entities.DonationSubscription, donation *entities.Donation) error
+	SendPaymentFailed(ctx context.Context, subscription *entities.DonationSubscription, donation *entities.Donation) error
+}
+
+type mailSubscriptionNotifier struct {
+	transport       mail.Transport
+	renderer        *mailtemplate.Renderer
+	programDonation repositories.ProgramDonationRepository
+}
+
+// NewMailSubscriptionNotifier mengirim link pembayaran dan kabar penagihan gagal lewat email
+func NewMailSubscriptionNotifier(transport mail.Transport, renderer *mailtemplate.Renderer, programDonation repositories.ProgramDonationRepository) SubscriptionNotifier {
+	return &mailSubscriptionNotifier{
+		transport:       transport,
+		renderer:        renderer,
+		programDonation: programDonation,
+	}
+}
+
+func (n *mailSubscriptionNotifier) SendPaymentLink(ctx context.Context, subscription *entities.DonationSubscription, donation *entities.Donation) error {
+	return sendMail(ctx, n.transport, n.renderer, mailtemplate.SubscriptionPaymentLink, []string{subscription.Email}, n.subscriptionData(ctx, subscription, donation), nil)
+}
+
+func (n *mailSubscriptionNotifier) SendPaymentFailed(ctx context.Context, subscription *entities.DonationSubscription, donation *entities.Donation) error {
+	return sendMail(ctx, n.transport, n.renderer, mailtemplate.SubscriptionPaymentFailed, []string{subscription.Email}, n.subscriptionData(ctx, subscription, donation), nil)
+}
+
+func (n *mailSubscriptionNotifier) subscriptionData(ctx context.Context, subscription *entities.DonationSubscription, donation *entities.Donation) mailtemplate.SubscriptionData {
+	programTitle := ""
+	if program, err := n.programDonation.GetProgramDonationByID(ctx, subscription.ProgramDonationID); err == nil {
+		programTitle = program.Title
+	}
+
+	return mailtemplate.SubscriptionData{
+		Name:         subscription.Name,
+		Amount:       formatRupiah(donation.Amount),
+		ProgramTitle: programTitle,
+		PaymentURL:   donation.SnapURL,
+		Attempt:      subscription.FailedAttempts,
+		Suspended:    subscription.Status == entities.SubscriptionSuspended,
+	}
+}
+
+type DonationSubscriptionUsecase interface {
+	CreateSubscription(c echo.Context, req *dto.SubscriptionRequest) (*dto.SubscriptionResponse, error)
+	PauseSubscription(c echo.Context, subscriptionID uuid.UUID, manageToken string) (*dto.SubscriptionResponse, error)
+	ResumeSubscription(c echo.Context, subscriptionID uuid.UUID, manageToken string) (*dto.SubscriptionResponse, error)
+	CancelSubscription(c echo.Context, subscriptionID uuid.UUID, manageToken string) (*dto.SubscriptionResponse, error)
+	GetSubscriptions(c echo.Context, status string) (*[]dto.SubscriptionResponse, error)
+	GetDonorSubscriptions(c echo.Context, userID uuid.UUID) (*[]dto.SubscriptionResponse, error)
+	ProcessCycles(ctx context.Context)
+}
+
+type donationSubscriptionUsecase struct {
+	donationUsecase                   DonationUsecase
+	donationRepository                repositories.DonationRepository
+	subscriptionRepository            repositories.DonationSubscriptionRepository
+	programDonation                   repositories.ProgramDonationRepository
+	transactionNotificationRepository repositories.TransactionNotificationRepository
+	transactionManager                repositories.TransactionManager
+	notifier                          SubscriptionNotifier
+	config                            config.DonationConfig
+	running                           sync.Mutex
+}
+
+func NewDonationSubscriptionUsecase(donationUsecase DonationUsecase, donationRepository repositories.DonationRepository, subscriptionRepository repositories.DonationSubscriptionRepository, programDonation repositories.ProgramDonationRepository, transactionNotificationRepository repositories.TransactionNotificationRepository, transactionManager repositories.TransactionManager, notifier SubscriptionNotifier, config config.DonationConfig) DonationSubscriptionUsecase {
+	return &donationSubscriptionUsecase{
+		donationUsecase:                   donationUsecase,
+		donationRepository:                donationRepository,
+		subscriptionRepository:            subscriptionRepository,
+		programDonation:                   programDonation,
+		transactionNotificationRepository: transactionNotificationRepository,
+		transactionManager:                transactionManager,
+		notifier:                          notifier,
+		config:                            config,
+	}
+}
+
+// CreateSubscription mendaftarkan donasi rutin. Jika mulai hari ini, siklus pertama
+// langsung dibuat sehingga respons sudah berisi link pembayaran.
+func (su *donationSubscriptionUsecase) CreateSubscription(c echo.Context, req *dto.SubscriptionRequest) (*dto.SubscriptionResponse, error) {
+	ctx := c.Request().Context()
+
+	if _, err := su.programDonation.GetProgramDonationByID(ctx, req.ProgramID); err != nil {
+		return nil, err
+	}
+
+	nextChargeAt := time.Now()
+	if req.StartDate != "" {
+		startDate, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		if startDate.After(nextChargeAt) {
+			nextChargeAt = startDate
+		}
+	}
+
+	manageToken, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := entities.DonationSubscription{
+		ID:                uuid.New(),
+		Name:              req.Name,
+		Address:           req.Address,
+		NoWA:              req.NoWA,
+		Email:             req.Email,
+		Message:           req.Message,
+		ProgramDonationID: req.ProgramID,
+		Amount:            req.Amount,
+		Interval:          req.Interval,
+		Status:            entities.SubscriptionActive,
+		UserID:            req.UserID,
+		Anonymous:         req.Anonymous,
+		DisplayName:       publicDisplayName(req.DisplayName),
+		NextChargeAt:      nextChargeAt,
+		AnchorDay:         nextChargeAt.Day(),
+		ManageTokenHash:   hashSecretToken(manageToken),
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+	if err := su.subscriptionRepository.CreateSubscription(ctx, &subscription); err != nil {
+		return nil, err
+	}
+
+	var donation *entities.Donation
+	if !subscription.NextChargeAt.After(time.Now()) {
+		donation, err = su.startCycle(ctx, subscription.ID)
+		if err != nil {
+			logrus.New().WithError(err).Errorf("Failed to start first cycle of recurring donation %s", subscription.ID)
+		}
+	}
+
+	response := toSubscriptionResponse(&subscription, donation)
+	response.ManageToken = manageToken
+	return &response, nil
+}
+
+func (su *donationSubscriptionUsecase) PauseSubscription(c echo.Context, subscriptionID uuid.UUID, manageToken string) (*dto.SubscriptionResponse, error) {
+	return su.changeSubscription(c.Request().Context(), subscriptionID, manageToken, func(subscription *entities.DonationSubscription) error {
+		if subscription.Status != entities.SubscriptionActive {
+			return err_util.ErrSubscriptionState
+		}
+		subscription.Status = entities.SubscriptionPaused
+		return nil
+	})
+}
+
+// ResumeSubscription mengaktifkan kembali donasi rutin. Jadwal yang terlewat selama
+// jeda tidak ditagih, penagihan berikutnya mengikuti jadwal setelah hari ini.
+func (su *donationSubscriptionUsecase) ResumeSubscription(c echo.Context, subscriptionID uuid.UUID, manageToken string) (*dto.SubscriptionResponse, error) {
+	return su.changeSubscription(c.Request().Context(), subscriptionID, manageToken, func(subscription *entities.DonationSubscription) error {
+		if subscription.Status != entities.SubscriptionPaused && subscription.Status != entities.SubscriptionSuspended {
+			return err_util.ErrSubscriptionState
+		}
+		subscription.Status = entities.SubscriptionActive
+		subscription.FailedAttempts = 0
+		subscription.RetryAt = nil
+		for !subscription.NextChargeAt.After(time.Now()) {
+			subscription.NextChargeAt = subscription.NextCycle(subscription.NextChargeAt)
+		}
+		return nil
+	})
+}
+
+func (su *donationSubscriptionUsecase) CancelSubscription(c echo.Context, subscriptionID uuid.UUID, manageToken string) (*dto.SubscriptionResponse, error) {
+	return su.changeSubscription(c.Request().Context(), subscriptionID, manageToken, func(subscription *entities.DonationSubscription) error {
+		if subscription.Status == entities.SubscriptionCancelled {
+			return err_util.ErrSubscriptionState
+		}
+		cancelledAt := time.Now()
+		subscription.Status = entities.SubscriptionCancelled
+		subscription.CancelledAt = &cancelledAt
+		subscription.RetryAt = nil
+		return nil
+	})
+}
+
+func (su *donationSubscriptionUsecase) changeSubscription(ctx context.Context, subscriptionID uuid.UUID, manageToken string, change func(subscription *entities.DonationSubscription) error) (*dto.SubscriptionResponse, error) {
+	var subscription *entities.DonationSubscription
+	err := su.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = su.subscriptionRepository.FindByIdForUpdate(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(hashSecretToken(manageToken)), []byte(subscription.ManageTokenHash)) != 1 {
+			return err_util.ErrInvalidManageToken
+		}
+
+		if err := change(subscription); err != nil {
+			return err
+		}
+		subscription.UpdatedAt = time.Now()
+		return su.subscriptionRepository.Update(ctx, subscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toSubscriptionResponse(subscription, nil)
+	return &response, nil
+}
+
+func (su *donationSubscriptionUsecase) GetSubscriptions(c echo.Context, status string) (*[]dto.SubscriptionResponse, error) {
+	subscriptions, err := su.subscriptionRepository.GetSubscriptions(c.Request().Context(), status)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.SubscriptionResponse{}
+	for i := range subscriptions {
+		responses = append(responses, toSubscriptionResponse(&subscriptions[i], nil))
+	}
+	return &responses, nil
+}
+
+func (su *donationSubscriptionUsecase) GetDonorSubscriptions(c echo.Context, userID uuid.UUID) (*[]dto.SubscriptionResponse, error) {
+	subscriptions, err := su.subscriptionRepository.GetSubscriptionsByUserID(c.Request().Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.SubscriptionResponse{}
+	for i := range subscriptions {
+		responses = append(responses, toSubscriptionResponse(&subscriptions[i], nil))
+	}
+	return &responses, nil
+}
+
+// ProcessCycles dijalankan scheduler: menutup siklus yang sudah selesai lalu membuat
+// siklus baru untuk donasi rutin yang jatuh tempo
+func (su *donationSubscriptionUsecase) ProcessCycles(ctx context.Context) {
+	if !su.running.TryLock() {
+		return
+	}
+	defer su.running.Unlock()
+
+	log := logrus.New()
+
+	open, err := su.subscriptionRepository.GetOpenCycles(ctx, su.config.ReconcileBatch)
+	if err != nil {
+		log.WithError(err).Error("Failed to get open recurring donation cycles")
+		return
+	}
+	for _, subscription := range open {
+		if err := su.closeCycle(ctx, subscription.ID); err != nil {
+			log.WithError(err).Warnf("Failed to close cycle of recurring donation %s", subscription.ID)
+		}
+	}
+
+	due, err := su.subscriptionRepository.GetDueSubscriptions(ctx, time.Now(), su.config.ReconcileBatch)
+	if err != nil {
+		log.WithError(err).Error("Failed to get due recurring donations")
+		return
+	}
+	for _, subscription := range due {
+		if _, err := su.startCycle(ctx, subscription.ID); err != nil {
+			log.WithError(err).Warnf("Failed to start cycle of recurring donation %s", subscription.ID)
+		}
+	}
+}
+
+// startCycle membuat donasi untuk siklus berikutnya. Siklus dan donasinya disimpan
+// lebih dulu, lalu kartu tersimpan ditagih atau link pembayaran dikirim setelah
+// transaksi selesai.
+func (su *donationSubscriptionUsecase) startCycle(ctx context.Context, subscriptionID uuid.UUID) (*entities.Donation, error) {
+	var (
+		subscription *entities.DonationSubscription
+		donation     *entities.Donation
+	)
+
+	err := su.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = su.subscriptionRepository.FindByIdForUpdate(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if subscription.Status != entities.SubscriptionActive || subscription.CurrentDonationID != nil {
+			return nil
+		}
+
+		donation, err = su.donationUsecase.CreateSubscriptionDonation(ctx, subscription)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		subscription.CurrentDonationID = &donation.ID
+		subscription.LastChargedAt = &now
+		subscription.UpdatedAt = now
+		return su.subscriptionRepository.Update(ctx, subscription)
+	})
+	if err != nil || donation == nil {
+		return nil, err
+	}
+
+	donation, err = su.donationUsecase.ChargeSubscriptionDonation(ctx, subscription, donation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if donation.Status == entities.DonationStatusPending && donation.SnapURL != "" {
+		if err := su.notifier.SendPaymentLink(ctx, subscription, donation); err != nil {
+			logrus.New().WithError(err).Warnf("Failed to send payment link of recurring donation %s", subscription.ID)
+		}
+	}
+	return donation, nil
+}
+
+// closeCycle memeriksa donasi siklus berjalan. Siklus yang lunas memajukan jadwal,
+// sedangkan siklus yang gagal atau kadaluarsa dijadwalkan tagih ulang sampai batas
+// SubscriptionMaxRetries, setelah itu donasi rutin dihentikan sementara (suspended).
+func (su *donationSubscriptionUsecase) closeCycle(ctx context.Context, subscriptionID uuid.UUID) error {
+	var (
+		subscription *entities.DonationSubscription
+		donation     *entities.Donation
+		failed       bool
+	)
+
+	err := su.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = su.subscriptionRepository.FindByIdForUpdate(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if subscription.CurrentDonationID == nil {
+			return nil
+		}
+
+		donation, err = su.donationRepository.GetDonationByID(ctx, *subscription.CurrentDonationID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		switch donation.Status {
+		case entities.DonationStatusPaid, entities.DonationStatusPartiallyRefunded, entities.DonationStatusRefunded:
+			subscription.FailedAttempts = 0
+			subscription.RetryAt = nil
+			for !subscription.NextChargeAt.After(now) {
+				subscription.NextChargeAt = subscription.NextCycle(subscription.NextChargeAt)
+			}
+
+			token, err := su.transactionNotificationRepository.GetSavedTokenByDonationID(ctx, donation.ID)
+			if err != nil {
+				return err
+			}
+			if token != "" {
+				subscription.SavedTokenID = token
+			}
+
+		case entities.DonationStatusFailed, entities.DonationStatusExpired:
+			failed = true
+			subscription.FailedAttempts++
+			// Kartu yang gagal tidak dipakai lagi, donatur membayar lewat link berikutnya
+			subscription.SavedTokenID = ""
+			if subscription.FailedAttempts >= su.config.SubscriptionMaxRetries {
+				subscription.Status = entities.SubscriptionSuspended
+				subscription.RetryAt = nil
+				for !subscription.NextChargeAt.After(now) {
+					subscription.NextChargeAt = subscription.NextCycle(subscription.NextChargeAt)
+				}
+			} else {
+				retryAt := now.Add(su.config.SubscriptionRetryDelay)
+				subscription.RetryAt = &retryAt
+			}
+
+		default:
+			// Pembayaran masih berjalan
+			return nil
+		}
+
+		subscription.CurrentDonationID = nil
+		subscription.UpdatedAt = now
+		return su.subscriptionRepository.Update(ctx, subscription)
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed {
+		if err := su.notifier.SendPaymentFailed(ctx, subscription, donation); err != nil {
+			logrus.New().WithError(err).Warnf("Failed to send payment failure of recurring donation %s", subscription.ID)
+		}
+	}
+	return nil
+}
+
+func generateSecretToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func hashSecretToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func toSubscriptionResponse(subscription *entities.DonationSubscription, donation *entities.Donation) dto.SubscriptionResponse {
+	response := dto.SubscriptionResponse{
+		ID:             subscription.ID.String(),
+		Name:           subscription.Name,
+		Email:          subscription.Email,
+		ProgramID:      subscription.ProgramDonationID.String(),
+		Amount:         subscription.Amount,
+		Interval:       subscription.Interval,
+		Status:         subscription.Status,
+		NextChargeAt:   subscription.NextChargeAt.Format(time.RFC3339),
+		FailedAttempts: subscription.FailedAttempts,
+		AutoCharge:     subscription.SavedTokenID != "",
+	}
+	if subscription.RetryAt != nil {
+		response.RetryAt = subscription.RetryAt.Format(time.RFC3339)
+	}
+	if donation != nil {
+		response.SnapURL = donation.SnapURL
+	}
+	return response
+}
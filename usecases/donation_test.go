@@ -0,0 +1,566 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/payment"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// donationTestEnv merangkai DonationUsecase, DonationRefundUsecase, dan
+// OfflineDonationUsecase dengan FakeGateway dan repository in-memory, sehingga alur donasi
+// bisa diuji tanpa Postgres maupun Midtrans. Transaksi database tidak di-rollback, jadi
+// pengujian hanya memeriksa error yang terjadi sebelum ada data yang ditulis.
+type donationTestEnv struct {
+	gateway        *payment.FakeGateway
+	donations      *memDonationRepo
+	programs       *memProgramRepo
+	notifications  *memNotificationRepo
+	refunds        *memRefundRepo
+	transactions   *trackingTransaction
+	feed           *recordingFeed
+	mailer         *recordingMailer
+	usecase        DonationUsecase
+	refundUsecase  DonationRefundUsecase
+	offlineUsecase OfflineDonationUsecase
+}
+
+func newDonationTestEnv(t *testing.T, programs ...entities.ProgramDonation) *donationTestEnv {
+	t.Helper()
+
+	env := &donationTestEnv{
+		gateway:       payment.NewFakeGateway(),
+		donations:     &memDonationRepo{donations: map[uuid.UUID]entities.Donation{}},
+		programs:      &memProgramRepo{programs: map[uuid.UUID]entities.ProgramDonation{}},
+		notifications: &memNotificationRepo{},
+		refunds:       &memRefundRepo{refunds: map[uuid.UUID]entities.DonationRefund{}},
+		transactions:  &trackingTransaction{},
+	}
+	env.feed = &recordingFeed{transactions: env.transactions}
+	env.mailer = &recordingMailer{transactions: env.transactions}
+	for _, program := range programs {
+		env.programs.programs[program.ID] = program
+	}
+
+	allocations := &memAllocationRepo{allocations: map[uuid.UUID][]entities.DonationAllocation{}}
+	env.usecase = NewDonationUsecase(env.donations, env.gateway, env.programs, env.notifications, env.transactions, allocations, stubReceipts{}, env.feed, env.mailer, nopWhatsApp{})
+	env.refundUsecase = NewDonationRefundUsecase(env.donations, env.programs, env.refunds, allocations, env.gateway, env.transactions, env.feed, config.DonationConfig{ReconcileBatch: 10})
+	env.offlineUsecase = NewOfflineDonationUsecase(env.usecase, env.donations, env.programs, nil, env.transactions)
+	return env
+}
+
+func newTestProgram(title string) entities.ProgramDonation {
+	return entities.ProgramDonation{ID: uuid.New(), Title: title, GoalAmount: 10000000}
+}
+
+func newTestContext() echo.Context {
+	return echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+}
+
+// createPaidDonation membuat donasi lewat CreateDonation lalu melunasinya di gateway
+func (env *donationTestEnv) createPaidDonation(t *testing.T, request dto.DonationRequest) entities.Donation {
+	t.Helper()
+
+	response, err := env.usecase.CreateDonation(newTestContext(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	donation := env.donations.get(t, uuid.MustParse(response.ID))
+
+	settlement, err := env.gateway.SetStatus(donation.OrderID, "settlement")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.usecase.ApplyTransactionStatus(context.Background(), settlement); err != nil {
+		t.Fatal(err)
+	}
+	return env.donations.get(t, donation.ID)
+}
+
+func (env *donationTestEnv) currentAmount(t *testing.T, programID uuid.UUID) int {
+	t.Helper()
+	return env.programs.programs[programID].CurrentAmount
+}
+
+func testDonationRequest(programID uuid.UUID, amount int) dto.DonationRequest {
+	return dto.DonationRequest{
+		Name:      "Budi",
+		Address:   "Yogyakarta",
+		NoWA:      "+6281234567890",
+		Email:     "budi@example.com",
+		Message:   "Semoga bermanfaat",
+		ProgramID: programID,
+		Amount:    amount,
+	}
+}
+
+func TestCreateDonation(t *testing.T) {
+	education, food := newTestProgram("Pendidikan"), newTestProgram("Makanan")
+	env := newDonationTestEnv(t, education, food)
+
+	request := testDonationRequest(uuid.Nil, 0)
+	request.Allocations = []dto.AllocationRequest{
+		{ProgramID: education.ID, Amount: 60000},
+		{ProgramID: food.ID, Amount: 40000},
+		{ProgramID: education.ID, Amount: 10000},
+	}
+	response, err := env.usecase.CreateDonation(newTestContext(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	donation := env.donations.get(t, uuid.MustParse(response.ID))
+	if donation.Amount != 110000 || donation.Status != entities.DonationStatusPending {
+		t.Errorf("donation saved with amount %d and status %s, want 110000 pending", donation.Amount, donation.Status)
+	}
+	if donation.ProgramDonationID != education.ID {
+		t.Errorf("primary program is %s, want the first allocation %s", donation.ProgramDonationID, education.ID)
+	}
+	if response.SnapURL == "" || response.SnapURL != donation.SnapURL {
+		t.Errorf("response snap url %q does not match saved %q", response.SnapURL, donation.SnapURL)
+	}
+	if len(response.Allocations) != 2 {
+		t.Errorf("got %d allocations, want allocations of the same program merged into 2", len(response.Allocations))
+	}
+	if response.NoWA == donation.NoWA.String() {
+		t.Errorf("response exposes the full WhatsApp number %s", response.NoWA)
+	}
+
+	status, err := env.gateway.QueryStatus(context.Background(), donation.OrderID)
+	if err != nil {
+		t.Fatalf("gateway has no transaction for order %s: %v", donation.OrderID, err)
+	}
+	if amount, _ := status.Amount(); amount != donation.Amount {
+		t.Errorf("gateway transaction amount %d, want %d", amount, donation.Amount)
+	}
+	if env.currentAmount(t, education.ID) != 0 || env.currentAmount(t, food.ID) != 0 {
+		t.Error("pending donation credited a program")
+	}
+}
+
+func TestCreateDonationUnknownProgram(t *testing.T) {
+	env := newDonationTestEnv(t, newTestProgram("Pendidikan"))
+
+	if _, err := env.usecase.CreateDonation(newTestContext(), testDonationRequest(uuid.New(), 50000)); err == nil {
+		t.Fatal("donation to an unknown program was created")
+	}
+	if len(env.donations.donations) != 0 {
+		t.Errorf("%d donations saved, want none", len(env.donations.donations))
+	}
+}
+
+func TestApplyTransactionStatus(t *testing.T) {
+	tests := []struct {
+		name              string
+		initialStatus     entities.DonationStatus
+		transactionStatus string
+		fraudStatus       string
+		grossAmount       string
+		wantErr           error
+		wantStatus        entities.DonationStatus
+		wantCredited      int
+	}{
+		{"settlement credits the program", entities.DonationStatusPending, "settlement", "", "", nil, entities.DonationStatusPaid, 75000},
+		{"accepted capture credits the program", entities.DonationStatusPending, "capture", "accept", "", nil, entities.DonationStatusPaid, 75000},
+		{"challenged capture waits for review", entities.DonationStatusPending, "capture", "challenge", "", nil, entities.DonationStatusChallenged, 0},
+		{"fraud denied capture fails", entities.DonationStatusPending, "capture", "deny", "", nil, entities.DonationStatusFailed, 0},
+		{"expire", entities.DonationStatusPending, "expire", "", "", nil, entities.DonationStatusExpired, 0},
+		{"late settlement of expired donation", entities.DonationStatusExpired, "settlement", "", "", nil, entities.DonationStatusPaid, 75000},
+		{"settlement with a different gross amount", entities.DonationStatusPending, "settlement", "", "1000.00", err_util.ErrGrossAmountMismatch, entities.DonationStatusPending, 0},
+		{"settlement with an invalid gross amount", entities.DonationStatusPending, "settlement", "", "abc", err_util.ErrGrossAmountMismatch, entities.DonationStatusPending, 0},
+		{"illegal transition", entities.DonationStatusRefunded, "expire", "", "", err_util.ErrIllegalStatusTransition, entities.DonationStatusRefunded, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := newTestProgram("Pendidikan")
+			env := newDonationTestEnv(t, program)
+
+			response, err := env.usecase.CreateDonation(newTestContext(), testDonationRequest(program.ID, 75000))
+			if err != nil {
+				t.Fatal(err)
+			}
+			donation := env.donations.get(t, uuid.MustParse(response.ID))
+			donation.Status = tt.initialStatus
+			env.donations.donations[donation.ID] = donation
+
+			notification, err := env.gateway.SetStatus(donation.OrderID, tt.transactionStatus)
+			if err != nil {
+				t.Fatal(err)
+			}
+			notification.FraudStatus = tt.fraudStatus
+			if tt.grossAmount != "" {
+				notification.GrossAmount = tt.grossAmount
+			}
+
+			_, err = env.usecase.ApplyTransactionStatus(context.Background(), notification)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyTransactionStatus returned %v, want %v", err, tt.wantErr)
+			}
+			if got := env.donations.get(t, donation.ID).Status; got != tt.wantStatus {
+				t.Errorf("donation status %s, want %s", got, tt.wantStatus)
+			}
+			if got := env.currentAmount(t, program.ID); got != tt.wantCredited {
+				t.Errorf("program credited %d, want %d", got, tt.wantCredited)
+			}
+		})
+	}
+}
+
+func TestApplyTransactionStatusDuplicateNotification(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+	donation := env.createPaidDonation(t, testDonationRequest(program.ID, 50000))
+
+	settlement, err := env.gateway.QueryStatus(context.Background(), donation.OrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := env.usecase.ApplyTransactionStatus(context.Background(), *settlement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Changed() {
+		t.Errorf("duplicate notification changed status from %s to %s", change.From, change.To)
+	}
+	if got := env.currentAmount(t, program.ID); got != 50000 {
+		t.Errorf("program credited %d after a duplicate notification, want 50000", got)
+	}
+}
+
+// resumeWithExpiredLink membuat donasi lalu melanjutkannya setelah link pertama
+// kadaluarsa, sehingga donasi punya order lama dan order baru yang sama-sama bisa dibayar
+func (env *donationTestEnv) resumeWithExpiredLink(t *testing.T, request dto.DonationRequest) (entities.Donation, string) {
+	t.Helper()
+
+	response, err := env.usecase.CreateDonation(newTestContext(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	donation := env.donations.get(t, uuid.MustParse(response.ID))
+	firstOrderID := donation.CurrentOrderID()
+
+	expiredAt := time.Now().Add(-time.Minute)
+	donation.SnapExpiresAt = &expiredAt
+	env.donations.donations[donation.ID] = donation
+	if _, err := env.usecase.ResumeDonation(newTestContext(), donation.ID); err != nil {
+		t.Fatal(err)
+	}
+	return env.donations.get(t, donation.ID), firstOrderID
+}
+
+func (env *donationTestEnv) settle(t *testing.T, orderID string, transactionStatus string) {
+	t.Helper()
+
+	notification, err := env.gateway.SetStatus(orderID, transactionStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.usecase.ApplyTransactionStatus(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPaymentThroughSupersededOrderIsRefundedThroughIt(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+	donation, firstOrderID := env.resumeWithExpiredLink(t, testDonationRequest(program.ID, 100000))
+	if donation.CurrentOrderID() == firstOrderID {
+		t.Fatalf("resume kept order %s", firstOrderID)
+	}
+
+	// Donatur membayar lewat link lama
+	env.settle(t, firstOrderID, "settlement")
+	paid := env.donations.get(t, donation.ID)
+	if paid.Status != entities.DonationStatusPaid || paid.CurrentOrderID() != firstOrderID {
+		t.Fatalf("donation %s with order %s, want paid with order %s", paid.Status, paid.CurrentOrderID(), firstOrderID)
+	}
+
+	if _, err := env.refundUsecase.RefundDonation(newTestContext(), donation.ID, uuid.New(), &dto.RefundRequest{Amount: 30000, Reason: "salah nominal"}); err != nil {
+		t.Fatal(err)
+	}
+	refunds := env.gateway.Refunds()
+	if len(refunds) != 1 || refunds[0].OrderID != firstOrderID {
+		t.Errorf("gateway refunds %+v, want one refund of order %s", refunds, firstOrderID)
+	}
+
+	// Refund penuh dari dashboard gateway untuk order yang dibayar tidak dianggap usang
+	env.settle(t, firstOrderID, "refund")
+	refunded := env.donations.get(t, donation.ID)
+	if refunded.Status != entities.DonationStatusRefunded {
+		t.Errorf("donation %s after refund webhook of the paid order, want refunded", refunded.Status)
+	}
+	if got := env.currentAmount(t, program.ID); got != 0 {
+		t.Errorf("program amount %d after full refund, want 0", got)
+	}
+}
+
+func TestDuplicatePaymentAlertsAdmin(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+	donation, firstOrderID := env.resumeWithExpiredLink(t, testDonationRequest(program.ID, 100000))
+
+	env.settle(t, firstOrderID, "settlement")
+	env.settle(t, donation.CurrentOrderID(), "settlement")
+
+	updated := env.donations.get(t, donation.ID)
+	if updated.Status != entities.DonationStatusPaid || updated.CurrentOrderID() != firstOrderID {
+		t.Errorf("donation %s with order %s, want paid with order %s", updated.Status, updated.CurrentOrderID(), firstOrderID)
+	}
+	if got := env.currentAmount(t, program.ID); got != 100000 {
+		t.Errorf("program amount %d, want the donation counted once", got)
+	}
+	if len(env.mailer.duplicates) != 1 || env.mailer.duplicates[0] != donation.CurrentOrderID() {
+		t.Errorf("duplicate payment alerts %v, want one for order %s", env.mailer.duplicates, donation.CurrentOrderID())
+	}
+}
+
+type memDonationRepo struct {
+	repositories.DonationRepository
+	donations map[uuid.UUID]entities.Donation
+}
+
+func (r *memDonationRepo) get(t *testing.T, id uuid.UUID) entities.Donation {
+	t.Helper()
+	donation, ok := r.donations[id]
+	if !ok {
+		t.Fatalf("donation %s not saved", id)
+	}
+	return donation
+}
+
+func (r *memDonationRepo) CreateDonation(ctx context.Context, donation *entities.Donation) error {
+	r.donations[donation.ID] = *donation
+	return nil
+}
+
+func (r *memDonationRepo) Update(ctx context.Context, donation *entities.Donation) error {
+	r.donations[donation.ID] = *donation
+	return nil
+}
+
+func (r *memDonationRepo) GetDonationByID(ctx context.Context, donationID uuid.UUID) (*entities.Donation, error) {
+	donation, ok := r.donations[donationID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &donation, nil
+}
+
+func (r *memDonationRepo) FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.Donation, error) {
+	return r.GetDonationByID(ctx, id)
+}
+
+type memProgramRepo struct {
+	repositories.ProgramDonationRepository
+	programs map[uuid.UUID]entities.ProgramDonation
+}
+
+func (r *memProgramRepo) GetProgramDonationByID(ctx context.Context, programDonationID uuid.UUID) (*entities.ProgramDonation, error) {
+	program, ok := r.programs[programDonationID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &program, nil
+}
+
+func (r *memProgramRepo) UpdateCurrentAmount(ctx context.Context, programDonationID uuid.UUID, amount int) error {
+	program, ok := r.programs[programDonationID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	program.CurrentAmount += amount
+	r.programs[programDonationID] = program
+	return nil
+}
+
+type memAllocationRepo struct {
+	allocations map[uuid.UUID][]entities.DonationAllocation
+}
+
+func (r *memAllocationRepo) CreateAllocations(ctx context.Context, allocations []entities.DonationAllocation) error {
+	for _, allocation := range allocations {
+		r.allocations[allocation.DonationID] = append(r.allocations[allocation.DonationID], allocation)
+	}
+	return nil
+}
+
+func (r *memAllocationRepo) GetAllocationsByDonationID(ctx context.Context, donationID uuid.UUID) ([]entities.DonationAllocation, error) {
+	return r.allocations[donationID], nil
+}
+
+type memNotificationRepo struct {
+	repositories.TransactionNotificationRepository
+	notifications []entities.TransactionNotification
+}
+
+func (r *memNotificationRepo) CreateNotification(ctx context.Context, notification *entities.TransactionNotification) error {
+	r.notifications = append(r.notifications, *notification)
+	return nil
+}
+
+func (r *memNotificationRepo) ExistsByTransactionStatus(ctx context.Context, transactionID string, transactionStatus string) (bool, error) {
+	for _, notification := range r.notifications {
+		if notification.TransactionID == transactionID && notification.TransactionStatus == transactionStatus {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type memRefundRepo struct {
+	refunds map[uuid.UUID]entities.DonationRefund
+}
+
+func (r *memRefundRepo) CreateRefund(ctx context.Context, refund *entities.DonationRefund) error {
+	r.refunds[refund.ID] = *refund
+	return nil
+}
+
+func (r *memRefundRepo) FindByIdForUpdate(ctx context.Context, id uuid.UUID) (*entities.DonationRefund, error) {
+	refund, ok := r.refunds[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &refund, nil
+}
+
+func (r *memRefundRepo) Update(ctx context.Context, refund *entities.DonationRefund) error {
+	r.refunds[refund.ID] = *refund
+	return nil
+}
+
+func (r *memRefundRepo) GetRefundsByDonationID(ctx context.Context, donationID uuid.UUID) ([]entities.DonationRefund, error) {
+	var refunds []entities.DonationRefund
+	for _, refund := range r.refunds {
+		if refund.DonationID == donationID {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
+
+func (r *memRefundRepo) GetPendingAmount(ctx context.Context, donationID uuid.UUID) (int, error) {
+	pending := 0
+	for _, refund := range r.refunds {
+		if refund.DonationID == donationID && refund.Status == entities.RefundStatusPending {
+			pending += refund.Amount
+		}
+	}
+	return pending, nil
+}
+
+func (r *memRefundRepo) GetPendingRefundsBefore(ctx context.Context, createdBefore time.Time, limit int) ([]entities.DonationRefund, error) {
+	var refunds []entities.DonationRefund
+	for _, refund := range r.refunds {
+		if refund.Status == entities.RefundStatusPending && !refund.CreatedAt.After(createdBefore) && len(refunds) < limit {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
+
+// trackingTransaction menjalankan fn langsung dan mencatat apakah transaksi sedang terbuka,
+// sehingga pengujian bisa memeriksa notifikasi baru dikirim setelah commit
+type trackingTransaction struct {
+	depth int
+}
+
+func (tt *trackingTransaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	tt.depth++
+	defer func() { tt.depth-- }()
+	return fn(ctx)
+}
+
+func (tt *trackingTransaction) open() bool {
+	return tt.depth > 0
+}
+
+type stubReceipts struct {
+	DonationReceiptUsecase
+}
+
+func (stubReceipts) IssueReceipt(ctx context.Context, donation *entities.Donation, allocations []entities.DonationAllocation) (*entities.DonationReceipt, error) {
+	return &entities.DonationReceipt{ID: uuid.New(), DonationID: donation.ID}, nil
+}
+
+// recordingFeed mencatat event yang dikirim ke feed donasi
+type recordingFeed struct {
+	DonationFeedUsecase
+	transactions      *trackingTransaction
+	events            []string
+	duringTransaction int
+}
+
+func (f *recordingFeed) Publish(ctx context.Context, eventType string, donation *entities.Donation) {
+	f.events = append(f.events, eventType)
+}
+
+func (f *recordingFeed) PublishStatusChange(ctx context.Context, change *DonationStatusChange) {
+	if change == nil || !change.Changed() {
+		return
+	}
+	if f.transactions.open() {
+		f.duringTransaction++
+	}
+	switch change.To {
+	case entities.DonationStatusRefunded, entities.DonationStatusPartiallyRefunded:
+		f.events = append(f.events, DonationEventRefunded)
+	case entities.DonationStatusPaid:
+		f.events = append(f.events, DonationEventPaid)
+	}
+}
+
+func (f *recordingFeed) count(eventType string) int {
+	count := 0
+	for _, event := range f.events {
+		if event == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+// recordingMailer mencatat perubahan status yang dikabarkan lewat email
+type recordingMailer struct {
+	transactions      *trackingTransaction
+	changes           []DonationStatusChange
+	duplicates        []string
+	duringTransaction int
+}
+
+func (m *recordingMailer) SendDonationCreated(ctx context.Context, donation *entities.Donation) {}
+
+func (m *recordingMailer) NotifyStatusChange(ctx context.Context, change *DonationStatusChange) {
+	if change == nil {
+		return
+	}
+	if change.DuplicateOrderID != "" {
+		m.duplicates = append(m.duplicates, change.DuplicateOrderID)
+	}
+	if !change.Changed() {
+		return
+	}
+	if m.transactions.open() {
+		m.duringTransaction++
+	}
+	m.changes = append(m.changes, *change)
+}
+
+type nopWhatsApp struct {
+	WhatsAppUsecase
+}
+
+func (nopWhatsApp) SendPaymentLink(ctx context.Context, donation *entities.Donation)     {}
+func (nopWhatsApp) NotifyStatusChange(ctx context.Context, change *DonationStatusChange) {}
@@ -0,0 +1,444 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"tugas-akhir/drivers/mail"
+	dto "tugas-akhir/dto/donor"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+	"tugas-akhir/utils/mailtemplate"
+	"tugas-akhir/utils/password"
+	"tugas-akhir/utils/phone"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	phoneVerificationTTL = 10 * time.Minute
+
+	// Kode WhatsApp hanya 6 digit, jadi jumlah percobaan dibatasi per kode dan per akun.
+	// Batas per akun berlaku lintas kode agar meminta kode baru tidak mengembalikan jatah
+	// percobaan.
+	phoneVerificationMaxAttempts = 5
+	phoneVerificationWindow      = time.Hour
+	phoneVerificationMaxRequests = 5
+	phoneVerificationMaxFailures = 10
+)
+
+// DonorNotifier mengirim token verifikasi akun donatur
+type DonorNotifier interface {
+	SendEmailVerification(ctx context.Context, user *entities.User, token string) error
+	SendPhoneVerification(ctx context.Context, user *entities.User, code string) error
+}
+
+type donorNotifier struct {
+	transport mail.Transport
+	renderer  *mailtemplate.Renderer
+	whatsApp  WhatsAppUsecase
+}
+
+// NewDonorNotifier mengirim token verifikasi email lewat email dan kode verifikasi
+// nomor lewat WhatsApp
+func NewDonorNotifier(transport mail.Transport, renderer *mailtemplate.Renderer, whatsApp WhatsAppUsecase) DonorNotifier {
+	return &donorNotifier{
+		transport: transport,
+		renderer:  renderer,
+		whatsApp:  whatsApp,
+	}
+}
+
+func (n *donorNotifier) SendEmailVerification(ctx context.Context, user *entities.User, token string) error {
+	data := mailtemplate.VerificationData{
+		Name:     user.Name,
+		Token:    token,
+		ValidFor: fmt.Sprintf("%.0f jam", emailVerificationTTL.Hours()),
+	}
+	return sendMail(ctx, n.transport, n.renderer, mailtemplate.EmailVerification, []string{user.Email}, data, nil)
+}
+
+func (n *donorNotifier) SendPhoneVerification(ctx context.Context, user *entities.User, code string) error {
+	return n.whatsApp.SendVerificationCode(ctx, user.NoWA, code)
+}
+
+type DonorUsecase interface {
+	Register(c echo.Context, req *dto.RegisterRequest) (*dto.ProfileResponse, error)
+	VerifyEmail(c echo.Context, req *dto.VerifyEmailRequest) (*dto.ProfileResponse, error)
+	ResendVerification(c echo.Context, req *dto.ResendVerificationRequest) error
+	Login(c echo.Context, req *dto.LoginRequest) (*dto.LoginResponse, error)
+	GetProfile(c echo.Context, userID uuid.UUID) (*dto.ProfileResponse, error)
+	RequestPhoneVerification(c echo.Context, userID uuid.UUID) error
+	VerifyPhone(c echo.Context, userID uuid.UUID, req *dto.VerifyPhoneRequest) (*dto.ProfileResponse, error)
+}
+
+type donorUsecase struct {
+	userRepo               repositories.UserRepository
+	verificationRepo       repositories.DonorVerificationRepository
+	donationRepository     repositories.DonationRepository
+	subscriptionRepository repositories.DonationSubscriptionRepository
+	transactionManager     repositories.TransactionManager
+	passwordUtil           password.PasswordUtil
+	sessionUsecase         SessionUsecase
+	notifier               DonorNotifier
+}
+
+func NewDonorUsecase(userRepo repositories.UserRepository, verificationRepo repositories.DonorVerificationRepository, donationRepository repositories.DonationRepository, subscriptionRepository repositories.DonationSubscriptionRepository, transactionManager repositories.TransactionManager, passwordUtil password.PasswordUtil, sessionUsecase SessionUsecase, notifier DonorNotifier) DonorUsecase {
+	return &donorUsecase{
+		userRepo:               userRepo,
+		verificationRepo:       verificationRepo,
+		donationRepository:     donationRepository,
+		subscriptionRepository: subscriptionRepository,
+		transactionManager:     transactionManager,
+		passwordUtil:           passwordUtil,
+		sessionUsecase:         sessionUsecase,
+		notifier:               notifier,
+	}
+}
+
+// Register membuat akun donatur yang belum terverifikasi dan mengirim token verifikasi email
+func (du *donorUsecase) Register(c echo.Context, req *dto.RegisterRequest) (*dto.ProfileResponse, error) {
+	ctx := c.Request().Context()
+	email := normalizeEmail(req.Email)
+
+	_, err := du.userRepo.GetRegisteredUserByEmail(ctx, email)
+	if err == nil {
+		return nil, err_util.ErrEmailRegistered
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hashedPassword, err := du.passwordUtil.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entities.User{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		Email:     email,
+		Address:   req.Address,
+		NoWA:      req.NoWA,
+		Password:  hashedPassword,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	var verificationToken string
+	err = du.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := du.userRepo.CreateUser(ctx, &user); err != nil {
+			return err
+		}
+		verificationToken, err = du.createVerification(ctx, user.ID, entities.VerificationChannelEmail, emailVerificationTTL)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := du.notifier.SendEmailVerification(ctx, &user, verificationToken); err != nil {
+		logrus.New().WithError(err).Warnf("Failed to send email verification to donor %s", user.ID)
+	}
+
+	response := toProfileResponse(&user)
+	return &response, nil
+}
+
+// VerifyEmail menandai email akun terverifikasi lalu menautkan donasi tamu dengan email yang sama
+func (du *donorUsecase) VerifyEmail(c echo.Context, req *dto.VerifyEmailRequest) (*dto.ProfileResponse, error) {
+	var user *entities.User
+	err := du.transactionManager.WithinTransaction(c.Request().Context(), func(ctx context.Context) error {
+		verification, err := du.verificationRepo.FindActiveByTokenHash(ctx, entities.VerificationChannelEmail, hashSecretToken(req.Token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return err_util.ErrInvalidVerification
+			}
+			return err
+		}
+
+		if err := du.useVerification(ctx, verification); err != nil {
+			return err
+		}
+
+		user, err = du.userRepo.GetUserByID(ctx, verification.UserID)
+		if err != nil {
+			return err
+		}
+		if user.EmailVerifiedAt == nil {
+			verifiedAt := time.Now()
+			user.EmailVerifiedAt = &verifiedAt
+			user.UpdatedAt = verifiedAt
+			if err := du.userRepo.UpdateUser(ctx, user); err != nil {
+				return err
+			}
+		}
+		return du.linkGuestDonations(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toProfileResponse(user)
+	return &response, nil
+}
+
+// ResendVerification mengirim ulang token verifikasi email. Email yang tidak terdaftar
+// atau sudah terverifikasi tidak menghasilkan error agar keberadaan akun tidak bocor.
+func (du *donorUsecase) ResendVerification(c echo.Context, req *dto.ResendVerificationRequest) error {
+	ctx := c.Request().Context()
+
+	user, err := du.userRepo.GetRegisteredUserByEmail(ctx, normalizeEmail(req.Email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	verificationToken, err := du.createVerification(ctx, user.ID, entities.VerificationChannelEmail, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return du.notifier.SendEmailVerification(ctx, user, verificationToken)
+}
+
+// Login membuat sesi baru dengan access token ber-role donor dan refresh token. Setiap login juga menautkan donasi tamu baru
+// yang dibuat dengan email atau nomor WhatsApp terverifikasi.
+func (du *donorUsecase) Login(c echo.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	ctx := c.Request().Context()
+
+	user, err := du.userRepo.GetRegisteredUserByEmail(ctx, normalizeEmail(req.Email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err_util.ErrPasswordMismatch
+		}
+		return nil, err
+	}
+	if err := du.passwordUtil.VerifyPassword(req.Password, user.Password); err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, err_util.ErrEmailNotVerified
+	}
+
+	if err := du.linkGuestDonations(ctx, user); err != nil {
+		logrus.New().WithError(err).Warnf("Failed to link guest donations to donor %s", user.ID)
+	}
+
+	session, err := du.sessionUsecase.CreateSession(c, user.ID, entities.RoleDonor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		ID:           user.ID.String(),
+		Name:         user.Name,
+		Email:        user.Email,
+		Token:        session.Token,
+		ExpiresAt:    session.ExpiresAt,
+		RefreshToken: session.RefreshToken,
+		Role:         entities.RoleDonor,
+	}, nil
+}
+
+func (du *donorUsecase) GetProfile(c echo.Context, userID uuid.UUID) (*dto.ProfileResponse, error) {
+	user, err := du.userRepo.GetUserByID(c.Request().Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toProfileResponse(user)
+	return &response, nil
+}
+
+// RequestPhoneVerification mengirim kode 6 digit ke nomor WhatsApp akun. Kode lama
+// dinonaktifkan, dan permintaan ditolak jika akun sudah terlalu sering meminta kode
+// atau salah memasukkan kode dalam jendela phoneVerificationWindow.
+func (du *donorUsecase) RequestPhoneVerification(c echo.Context, userID uuid.UUID) error {
+	ctx := c.Request().Context()
+
+	user, err := du.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.NoWA.IsZero() {
+		return err_util.ErrInvalidVerification
+	}
+
+	usage, err := du.verificationRepo.GetUsageSince(ctx, user.ID, entities.VerificationChannelPhone, time.Now().Add(-phoneVerificationWindow))
+	if err != nil {
+		return err
+	}
+	if usage.Issued >= phoneVerificationMaxRequests || usage.FailedAttempts >= phoneVerificationMaxFailures {
+		return err_util.ErrTooManyVerificationAttempts
+	}
+
+	code, err := du.createVerification(ctx, user.ID, entities.VerificationChannelPhone, phoneVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return du.notifier.SendPhoneVerification(ctx, user, code)
+}
+
+// VerifyPhone memeriksa kode WhatsApp lalu menautkan donasi tamu dengan nomor yang sama
+func (du *donorUsecase) VerifyPhone(c echo.Context, userID uuid.UUID, req *dto.VerifyPhoneRequest) (*dto.ProfileResponse, error) {
+	var (
+		user      *entities.User
+		wrongCode bool
+	)
+	err := du.transactionManager.WithinTransaction(c.Request().Context(), func(ctx context.Context) error {
+		verification, err := du.verificationRepo.FindLatestActive(ctx, userID, entities.VerificationChannelPhone)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return err_util.ErrInvalidVerification
+			}
+			return err
+		}
+
+		// Percobaan salah dihitung dari semua kode akun dalam jendela waktu yang sama
+		usage, err := du.verificationRepo.GetUsageSince(ctx, userID, entities.VerificationChannelPhone, time.Now().Add(-phoneVerificationWindow))
+		if err != nil {
+			return err
+		}
+		if usage.FailedAttempts >= phoneVerificationMaxFailures {
+			return err_util.ErrTooManyVerificationAttempts
+		}
+
+		// Percobaan kode yang salah tetap disimpan, jadi transaksi tidak dibatalkan
+		if hashSecretToken(userID.String()+req.Code) != verification.TokenHash {
+			wrongCode = true
+			verification.Attempts++
+			if verification.Attempts >= phoneVerificationMaxAttempts || usage.FailedAttempts+1 >= phoneVerificationMaxFailures {
+				verification.ExpiresAt = time.Now()
+			}
+			return du.verificationRepo.Update(ctx, verification)
+		}
+
+		if err := du.useVerification(ctx, verification); err != nil {
+			return err
+		}
+
+		user, err = du.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		verifiedAt := time.Now()
+		user.PhoneVerifiedAt = &verifiedAt
+		user.UpdatedAt = verifiedAt
+		if err := du.userRepo.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		return du.linkGuestDonations(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if wrongCode {
+		return nil, err_util.ErrInvalidVerification
+	}
+
+	response := toProfileResponse(user)
+	return &response, nil
+}
+
+// createVerification membuat token verifikasi baru. Token email berupa string acak
+// panjang, sedangkan kode WhatsApp 6 digit yang di-hash bersama ID akun.
+func (du *donorUsecase) createVerification(ctx context.Context, userID uuid.UUID, channel string, ttl time.Duration) (string, error) {
+	var (
+		secret    string
+		tokenHash string
+		err       error
+	)
+
+	if channel == entities.VerificationChannelPhone {
+		secret, err = generatePhoneCode()
+		tokenHash = hashSecretToken(userID.String() + secret)
+	} else {
+		secret, err = generateSecretToken()
+		tokenHash = hashSecretToken(secret)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	verification := entities.DonorVerification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Channel:   channel,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}
+	if err := du.verificationRepo.CreateVerification(ctx, &verification); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+func (du *donorUsecase) useVerification(ctx context.Context, verification *entities.DonorVerification) error {
+	usedAt := time.Now()
+	verification.UsedAt = &usedAt
+	return du.verificationRepo.Update(ctx, verification)
+}
+
+// linkGuestDonations menautkan donasi dan donasi rutin tamu ke akun memakai kanal yang
+// sudah terverifikasi saja
+func (du *donorUsecase) linkGuestDonations(ctx context.Context, user *entities.User) error {
+	email, noWA := "", phone.Number("")
+	if user.EmailVerifiedAt != nil {
+		email = user.Email
+	}
+	if user.PhoneVerifiedAt != nil {
+		noWA = user.NoWA
+	}
+
+	linked, err := du.donationRepository.LinkGuestDonations(ctx, user.ID, email, noWA)
+	if err != nil {
+		return err
+	}
+	if _, err := du.subscriptionRepository.LinkGuestSubscriptions(ctx, user.ID, email, noWA); err != nil {
+		return err
+	}
+
+	if linked > 0 {
+		logrus.New().Infof("Linked %d guest donations to donor %s", linked, user.ID)
+	}
+	return nil
+}
+
+func generatePhoneCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func toProfileResponse(user *entities.User) dto.ProfileResponse {
+	return dto.ProfileResponse{
+		ID:            user.ID.String(),
+		Name:          user.Name,
+		Email:         user.Email,
+		Address:       user.Address,
+		NoWA:          user.NoWA.String(),
+		EmailVerified: user.EmailVerifiedAt != nil,
+		PhoneVerified: user.PhoneVerifiedAt != nil,
+	}
+}
@@ -0,0 +1,455 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	dto_base "tugas-akhir/dto/base"
+	dto "tugas-akhir/dto/donor"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	"tugas-akhir/utils/contact"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Jarak maksimum antara dua email dengan nama donatur yang sama untuk dianggap salah ketik
+const similarEmailDistance = 2
+
+// Jumlah donasi yang dikelompokkan setiap kali worker direktori donatur berjalan
+const donorDirectoryBatch = 200
+
+type DonorDirectoryUsecase interface {
+	GroupDonations(ctx context.Context)
+	GetProfiles(c echo.Context, search string, req *dto_base.PaginationRequest) (*[]dto.DonorProfileResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
+	GetDuplicates(c echo.Context, status string) (*[]dto.DonorDuplicateResponse, error)
+	MergeProfiles(c echo.Context, targetID uuid.UUID, sourceID uuid.UUID, adminID uuid.UUID) (*dto.DonorProfileResponse, error)
+	DismissDuplicate(c echo.Context, duplicateID uuid.UUID, adminID uuid.UUID) (*dto.DonorDuplicateResponse, error)
+	ExportProfiles(c echo.Context) ([]byte, error)
+}
+
+type donorDirectoryUsecase struct {
+	donorProfileRepository repositories.DonorProfileRepository
+	transactionManager     repositories.TransactionManager
+	running                sync.Mutex
+}
+
+func NewDonorDirectoryUsecase(donorProfileRepository repositories.DonorProfileRepository, transactionManager repositories.TransactionManager) DonorDirectoryUsecase {
+	return &donorDirectoryUsecase{
+		donorProfileRepository: donorProfileRepository,
+		transactionManager:     transactionManager,
+	}
+}
+
+// GroupDonations dijalankan scheduler: memasukkan donasi yang belum punya profil ke profil
+// donatur berdasarkan email dan nomor WhatsApp yang sudah dinormalkan. Donasi dibaca per
+// halaman dengan cursor sehingga donasi yang gagal dikelompokkan tidak menghalangi donasi
+// yang lebih baru.
+func (du *donorDirectoryUsecase) GroupDonations(ctx context.Context) {
+	if !du.running.TryLock() {
+		return
+	}
+	defer du.running.Unlock()
+
+	log := logrus.New()
+
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	for {
+		donations, err := du.donorProfileRepository.GetUnassignedDonations(ctx, after, afterID, donorDirectoryBatch)
+		if err != nil {
+			log.WithError(err).Error("Failed to get donations without donor profile")
+			return
+		}
+
+		for i := range donations {
+			donation := &donations[i]
+			err := du.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+				return du.assignDonation(ctx, donation)
+			})
+			if err != nil {
+				log.WithError(err).Warnf("Failed to assign donation %s to donor profile", donation.ID)
+			}
+		}
+
+		if len(donations) < donorDirectoryBatch {
+			return
+		}
+		last := donations[len(donations)-1]
+		after, afterID = &last.CreatedAt, last.ID
+	}
+}
+
+// assignDonation mencari profil pemilik email atau nomor WhatsApp donasi. Jika email dan
+// nomor dimiliki dua profil berbeda, donasi masuk ke profil pemilik email dan pasangan
+// profil tersebut dicatat sebagai dugaan ganda.
+func (du *donorDirectoryUsecase) assignDonation(ctx context.Context, donation *entities.Donation) error {
+	emailKey := contact.EmailKey(donation.Email)
+	phoneKey := contact.PhoneKey(donation.NoWA)
+
+	var byEmail, byPhone *entities.DonorProfile
+	var err error
+	if emailKey != "" {
+		if byEmail, err = du.findActiveProfile(ctx, entities.DonorIdentifierEmail, emailKey); err != nil {
+			return err
+		}
+	}
+	if phoneKey != "" {
+		if byPhone, err = du.findActiveProfile(ctx, entities.DonorIdentifierPhone, phoneKey); err != nil {
+			return err
+		}
+	}
+
+	profile := byEmail
+	if profile == nil {
+		profile = byPhone
+	}
+
+	if profile == nil {
+		profile = &entities.DonorProfile{
+			ID:    uuid.New(),
+			Name:  donation.Name,
+			Email: donation.Email,
+			NoWA:  donation.NoWA,
+		}
+		if err := du.donorProfileRepository.CreateProfile(ctx, profile); err != nil {
+			return err
+		}
+		if err := du.flagSimilarProfiles(ctx, profile, emailKey); err != nil {
+			return err
+		}
+	} else {
+		// Data kontak profil mengikuti donasi terbaru
+		profile.Name = donation.Name
+		if donation.Email != "" {
+			profile.Email = donation.Email
+		}
+		if !donation.NoWA.IsZero() {
+			profile.NoWA = donation.NoWA
+		}
+	}
+
+	if byEmail != nil && byPhone != nil && byEmail.ID != byPhone.ID {
+		if err := du.flagDuplicate(ctx, byEmail.ID, byPhone.ID, entities.DonorDuplicateSharedPhone); err != nil {
+			return err
+		}
+	}
+
+	if profile.UserID == nil && donation.UserID != nil {
+		profile.UserID = donation.UserID
+	}
+	if err := du.donorProfileRepository.UpdateProfile(ctx, profile); err != nil {
+		return err
+	}
+
+	for kind, value := range map[string]string{entities.DonorIdentifierEmail: emailKey, entities.DonorIdentifierPhone: phoneKey} {
+		if value == "" {
+			continue
+		}
+		identifier := &entities.DonorIdentifier{ID: uuid.New(), ProfileID: profile.ID, Kind: kind, Value: value}
+		if err := du.donorProfileRepository.AddIdentifier(ctx, identifier); err != nil {
+			return err
+		}
+	}
+
+	return du.donorProfileRepository.AssignDonation(ctx, donation.ID, profile.ID)
+}
+
+// findActiveProfile mengambil profil pemilik identitas. Identitas profil yang sudah
+// digabung ikut pindah, tetapi rantai MergedIntoID tetap diikuti untuk berjaga-jaga.
+func (du *donorDirectoryUsecase) findActiveProfile(ctx context.Context, kind string, value string) (*entities.DonorProfile, error) {
+	profile, err := du.donorProfileRepository.FindProfileByIdentifier(ctx, kind, value)
+	if err != nil || profile == nil {
+		return profile, err
+	}
+
+	for profile.MergedIntoID != nil {
+		if profile, err = du.donorProfileRepository.FindProfileForUpdate(ctx, *profile.MergedIntoID); err != nil {
+			return nil, err
+		}
+	}
+	return profile, nil
+}
+
+// flagSimilarProfiles mencatat profil lain dengan nama sama yang emailnya hanya berbeda
+// beberapa huruf dari profil baru
+func (du *donorDirectoryUsecase) flagSimilarProfiles(ctx context.Context, profile *entities.DonorProfile, emailKey string) error {
+	if emailKey == "" {
+		return nil
+	}
+
+	candidates, err := du.donorProfileRepository.GetActiveProfilesByName(ctx, contact.NameKey(profile.Name))
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		if candidate.ID == profile.ID {
+			continue
+		}
+
+		identifiers, err := du.donorProfileRepository.GetIdentifiers(ctx, candidate.ID)
+		if err != nil {
+			return err
+		}
+		for _, identifier := range identifiers {
+			if identifier.Kind == entities.DonorIdentifierEmail && contact.Distance(identifier.Value, emailKey) <= similarEmailDistance {
+				if err := du.flagDuplicate(ctx, candidate.ID, profile.ID, entities.DonorDuplicateSimilarEmail); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// flagDuplicate mencatat dugaan ganda dengan urutan pasangan yang tetap agar pasangan
+// yang sama tidak tercatat dua kali
+func (du *donorDirectoryUsecase) flagDuplicate(ctx context.Context, first uuid.UUID, second uuid.UUID, reason string) error {
+	if first.String() > second.String() {
+		first, second = second, first
+	}
+
+	return du.donorProfileRepository.CreateDuplicate(ctx, &entities.DonorDuplicate{
+		ID:          uuid.New(),
+		ProfileID:   first,
+		CandidateID: second,
+		Reason:      reason,
+		Status:      entities.DonorDuplicateOpen,
+	})
+}
+
+func (du *donorDirectoryUsecase) GetProfiles(c echo.Context, search string, req *dto_base.PaginationRequest) (*[]dto.DonorProfileResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
+	ctx := c.Request().Context()
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	profiles, totalData, err := du.donorProfileRepository.GetProfiles(ctx, search, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	totalPage := int(math.Ceil(float64(totalData) / float64(req.Limit)))
+	if totalData > 0 && req.Page > totalPage {
+		return nil, nil, nil, err_util.ErrPageNotFound
+	}
+
+	responses := []dto.DonorProfileResponse{}
+	for i := range profiles {
+		response, err := du.toProfileResponse(ctx, &profiles[i])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		responses = append(responses, *response)
+	}
+
+	baseURL := fmt.Sprintf("%s?limit=%d&page=", c.Request().URL.Path, req.Limit)
+	link := &dto_base.Link{}
+	if req.Page > 1 {
+		link.Prev = baseURL + strconv.Itoa(req.Page-1)
+	}
+	if req.Page < totalPage {
+		link.Next = baseURL + strconv.Itoa(req.Page+1)
+	}
+
+	return &responses, &dto_base.PaginationMetadata{
+		TotalData:   totalData,
+		TotalPage:   totalPage,
+		CurrentPage: req.Page,
+	}, link, nil
+}
+
+func (du *donorDirectoryUsecase) GetDuplicates(c echo.Context, status string) (*[]dto.DonorDuplicateResponse, error) {
+	ctx := c.Request().Context()
+
+	duplicates, err := du.donorProfileRepository.GetDuplicates(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.DonorDuplicateResponse{}
+	for i := range duplicates {
+		response, err := du.toDuplicateResponse(ctx, &duplicates[i])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return &responses, nil
+}
+
+// MergeProfiles menggabungkan profil sumber ke profil tujuan. Donasi dan identitas
+// sumber berpindah ke tujuan, dan profil sumber tidak lagi ditampilkan.
+func (du *donorDirectoryUsecase) MergeProfiles(c echo.Context, targetID uuid.UUID, sourceID uuid.UUID, adminID uuid.UUID) (*dto.DonorProfileResponse, error) {
+	ctx := c.Request().Context()
+
+	if targetID == sourceID {
+		return nil, err_util.ErrInvalidDonorMerge
+	}
+
+	err := du.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		target, err := du.donorProfileRepository.FindProfileForUpdate(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		source, err := du.donorProfileRepository.FindProfileForUpdate(ctx, sourceID)
+		if err != nil {
+			return err
+		}
+		if target.MergedIntoID != nil || source.MergedIntoID != nil {
+			return err_util.ErrInvalidDonorMerge
+		}
+
+		if target.UserID == nil && source.UserID != nil {
+			target.UserID = source.UserID
+			if err := du.donorProfileRepository.UpdateProfile(ctx, target); err != nil {
+				return err
+			}
+		}
+
+		return du.donorProfileRepository.MergeProfile(ctx, source.ID, target.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.New().Infof("Donor profile %s merged into %s by admin %s", sourceID, targetID, adminID)
+
+	profiles, err := du.donorProfileRepository.GetProfilesByIDs(ctx, []uuid.UUID{targetID})
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return du.toProfileResponse(ctx, &profiles[0])
+}
+
+// DismissDuplicate menandai dugaan ganda sebagai bukan orang yang sama
+func (du *donorDirectoryUsecase) DismissDuplicate(c echo.Context, duplicateID uuid.UUID, adminID uuid.UUID) (*dto.DonorDuplicateResponse, error) {
+	ctx := c.Request().Context()
+
+	var duplicate *entities.DonorDuplicate
+	err := du.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		duplicate, err = du.donorProfileRepository.FindDuplicateForUpdate(ctx, duplicateID)
+		if err != nil {
+			return err
+		}
+		if duplicate.Status != entities.DonorDuplicateOpen {
+			return err_util.ErrDonorDuplicateReviewed
+		}
+
+		now := time.Now()
+		duplicate.Status = entities.DonorDuplicateDismissed
+		duplicate.ReviewedBy = &adminID
+		duplicate.ReviewedAt = &now
+		return du.donorProfileRepository.UpdateDuplicate(ctx, duplicate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return du.toDuplicateResponse(ctx, duplicate)
+}
+
+// ExportProfiles menyusun CSV seluruh profil donatur beserta rekap donasinya
+func (du *donorDirectoryUsecase) ExportProfiles(c echo.Context) ([]byte, error) {
+	profiles, err := du.donorProfileRepository.GetAllProfiles(c.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"ID", "Nama", "Email", "No WA", "Jumlah Donasi", "Total Donasi", "Donasi Pertama", "Donasi Terakhir"})
+	for _, profile := range profiles {
+		writer.Write([]string{
+			profile.ID.String(),
+			profile.Name,
+			profile.Email,
+			profile.NoWA.String(),
+			strconv.FormatInt(profile.DonationCount, 10),
+			strconv.FormatInt(profile.TotalAmount, 10),
+			formatPaidAt(profile.FirstDonationAt),
+			formatPaidAt(profile.LastDonationAt),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (du *donorDirectoryUsecase) toProfileResponse(ctx context.Context, profile *entities.DonorProfileSummary) (*dto.DonorProfileResponse, error) {
+	identifiers, err := du.donorProfileRepository.GetIdentifiers(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DonorProfileResponse{
+		ID:              profile.ID.String(),
+		Name:            profile.Name,
+		Email:           profile.Email,
+		NoWA:            profile.NoWA.String(),
+		DonationCount:   profile.DonationCount,
+		TotalAmount:     profile.TotalAmount,
+		FirstDonationAt: formatPaidAt(profile.FirstDonationAt),
+		LastDonationAt:  formatPaidAt(profile.LastDonationAt),
+	}
+	if profile.UserID != nil {
+		response.UserID = profile.UserID.String()
+	}
+	for _, identifier := range identifiers {
+		response.Identifiers = append(response.Identifiers, identifier.Value)
+	}
+	return response, nil
+}
+
+func (du *donorDirectoryUsecase) toDuplicateResponse(ctx context.Context, duplicate *entities.DonorDuplicate) (*dto.DonorDuplicateResponse, error) {
+	profiles, err := du.donorProfileRepository.GetProfilesByIDs(ctx, []uuid.UUID{duplicate.ProfileID, duplicate.CandidateID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DonorDuplicateResponse{
+		ID:        duplicate.ID.String(),
+		Reason:    duplicate.Reason,
+		Status:    duplicate.Status,
+		CreatedAt: duplicate.CreatedAt.Format(time.RFC3339),
+	}
+	for i := range profiles {
+		profile, err := du.toProfileResponse(ctx, &profiles[i])
+		if err != nil {
+			return nil, err
+		}
+		switch profiles[i].ID {
+		case duplicate.ProfileID:
+			response.Profile = profile
+		case duplicate.CandidateID:
+			response.Candidate = profile
+		}
+	}
+	if duplicate.ReviewedBy != nil {
+		response.ReviewedBy = duplicate.ReviewedBy.String()
+	}
+	if duplicate.ReviewedAt != nil {
+		response.ReviewedAt = duplicate.ReviewedAt.Format(time.RFC3339)
+	}
+	return response, nil
+}
@@ -0,0 +1,299 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"tugas-akhir/drivers/payment"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const offlineDateLayout = "2006-01-02"
+
+type OfflineDonationUsecase interface {
+	RecordOfflineDonation(c echo.Context, adminID uuid.UUID, req *dto.OfflineDonationRequest) (*dto.DonationResponse, error)
+	SubmitTransferProof(c echo.Context, donationID uuid.UUID, req *dto.TransferProofRequest) (*dto.TransferProofResponse, error)
+	GetTransferProofs(c echo.Context, status string) (*[]dto.TransferProofResponse, error)
+	ApproveTransferProof(c echo.Context, proofID uuid.UUID, adminID uuid.UUID) (*dto.TransferProofResponse, error)
+	RejectTransferProof(c echo.Context, proofID uuid.UUID, adminID uuid.UUID, req *dto.RejectTransferProofRequest) (*dto.TransferProofResponse, error)
+}
+
+type offlineDonationUsecase struct {
+	donationUsecase         DonationUsecase
+	donationRepository      repositories.DonationRepository
+	programDonation         repositories.ProgramDonationRepository
+	transferProofRepository repositories.TransferProofRepository
+	transactionManager      repositories.TransactionManager
+}
+
+func NewOfflineDonationUsecase(donationUsecase DonationUsecase, donationRepository repositories.DonationRepository, programDonation repositories.ProgramDonationRepository, transferProofRepository repositories.TransferProofRepository, transactionManager repositories.TransactionManager) OfflineDonationUsecase {
+	return &offlineDonationUsecase{
+		donationUsecase:         donationUsecase,
+		donationRepository:      donationRepository,
+		programDonation:         programDonation,
+		transferProofRepository: transferProofRepository,
+		transactionManager:      transactionManager,
+	}
+}
+
+// RecordOfflineDonation mencatat donasi transfer langsung atau tunai yang diterima admin.
+// Donasi dibuat pending lalu dilunasi lewat RecordTransactionStatus agar currentAmount
+// dan catatan notifikasi diperbarui dengan cara yang sama seperti pembayaran Midtrans.
+// Notifikasi lunas baru dikirim setelah transaksi di-commit.
+func (ou *offlineDonationUsecase) RecordOfflineDonation(c echo.Context, adminID uuid.UUID, req *dto.OfflineDonationRequest) (*dto.DonationResponse, error) {
+	log := logrus.New()
+
+	paidAt, err := time.ParseInLocation(offlineDateLayout, req.PaidAt, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := ou.programDonation.GetProgramDonationByID(c.Request().Context(), req.ProgramID)
+	if err != nil {
+		log.WithError(err).Error("Program not found")
+		return nil, errors.New("program donation not found")
+	}
+
+	donation := entities.Donation{
+		ID:                uuid.New(),
+		Name:              req.Name,
+		Address:           req.Address,
+		NoWA:              req.NoWA,
+		Email:             req.Email,
+		Amount:            req.Amount,
+		Message:           req.Message,
+		Status:            entities.DonationStatusPending,
+		ProgramDonationID: program.ID,
+		PaymentAttempt:    1,
+		PaymentChannel:    req.PaymentChannel,
+		PaidAt:            &paidAt,
+		ProofImageURL:     req.ProofImageURL,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+	donation.OrderID = entities.DonationOrderID(donation.ID, donation.PaymentAttempt)
+
+	var change *DonationStatusChange
+	err = ou.transactionManager.WithinTransaction(c.Request().Context(), func(ctx context.Context) error {
+		if err := ou.donationRepository.CreateDonation(ctx, &donation); err != nil {
+			return err
+		}
+
+		change, err = ou.donationUsecase.RecordTransactionStatus(ctx, payment.TransactionStatus{
+			OrderID:           donation.OrderID,
+			TransactionID:     "offline-" + donation.ID.String(),
+			TransactionStatus: "settlement",
+			TransactionTime:   paidAt.Format(payment.TimeLayout),
+			SettlementTime:    paidAt.Format(payment.TimeLayout),
+			PaymentType:       req.PaymentChannel,
+			GrossAmount:       strconv.Itoa(donation.Amount),
+			StatusMessage:     "recorded by admin " + adminID.String(),
+		})
+		return err
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to record offline donation")
+		return nil, err
+	}
+	ou.donationUsecase.NotifyStatusChange(c.Request().Context(), change)
+
+	log.Infof("Offline donation %s (%s) recorded by admin %s", donation.ID, donation.PaymentChannel, adminID)
+	return &dto.DonationResponse{
+		ID:             donation.ID.String(),
+		Name:           donation.Name,
+		Address:        donation.Address,
+		NoWA:           donation.NoWA.String(),
+		Email:          donation.Email,
+		Amount:         donation.Amount,
+		Message:        donation.Message,
+		Status:         int(change.To),
+		StatusLabel:    change.To.String(),
+		ProgramID:      program.ID.String(),
+		ProgramTitle:   program.Title,
+		PaymentChannel: donation.PaymentChannel,
+		PaidAt:         paidAt.Format(offlineDateLayout),
+		ProofImageURL:  donation.ProofImageURL,
+	}, nil
+}
+
+// SubmitTransferProof menyimpan bukti transfer dari donatur untuk donasi yang masih pending
+func (ou *offlineDonationUsecase) SubmitTransferProof(c echo.Context, donationID uuid.UUID, req *dto.TransferProofRequest) (*dto.TransferProofResponse, error) {
+	ctx := c.Request().Context()
+
+	transferredAt, err := time.ParseInLocation(offlineDateLayout, req.TransferredAt, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	donation, err := ou.donationRepository.GetDonationByID(ctx, donationID)
+	if err != nil {
+		return nil, err
+	}
+	if donation.Status != entities.DonationStatusPending {
+		return nil, err_util.ErrDonationNotResumable
+	}
+
+	proof := entities.TransferProof{
+		ID:            uuid.New(),
+		DonationID:    donation.ID,
+		ImageURL:      req.ImageURL,
+		BankName:      req.BankName,
+		AccountName:   req.AccountName,
+		TransferredAt: transferredAt,
+		Status:        entities.TransferProofPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := ou.transferProofRepository.CreateProof(ctx, &proof); err != nil {
+		return nil, err
+	}
+
+	response := toTransferProofResponse(proof, donation)
+	return &response, nil
+}
+
+func (ou *offlineDonationUsecase) GetTransferProofs(c echo.Context, status string) (*[]dto.TransferProofResponse, error) {
+	ctx := c.Request().Context()
+
+	proofs, err := ou.transferProofRepository.GetProofs(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.TransferProofResponse{}
+	for _, proof := range proofs {
+		donation, err := ou.donationRepository.GetDonationByID(ctx, proof.DonationID)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, toTransferProofResponse(proof, donation))
+	}
+	return &responses, nil
+}
+
+// ApproveTransferProof menyetujui bukti transfer lalu melunasi donasinya lewat
+// RecordTransactionStatus, dalam satu transaksi dengan perubahan status bukti. Notifikasi
+// lunas baru dikirim setelah transaksi di-commit.
+func (ou *offlineDonationUsecase) ApproveTransferProof(c echo.Context, proofID uuid.UUID, adminID uuid.UUID) (*dto.TransferProofResponse, error) {
+	var (
+		proof    *entities.TransferProof
+		donation *entities.Donation
+		change   *DonationStatusChange
+	)
+
+	err := ou.transactionManager.WithinTransaction(c.Request().Context(), func(ctx context.Context) error {
+		var err error
+		proof, err = ou.reviewProof(ctx, proofID, adminID, entities.TransferProofApproved)
+		if err != nil {
+			return err
+		}
+
+		donation, err = ou.donationRepository.GetDonationByID(ctx, proof.DonationID)
+		if err != nil {
+			return err
+		}
+
+		change, err = ou.donationUsecase.RecordTransactionStatus(ctx, payment.TransactionStatus{
+			OrderID:           donation.CurrentOrderID(),
+			TransactionID:     "proof-" + proof.ID.String(),
+			TransactionStatus: "settlement",
+			TransactionTime:   proof.TransferredAt.Format(payment.TimeLayout),
+			SettlementTime:    proof.TransferredAt.Format(payment.TimeLayout),
+			PaymentType:       entities.PaymentChannelBankTransfer,
+			GrossAmount:       strconv.Itoa(donation.Amount),
+			StatusMessage:     "transfer proof approved by admin " + adminID.String(),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Ambil ulang agar status donasi pada respons sudah lunas
+		donation, err = ou.donationRepository.FindByIdForUpdate(ctx, proof.DonationID)
+		return err
+	})
+	if err != nil {
+		logrus.New().WithError(err).Errorf("Failed to approve transfer proof %s", proofID)
+		return nil, err
+	}
+	ou.donationUsecase.NotifyStatusChange(c.Request().Context(), change)
+
+	response := toTransferProofResponse(*proof, donation)
+	return &response, nil
+}
+
+func (ou *offlineDonationUsecase) RejectTransferProof(c echo.Context, proofID uuid.UUID, adminID uuid.UUID, req *dto.RejectTransferProofRequest) (*dto.TransferProofResponse, error) {
+	var (
+		proof    *entities.TransferProof
+		donation *entities.Donation
+	)
+
+	err := ou.transactionManager.WithinTransaction(c.Request().Context(), func(ctx context.Context) error {
+		var err error
+		proof, err = ou.reviewProof(ctx, proofID, adminID, entities.TransferProofRejected)
+		if err != nil {
+			return err
+		}
+		proof.RejectReason = req.Reason
+		if err := ou.transferProofRepository.Update(ctx, proof); err != nil {
+			return err
+		}
+
+		donation, err = ou.donationRepository.GetDonationByID(ctx, proof.DonationID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTransferProofResponse(*proof, donation)
+	return &response, nil
+}
+
+// reviewProof mengunci bukti transfer yang masih pending dan menandainya sudah diperiksa
+func (ou *offlineDonationUsecase) reviewProof(ctx context.Context, proofID uuid.UUID, adminID uuid.UUID, status string) (*entities.TransferProof, error) {
+	proof, err := ou.transferProofRepository.FindByIdForUpdate(ctx, proofID)
+	if err != nil {
+		return nil, err
+	}
+	if proof.Status != entities.TransferProofPending {
+		return nil, err_util.ErrTransferProofReviewed
+	}
+
+	reviewedAt := time.Now()
+	proof.Status = status
+	proof.ReviewedBy = &adminID
+	proof.ReviewedAt = &reviewedAt
+	if err := ou.transferProofRepository.Update(ctx, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+func toTransferProofResponse(proof entities.TransferProof, donation *entities.Donation) dto.TransferProofResponse {
+	response := dto.TransferProofResponse{
+		ID:             proof.ID.String(),
+		DonationID:     proof.DonationID.String(),
+		DonorName:      donation.Name,
+		Amount:         donation.Amount,
+		ImageURL:       proof.ImageURL,
+		BankName:       proof.BankName,
+		AccountName:    proof.AccountName,
+		TransferredAt:  proof.TransferredAt.Format(offlineDateLayout),
+		Status:         proof.Status,
+		RejectReason:   proof.RejectReason,
+		DonationStatus: donation.Status.String(),
+		CreatedAt:      proof.CreatedAt.Format(time.RFC3339),
+	}
+	if proof.ReviewedAt != nil {
+		response.ReviewedAt = proof.ReviewedAt.Format(time.RFC3339)
+	}
+	return response
+}
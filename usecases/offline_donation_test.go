@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"testing"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+
+	"github.com/google/uuid"
+)
+
+func TestRecordOfflineDonation(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+
+	response, err := env.offlineUsecase.RecordOfflineDonation(newTestContext(), uuid.New(), &dto.OfflineDonationRequest{
+		Name:           "Budi",
+		Email:          "budi@example.com",
+		Amount:         250000,
+		ProgramID:      program.ID,
+		PaymentChannel: entities.PaymentChannelCash,
+		PaidAt:         "2026-10-01",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	donation := env.donations.get(t, uuid.MustParse(response.ID))
+	if donation.Status != entities.DonationStatusPaid || response.Status != int(entities.DonationStatusPaid) {
+		t.Errorf("donation %s with response status %d, want paid", donation.Status, response.Status)
+	}
+	if got := env.currentAmount(t, program.ID); got != 250000 {
+		t.Errorf("program amount %d, want 250000", got)
+	}
+
+	if got := env.feed.count(DonationEventPaid); got != 1 {
+		t.Errorf("published %d paid events, want 1", got)
+	}
+	if len(env.mailer.changes) != 1 || env.mailer.changes[0].DonationID != donation.ID || env.mailer.changes[0].To != entities.DonationStatusPaid {
+		t.Errorf("mailed changes %+v, want one paid change for donation %s", env.mailer.changes, donation.ID)
+	}
+	if env.feed.duringTransaction != 0 || env.mailer.duringTransaction != 0 {
+		t.Errorf("%d feed events and %d emails sent before commit, want none", env.feed.duringTransaction, env.mailer.duringTransaction)
+	}
+}
@@ -61,6 +61,9 @@ func (pdu *programDonationUsecase) CreateProgramDonation(c echo.Context, req *dt
 		GoalAmount:    req.GoalAmount,
 		CurrentAmount: 0,
 		DonationImage: programImages,
+
+		PaymentExpiryMinutes: req.PaymentExpiryMinutes,
+		EnabledPayments:      req.EnabledPayments,
 	}
 
 	// Log program donation details before saving
@@ -99,6 +102,8 @@ func (pdu *programDonationUsecase) GetProgramDonationByID(c echo.Context, progra
 		Deskripsi:             programDonation.Deskripsi,
 		GoalAmount:            programDonation.GoalAmount,
 		CurrentAmount:         programDonation.CurrentAmount,
+		PaymentExpiryMinutes:  programDonation.PaymentExpiryMinutes,
+		EnabledPayments:       programDonation.EnabledPayments,
 		ProgramDonationImages: programDonationImages,
 	}
 
@@ -151,6 +156,8 @@ func (pdu *programDonationUsecase) GetProgramDonationAll(c echo.Context, req *dt
 			Deskripsi:             p.Deskripsi,
 			GoalAmount:            p.GoalAmount,
 			CurrentAmount:         p.CurrentAmount,
+			PaymentExpiryMinutes:  p.PaymentExpiryMinutes,
+			EnabledPayments:       p.EnabledPayments,
 			ProgramDonationImages: programDonationImages,
 		})
 	}
@@ -197,6 +204,8 @@ func (pdu *programDonationUsecase) UpdateProgramDonation(c echo.Context, program
 	programDonation.Title = req.Title
 	programDonation.Deskripsi = req.Deskripsi
 	programDonation.GoalAmount = req.GoalAmount
+	programDonation.PaymentExpiryMinutes = req.PaymentExpiryMinutes
+	programDonation.EnabledPayments = req.EnabledPayments
 
 	err = pdu.programDonationRepo.UpdateProgramDonation(ctx, programDonation)
 	if err != nil {
@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/payment"
+	dto "tugas-akhir/dto/donation"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type ReconciliationUsecase interface {
+	Run(ctx context.Context, trigger string) (*dto.ReconciliationRunResponse, error)
+	RunNow(c echo.Context) (*dto.ReconciliationRunResponse, error)
+	GetLastRun(c echo.Context) (*dto.ReconciliationRunResponse, error)
+}
+
+type reconciliationUsecase struct {
+	donationUsecase          DonationUsecase
+	donationRepository       repositories.DonationRepository
+	reconciliationRepository repositories.ReconciliationRepository
+	paymentGateway           payment.PaymentGateway
+	config                   config.DonationConfig
+	running                  sync.Mutex
+}
+
+func NewReconciliationUsecase(donationUsecase DonationUsecase, donationRepository repositories.DonationRepository, reconciliationRepository repositories.ReconciliationRepository, paymentGateway payment.PaymentGateway, config config.DonationConfig) ReconciliationUsecase {
+	return &reconciliationUsecase{
+		donationUsecase:          donationUsecase,
+		donationRepository:       donationRepository,
+		reconciliationRepository: reconciliationRepository,
+		paymentGateway:           paymentGateway,
+		config:                   config,
+	}
+}
+
+// Run mengecek donasi pending yang lebih tua dari ReconcileMinAge ke payment gateway
+// dan menerapkan statusnya lewat jalur yang sama dengan webhook
+func (ru *reconciliationUsecase) Run(ctx context.Context, trigger string) (*dto.ReconciliationRunResponse, error) {
+	if !ru.running.TryLock() {
+		return nil, err_util.ErrReconciliationRunning
+	}
+	defer ru.running.Unlock()
+
+	log := logrus.New()
+
+	run := entities.ReconciliationRun{
+		ID:        uuid.New(),
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+	}
+
+	// Donasi dibaca per halaman dengan cursor sehingga donasi yang statusnya belum berubah
+	// di gateway tidak menghalangi donasi yang lebih baru
+	var (
+		after   *time.Time
+		afterID uuid.UUID
+	)
+	for {
+		donations, err := ru.donationRepository.GetUnsettledDonations(ctx, run.StartedAt.Add(-ru.config.ReconcileMinAge), after, afterID, ru.config.ReconcileBatch)
+		if err != nil {
+			log.WithError(err).Error("Failed to get pending donations for reconciliation")
+			return nil, err
+		}
+
+		for _, donation := range donations {
+			run.Checked++
+
+			item := entities.ReconciliationItem{
+				ID:             uuid.New(),
+				RunID:          run.ID,
+				DonationID:     donation.ID,
+				OrderID:        donation.CurrentOrderID(),
+				PreviousStatus: donation.Status,
+				NewStatus:      donation.Status,
+			}
+
+			status, err := ru.paymentGateway.QueryStatus(ctx, item.OrderID)
+			// Order Snap yang belum pernah dibuka donatur belum ada di gateway, donasinya
+			// dibiarkan pending sampai dibayar atau dikedaluwarsakan
+			if errors.Is(err, payment.ErrTransactionNotFound) && donation.Status == entities.DonationStatusPending {
+				continue
+			}
+			if err != nil {
+				log.WithError(err).Warnf("Failed to query status of order %s", item.OrderID)
+				item.Error = err.Error()
+				run.Failed++
+				run.Items = append(run.Items, item)
+				continue
+			}
+			item.GatewayStatus = status.TransactionStatus
+
+			// Status di gateway belum berubah, tidak ada yang perlu diterapkan
+			if next, known := status.DonationStatus(); !known || next == donation.Status {
+				continue
+			}
+
+			change, err := ru.donationUsecase.ApplyTransactionStatus(ctx, *status)
+			if err != nil {
+				log.WithError(err).Warnf("Failed to apply status %s to order %s", status.TransactionStatus, item.OrderID)
+				item.Error = err.Error()
+				run.Failed++
+				run.Items = append(run.Items, item)
+				continue
+			}
+
+			// Hanya donasi yang berubah yang dicatat
+			if change.Changed() {
+				item.PreviousStatus = change.From
+				item.NewStatus = change.To
+				run.Updated++
+				run.Items = append(run.Items, item)
+			}
+		}
+
+		if len(donations) < ru.config.ReconcileBatch {
+			break
+		}
+		last := donations[len(donations)-1]
+		after, afterID = &last.CreatedAt, last.ID
+	}
+
+	run.FinishedAt = time.Now()
+	if err := ru.reconciliationRepository.CreateRun(ctx, &run); err != nil {
+		log.WithError(err).Error("Failed to save reconciliation run")
+		return nil, err
+	}
+
+	log.Infof("Reconciliation %s finished: checked=%d updated=%d failed=%d", run.ID, run.Checked, run.Updated, run.Failed)
+	return toReconciliationRunResponse(&run), nil
+}
+
+func (ru *reconciliationUsecase) RunNow(c echo.Context) (*dto.ReconciliationRunResponse, error) {
+	return ru.Run(c.Request().Context(), "manual")
+}
+
+func (ru *reconciliationUsecase) GetLastRun(c echo.Context) (*dto.ReconciliationRunResponse, error) {
+	run, err := ru.reconciliationRepository.GetLastRun(c.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, nil
+	}
+	return toReconciliationRunResponse(run), nil
+}
+
+func toReconciliationRunResponse(run *entities.ReconciliationRun) *dto.ReconciliationRunResponse {
+	items := []dto.ReconciliationItemResponse{}
+	for _, item := range run.Items {
+		items = append(items, dto.ReconciliationItemResponse{
+			DonationID:     item.DonationID.String(),
+			OrderID:        item.OrderID,
+			GatewayStatus:  item.GatewayStatus,
+			PreviousStatus: item.PreviousStatus.String(),
+			NewStatus:      item.NewStatus.String(),
+			Error:          item.Error,
+		})
+	}
+
+	return &dto.ReconciliationRunResponse{
+		ID:         run.ID.String(),
+		Trigger:    run.Trigger,
+		Checked:    run.Checked,
+		Updated:    run.Updated,
+		Failed:     run.Failed,
+		StartedAt:  run.StartedAt.Format(time.RFC3339),
+		FinishedAt: run.FinishedAt.Format(time.RFC3339),
+		Items:      items,
+	}
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+
+	"github.com/google/uuid"
+)
+
+func TestReconciliationSkipsOrdersMissingAtGateway(t *testing.T) {
+	program := newTestProgram("Pendidikan")
+	env := newDonationTestEnv(t, program)
+
+	// Donasi yang sudah dibayar di gateway tetapi webhooknya tidak sampai
+	paidResponse, err := env.usecase.CreateDonation(newTestContext(), testDonationRequest(program.ID, 100000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paid := env.donations.get(t, uuid.MustParse(paidResponse.ID))
+	if _, err := env.gateway.SetStatus(paid.OrderID, "settlement"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Donasi yang link Snap-nya belum pernah dibuka sehingga ordernya belum ada di gateway
+	unopened := entities.Donation{ID: uuid.New(), Amount: 50000, Status: entities.DonationStatusPending, ProgramDonationID: program.ID, CreatedAt: time.Now().Add(-time.Hour)}
+	// Donasi challenged selalu sudah ada di gateway, jadi order yang hilang tetap dianggap gagal
+	challenged := entities.Donation{ID: uuid.New(), Amount: 50000, Status: entities.DonationStatusChallenged, ProgramDonationID: program.ID, CreatedAt: time.Now().Add(-time.Hour)}
+	env.donations.donations[unopened.ID] = unopened
+	env.donations.donations[challenged.ID] = challenged
+
+	runs := &memReconciliationRepo{}
+	usecase := NewReconciliationUsecase(env.usecase, env.donations, runs, env.gateway, config.DonationConfig{ReconcileBatch: 10})
+	response, err := usecase.Run(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Checked != 3 || response.Updated != 1 || response.Failed != 1 {
+		t.Errorf("checked=%d updated=%d failed=%d, want checked=3 updated=1 failed=1", response.Checked, response.Updated, response.Failed)
+	}
+	if len(runs.runs) != 1 {
+		t.Fatalf("saved %d runs, want 1", len(runs.runs))
+	}
+	for _, item := range runs.runs[0].Items {
+		if item.DonationID == unopened.ID {
+			t.Errorf("unopened order recorded as %+v, want it skipped", item)
+		}
+	}
+	if got := env.donations.get(t, paid.ID).Status; got != entities.DonationStatusPaid {
+		t.Errorf("paid donation %s after reconciliation, want paid", got)
+	}
+	if got := env.donations.get(t, unopened.ID).Status; got != entities.DonationStatusPending {
+		t.Errorf("unopened donation %s after reconciliation, want pending", got)
+	}
+}
+
+func (r *memDonationRepo) GetUnsettledDonations(ctx context.Context, createdBefore time.Time, after *time.Time, afterID uuid.UUID, limit int) ([]entities.Donation, error) {
+	var donations []entities.Donation
+	for _, donation := range r.donations {
+		if donation.Status != entities.DonationStatusPending && donation.Status != entities.DonationStatusChallenged {
+			continue
+		}
+		if !donation.CreatedAt.Before(createdBefore) {
+			continue
+		}
+		if after != nil && (donation.CreatedAt.Before(*after) || donation.CreatedAt.Equal(*after) && donation.ID.String() <= afterID.String()) {
+			continue
+		}
+		donations = append(donations, donation)
+	}
+	slices.SortFunc(donations, func(a, b entities.Donation) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	if len(donations) > limit {
+		donations = donations[:limit]
+	}
+	return donations, nil
+}
+
+type memReconciliationRepo struct {
+	repositories.ReconciliationRepository
+	runs []entities.ReconciliationRun
+}
+
+func (r *memReconciliationRepo) CreateRun(ctx context.Context, run *entities.ReconciliationRun) error {
+	r.runs = append(r.runs, *run)
+	return nil
+}
@@ -0,0 +1,219 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	dto "tugas-akhir/dto/admin"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type RoleUsecase interface {
+	HasPermission(ctx context.Context, role string, permission string) (bool, error)
+	GetPermissions(ctx context.Context, role string) ([]string, error)
+	GetRoles(c echo.Context) (*[]dto.RoleResponse, error)
+	GetAllPermissions(c echo.Context) (*[]dto.PermissionResponse, error)
+	UpdateRolePermissions(c echo.Context, name string, req *dto.UpdateRolePermissionsRequest) (*dto.RoleResponse, error)
+	AssignRole(c echo.Context, adminID uuid.UUID, actorID uuid.UUID, req *dto.AssignRoleRequest) (*dto.AdminResponse, error)
+}
+
+type roleUsecase struct {
+	roleRepo           repositories.RoleRepository
+	adminRepo          repositories.AdminRepository
+	sessionUsecase     SessionUsecase
+	transactionManager repositories.TransactionManager
+}
+
+func NewRoleUsecase(roleRepo repositories.RoleRepository, adminRepo repositories.AdminRepository, sessionUsecase SessionUsecase, transactionManager repositories.TransactionManager) RoleUsecase {
+	return &roleUsecase{
+		roleRepo:           roleRepo,
+		adminRepo:          adminRepo,
+		sessionUsecase:     sessionUsecase,
+		transactionManager: transactionManager,
+	}
+}
+
+// HasPermission dipakai policy route. Hak akses dibaca langsung dari database agar
+// perubahan role berlaku tanpa menunggu token admin diperbarui.
+func (ru *roleUsecase) HasPermission(ctx context.Context, role string, permission string) (bool, error) {
+	if role == entities.RoleSuperAdmin {
+		return true, nil
+	}
+	// Hak mengatur role tetap milik super admin meskipun tercatat pada role lain
+	if permission == entities.PermissionRoleManage {
+		return false, nil
+	}
+	return ru.roleRepo.HasPermission(ctx, role, permission)
+}
+
+// GetPermissions mengambil hak akses efektif role, super admin selalu mendapat semua hak akses
+func (ru *roleUsecase) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	if role != entities.RoleSuperAdmin {
+		return ru.roleRepo.GetRolePermissions(ctx, role)
+	}
+
+	permissions, err := ru.roleRepo.GetPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	return names, nil
+}
+
+func (ru *roleUsecase) GetRoles(c echo.Context) (*[]dto.RoleResponse, error) {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	roles, err := ru.roleRepo.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		permissions, err := ru.GetPermissions(ctx, role.Name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, roleResponse(role, permissions))
+	}
+	return &result, nil
+}
+
+func (ru *roleUsecase) GetAllPermissions(c echo.Context) (*[]dto.PermissionResponse, error) {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	permissions, err := ru.roleRepo.GetPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		result = append(result, dto.PermissionResponse{
+			Name:        permission.Name,
+			Description: permission.Description,
+		})
+	}
+	return &result, nil
+}
+
+// UpdateRolePermissions mengganti hak akses role. Hak akses super admin tidak bisa
+// diubah agar selalu ada admin yang dapat mengatur role, dan hak mengatur role tidak
+// bisa diberikan ke role lain karena pemegangnya bisa menaikkan hak aksesnya sendiri.
+func (ru *roleUsecase) UpdateRolePermissions(c echo.Context, name string, req *dto.UpdateRolePermissionsRequest) (*dto.RoleResponse, error) {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	if name == entities.RoleSuperAdmin {
+		return nil, err_util.ErrRoleLocked
+	}
+
+	role, err := ru.roleRepo.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	known, err := ru.roleRepo.GetPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]string, 0, len(req.Permissions))
+	for _, permission := range req.Permissions {
+		if !slices.ContainsFunc(known, func(p entities.Permission) bool { return p.Name == permission }) {
+			return nil, err_util.ErrUnknownPermission
+		}
+		if permission == entities.PermissionRoleManage {
+			return nil, err_util.ErrPermissionReserved
+		}
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	slices.Sort(permissions)
+
+	err = ru.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		return ru.roleRepo.SetRolePermissions(ctx, role.Name, permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := roleResponse(*role, permissions)
+	return &response, nil
+}
+
+// AssignRole mengganti role akun admin. Admin tidak bisa mengganti role miliknya sendiri
+// sehingga super admin terakhir tidak bisa kehilangan aksesnya secara tidak sengaja.
+// Seperti InviteAdmin, hanya super admin yang bisa memberi atau mencabut role super admin.
+// Sesi admin dicabut saat role berubah agar token lama yang memuat role sebelumnya tidak
+// bisa dipakai lagi.
+func (ru *roleUsecase) AssignRole(c echo.Context, adminID uuid.UUID, actorID uuid.UUID, req *dto.AssignRoleRequest) (*dto.AdminResponse, error) {
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	if adminID == actorID {
+		return nil, err_util.ErrCannotChangeOwnRole
+	}
+
+	if _, err := ru.roleRepo.GetRole(ctx, req.Role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err_util.ErrUnknownRole
+		}
+		return nil, err
+	}
+
+	admin, err := ru.adminRepo.GetAdminByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if admin.Role == entities.RoleSuperAdmin || req.Role == entities.RoleSuperAdmin {
+		actor, err := ru.adminRepo.GetAdminByID(ctx, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if actor.Role != entities.RoleSuperAdmin {
+			return nil, err_util.ErrSuperAdminRequired
+		}
+	}
+
+	if admin.Role != req.Role {
+		err = ru.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := ru.adminRepo.UpdateAdmin(ctx, admin.ID, &entities.Admin{Role: req.Role}); err != nil {
+				return err
+			}
+			return ru.sessionUsecase.RevokeSessions(ctx, admin.ID, uuid.Nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	admin.Role = req.Role
+	response := toAdminResponse(admin)
+	return &response, nil
+}
+
+func roleResponse(role entities.Role, permissions []string) dto.RoleResponse {
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return dto.RoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+}
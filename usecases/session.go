@@ -0,0 +1,217 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+	dto "tugas-akhir/dto/auth"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+	"tugas-akhir/utils/token"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Masa berlaku sesi login. Setiap refresh memperpanjang sesi sejauh ini dari waktu refresh.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+type SessionUsecase interface {
+	CreateSession(c echo.Context, subjectID uuid.UUID, role string) (*dto.TokenResponse, error)
+	Refresh(c echo.Context, req *dto.RefreshRequest) (*dto.TokenResponse, error)
+	Logout(c echo.Context, sessionID uuid.UUID) error
+	LogoutAll(c echo.Context, subjectID uuid.UUID) (*dto.LogoutAllResponse, error)
+	RevokeSessions(ctx context.Context, subjectID uuid.UUID, exceptSessionID uuid.UUID) error
+	IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+type sessionUsecase struct {
+	sessionRepo        repositories.SessionRepository
+	adminRepo          repositories.AdminRepository
+	userRepo           repositories.UserRepository
+	transactionManager repositories.TransactionManager
+	tokenUtil          token.TokenUtil
+}
+
+func NewSessionUsecase(sessionRepo repositories.SessionRepository, adminRepo repositories.AdminRepository, userRepo repositories.UserRepository, transactionManager repositories.TransactionManager, tokenUtil token.TokenUtil) SessionUsecase {
+	return &sessionUsecase{
+		sessionRepo:        sessionRepo,
+		adminRepo:          adminRepo,
+		userRepo:           userRepo,
+		transactionManager: transactionManager,
+		tokenUtil:          tokenUtil,
+	}
+}
+
+// CreateSession membuat sesi baru saat login dan mengembalikan access token beserta
+// refresh token pertamanya
+func (su *sessionUsecase) CreateSession(c echo.Context, subjectID uuid.UUID, role string) (*dto.TokenResponse, error) {
+	ctx := c.Request().Context()
+
+	refreshToken, err := generateSecretToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := entities.Session{
+		ID:               uuid.New(),
+		SubjectID:        subjectID,
+		Role:             role,
+		RefreshTokenHash: hashSecretToken(refreshToken),
+		UserAgent:        truncate(c.Request().UserAgent(), 255),
+		IPAddress:        truncate(c.RealIP(), 45),
+		ExpiresAt:        now.Add(refreshTokenTTL),
+		LastUsedAt:       now,
+		CreatedAt:        now,
+	}
+	if err := su.sessionRepo.CreateSession(ctx, &session); err != nil {
+		return nil, err
+	}
+
+	return su.tokenResponse(&session, refreshToken)
+}
+
+// Refresh menukar refresh token dengan pasangan token baru. Refresh token lama langsung
+// tidak berlaku. Jika refresh token yang sudah ditukar dipakai lagi, token tersebut
+// dianggap bocor dan seluruh sesinya dicabut.
+func (su *sessionUsecase) Refresh(c echo.Context, req *dto.RefreshRequest) (*dto.TokenResponse, error) {
+	ctx := c.Request().Context()
+	tokenHash := hashSecretToken(req.RefreshToken)
+
+	// rejected diisi saat sesi dicabut di dalam transaksi. Penolakan dikembalikan setelah
+	// transaksi selesai agar pencabutan sesi tetap tersimpan.
+	var (
+		session      *entities.Session
+		refreshToken string
+		rejected     error
+	)
+	err := su.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		session, err = su.sessionRepo.FindByRefreshTokenHashForUpdate(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return err_util.ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		now := time.Now()
+		if !session.IsActive(now) {
+			return err_util.ErrInvalidRefreshToken
+		}
+		if session.RefreshTokenHash != tokenHash {
+			logrus.New().Warnf("Reused refresh token for session %s, session revoked", session.ID)
+			rejected = err_util.ErrInvalidRefreshToken
+			session.RevokedAt = &now
+			return su.sessionRepo.Update(ctx, session)
+		}
+
+		role, err := su.currentRole(ctx, session)
+		if errors.Is(err, err_util.ErrInvalidRefreshToken) || errors.Is(err, err_util.ErrAdminDeactivated) {
+			rejected = err
+			session.RevokedAt = &now
+			return su.sessionRepo.Update(ctx, session)
+		}
+		if err != nil {
+			return err
+		}
+
+		refreshToken, err = generateSecretToken()
+		if err != nil {
+			return err
+		}
+
+		session.Role = role
+		session.PreviousTokenHash = session.RefreshTokenHash
+		session.RefreshTokenHash = hashSecretToken(refreshToken)
+		session.ExpiresAt = now.Add(refreshTokenTTL)
+		session.LastUsedAt = now
+		return su.sessionRepo.Update(ctx, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rejected != nil {
+		return nil, rejected
+	}
+
+	return su.tokenResponse(session, refreshToken)
+}
+
+// Logout mencabut sesi milik access token yang sedang dipakai
+func (su *sessionUsecase) Logout(c echo.Context, sessionID uuid.UUID) error {
+	return su.sessionRepo.Revoke(c.Request().Context(), sessionID)
+}
+
+// LogoutAll mencabut semua sesi akun, termasuk sesi yang sedang dipakai
+func (su *sessionUsecase) LogoutAll(c echo.Context, subjectID uuid.UUID) (*dto.LogoutAllResponse, error) {
+	revoked, err := su.sessionRepo.RevokeAll(c.Request().Context(), subjectID, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LogoutAllResponse{RevokedSessions: revoked}, nil
+}
+
+// RevokeSessions dipakai saat akun dinonaktifkan, dihapus, berganti password, atau berganti role
+func (su *sessionUsecase) RevokeSessions(ctx context.Context, subjectID uuid.UUID, exceptSessionID uuid.UUID) error {
+	_, err := su.sessionRepo.RevokeAll(ctx, subjectID, exceptSessionID)
+	return err
+}
+
+// IsSessionRevoked dipakai policy route untuk menolak access token dari sesi yang sudah
+// dicabut meskipun token tersebut belum kedaluwarsa
+func (su *sessionUsecase) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	return su.sessionRepo.IsRevoked(ctx, sessionID)
+}
+
+// currentRole membaca ulang role pemilik sesi, sehingga perubahan role atau akun yang
+// dinonaktifkan berlaku paling lambat saat access token berikutnya dibuat
+func (su *sessionUsecase) currentRole(ctx context.Context, session *entities.Session) (string, error) {
+	if session.Role == entities.RoleDonor {
+		if _, err := su.userRepo.GetUserByID(ctx, session.SubjectID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", err_util.ErrInvalidRefreshToken
+			}
+			return "", err
+		}
+		return entities.RoleDonor, nil
+	}
+
+	admin, err := su.adminRepo.GetAdminByID(ctx, session.SubjectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err_util.ErrInvalidRefreshToken
+		}
+		return "", err
+	}
+	if !admin.IsActive() {
+		return "", err_util.ErrAdminDeactivated
+	}
+	return admin.Role, nil
+}
+
+func (su *sessionUsecase) tokenResponse(session *entities.Session, refreshToken string) (*dto.TokenResponse, error) {
+	accessToken, expiresAt, err := su.tokenUtil.GenerateToken(session.SubjectID, session.Role, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		Token:        accessToken,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+		Role:         session.Role,
+	}, nil
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return value
+}
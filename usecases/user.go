@@ -82,7 +82,7 @@ func (uu *userUsecase) GetUserAll(c echo.Context, req *dto_base.PaginationReques
 			Name:    u.Name,
 			Email:   u.Email,
 			Address: u.Address,
-			NoWA:    u.NoWA,
+			NoWA:    u.NoWA.String(),
 		})
 	}
 
@@ -0,0 +1,496 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"tugas-akhir/config"
+	"tugas-akhir/drivers/whatsapp"
+	dto_base "tugas-akhir/dto/base"
+	dto_donor "tugas-akhir/dto/donor"
+	dto "tugas-akhir/dto/whatsapp"
+	"tugas-akhir/entities"
+	"tugas-akhir/repositories"
+	err_util "tugas-akhir/utils/error"
+	"tugas-akhir/utils/phone"
+	"tugas-akhir/utils/watemplate"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Balasan donatur yang dianggap berhenti atau kembali berlangganan pesan WhatsApp
+var (
+	whatsAppStopKeywords  = []string{"STOP", "BERHENTI", "UNSUBSCRIBE"}
+	whatsAppStartKeywords = []string{"START", "MULAI", "SUBSCRIBE"}
+)
+
+var indonesianMonths = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// WhatsAppUsecase mengirim pesan WhatsApp ke donatur lewat antrean: link pembayaran,
+// ucapan terima kasih, kabar program bulanan, dan kode verifikasi nomor. Pesan selain kode
+// verifikasi tidak dikirim ke nomor yang sudah opt-out.
+type WhatsAppUsecase interface {
+	SendPaymentLink(ctx context.Context, donation *entities.Donation)
+	NotifyStatusChange(ctx context.Context, change *DonationStatusChange)
+	SendVerificationCode(ctx context.Context, to phone.Number, code string) error
+	DispatchMessages(ctx context.Context)
+	SendProgramUpdates(ctx context.Context)
+	HandleWebhook(c echo.Context, req *dto.WebhookRequest) error
+	GetMessages(c echo.Context, status string, req *dto_base.PaginationRequest) (*[]dto.MessageResponse, *dto_base.PaginationMetadata, *dto_base.Link, error)
+	GetPreference(c echo.Context, userID uuid.UUID) (*dto_donor.WhatsAppPreferenceResponse, error)
+	UpdatePreference(c echo.Context, userID uuid.UUID, req *dto_donor.WhatsAppPreferenceRequest) (*dto_donor.WhatsAppPreferenceResponse, error)
+}
+
+type whatsAppUsecase struct {
+	provider           whatsapp.Provider
+	renderer           *watemplate.Renderer
+	whatsAppRepository repositories.WhatsAppRepository
+	donationRepository repositories.DonationRepository
+	programDonation    repositories.ProgramDonationRepository
+	userRepository     repositories.UserRepository
+	transactionManager repositories.TransactionManager
+	config             config.WhatsAppConfig
+	dispatching        sync.Mutex
+	updating           sync.Mutex
+}
+
+func NewWhatsAppUsecase(provider whatsapp.Provider, renderer *watemplate.Renderer, whatsAppRepository repositories.WhatsAppRepository, donationRepository repositories.DonationRepository, programDonation repositories.ProgramDonationRepository, userRepository repositories.UserRepository, transactionManager repositories.TransactionManager, config config.WhatsAppConfig) WhatsAppUsecase {
+	return &whatsAppUsecase{
+		provider:           provider,
+		renderer:           renderer,
+		whatsAppRepository: whatsAppRepository,
+		donationRepository: donationRepository,
+		programDonation:    programDonation,
+		userRepository:     userRepository,
+		transactionManager: transactionManager,
+		config:             config,
+	}
+}
+
+// SendPaymentLink mengantrekan link pembayaran donasi. Donasi offline dan transfer manual
+// tidak punya link pembayaran.
+func (wu *whatsAppUsecase) SendPaymentLink(ctx context.Context, donation *entities.Donation) {
+	if donation.SnapURL == "" {
+		return
+	}
+	wu.enqueueDonation(ctx, watemplate.PaymentLink, donation.CurrentOrderID(), donation)
+}
+
+// NotifyStatusChange mengantrekan ucapan terima kasih saat donasi lunas
+func (wu *whatsAppUsecase) NotifyStatusChange(ctx context.Context, change *DonationStatusChange) {
+	if change == nil || !change.Changed() || change.To != entities.DonationStatusPaid {
+		return
+	}
+
+	donation, err := wu.donationRepository.GetDonationByID(ctx, change.DonationID)
+	if err != nil {
+		logrus.New().WithError(err).Warnf("Failed to load donation %s for WhatsApp", change.DonationID)
+		return
+	}
+	wu.enqueueDonation(ctx, watemplate.ThankYou, donation.ID.String(), donation)
+}
+
+// SendVerificationCode langsung mengirim kode verifikasi tanpa menunggu worker. Kode tetap
+// dikirim ke nomor yang opt-out karena diminta sendiri oleh pemilik nomor. Jika gateway
+// sedang gagal, pesan tetap di antrean dan dicoba lagi oleh worker.
+func (wu *whatsAppUsecase) SendVerificationCode(ctx context.Context, to phone.Number, code string) error {
+	body, err := wu.renderer.Render(watemplate.VerificationCode, watemplate.VerificationData{
+		Code:     code,
+		ValidFor: fmt.Sprintf("%.0f menit", phoneVerificationTTL.Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+
+	message := wu.newMessage(to, watemplate.VerificationCode, uuid.NewString(), body)
+	if _, err := wu.whatsAppRepository.Enqueue(ctx, message); err != nil {
+		return err
+	}
+
+	if err := wu.deliver(ctx, message.ID); err != nil {
+		logrus.New().WithError(err).Warnf("Failed to send WhatsApp verification code to %s", to.Masked())
+	}
+	return nil
+}
+
+// DispatchMessages dijalankan scheduler: mengirim pesan yang mengantre ke gateway
+func (wu *whatsAppUsecase) DispatchMessages(ctx context.Context) {
+	if !wu.dispatching.TryLock() {
+		return
+	}
+	defer wu.dispatching.Unlock()
+
+	log := logrus.New()
+
+	messages, err := wu.whatsAppRepository.GetQueuedMessages(ctx, time.Now(), wu.config.BatchSize)
+	if err != nil {
+		log.WithError(err).Error("Failed to get queued WhatsApp messages")
+		return
+	}
+
+	for i := range messages {
+		if err := wu.deliver(ctx, messages[i].ID); err != nil {
+			log.WithError(err).Warnf("Failed to send WhatsApp message %s", messages[i].ID)
+		}
+	}
+}
+
+// deliver mengunci pesan lalu mengirimnya ke gateway, sehingga pesan yang sama tidak
+// terkirim dua kali oleh worker dan permintaan kode verifikasi yang berjalan bersamaan.
+// Pengiriman yang gagal dijadwalkan ulang sampai WHATSAPP_MAX_ATTEMPTS.
+func (wu *whatsAppUsecase) deliver(ctx context.Context, messageID uuid.UUID) error {
+	var sendErr error
+	err := wu.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		message, err := wu.whatsAppRepository.FindByIdForUpdate(ctx, messageID)
+		if err != nil {
+			return err
+		}
+		if message.Status != entities.WhatsAppStatusQueued {
+			return nil
+		}
+
+		now := time.Now()
+		message.UpdatedAt = now
+		if message.Template != watemplate.VerificationCode {
+			optedOut, err := wu.whatsAppRepository.IsOptedOut(ctx, message.Phone)
+			if err != nil {
+				return err
+			}
+			if optedOut {
+				message.Status = entities.WhatsAppStatusFailed
+				message.Error = "recipient opted out"
+				return wu.whatsAppRepository.Update(ctx, message)
+			}
+		}
+
+		message.Attempts++
+		result, err := wu.provider.Send(ctx, message.Phone, message.Body)
+		if err != nil {
+			sendErr = err
+			message.Error = truncateError(err.Error())
+			if message.Attempts >= wu.config.MaxAttempts {
+				message.Status = entities.WhatsAppStatusFailed
+			} else {
+				message.NextAttemptAt = now.Add(time.Duration(message.Attempts) * time.Minute)
+			}
+			return wu.whatsAppRepository.Update(ctx, message)
+		}
+
+		message.Error = ""
+		message.ProviderMessageID = result.MessageID
+		if !message.AdvanceStatus(result.Status, now) {
+			message.AdvanceStatus(entities.WhatsAppStatusSent, now)
+		}
+		return wu.whatsAppRepository.Update(ctx, message)
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// SendProgramUpdates dijalankan scheduler: mulai tanggal WHATSAPP_UPDATE_DAY setiap bulan,
+// mengantrekan satu kabar perkembangan program untuk setiap donatur. Nomor yang sudah
+// mendapat kabar bulan ini dilewati, jadi worker aman dijalankan berulang kali.
+func (wu *whatsAppUsecase) SendProgramUpdates(ctx context.Context) {
+	if !wu.updating.TryLock() {
+		return
+	}
+	defer wu.updating.Unlock()
+
+	now := time.Now()
+	if now.Day() < wu.config.UpdateDay {
+		return
+	}
+
+	log := logrus.New()
+	reference := now.Format("2006-01")
+
+	recipients, err := wu.whatsAppRepository.GetUpdateRecipients(ctx, watemplate.ProgramUpdate, reference)
+	if err != nil {
+		log.WithError(err).Error("Failed to get WhatsApp program update recipients")
+		return
+	}
+
+	programs := map[uuid.UUID]*watemplate.ProgramProgress{}
+	queued := 0
+	for start := 0; start < len(recipients); {
+		end := start
+		data := watemplate.ProgramUpdateData{
+			Name:  recipients[start].Name,
+			Month: fmt.Sprintf("%s %d", indonesianMonths[now.Month()-1], now.Year()),
+		}
+		for ; end < len(recipients) && recipients[end].Phone == recipients[start].Phone; end++ {
+			progress, ok := programs[recipients[end].ProgramDonationID]
+			if !ok {
+				progress = wu.programProgress(ctx, recipients[end].ProgramDonationID)
+				programs[recipients[end].ProgramDonationID] = progress
+			}
+			if progress != nil {
+				data.Programs = append(data.Programs, *progress)
+			}
+		}
+
+		to := recipients[start].Phone
+		start = end
+		if len(data.Programs) == 0 {
+			continue
+		}
+
+		body, err := wu.renderer.Render(watemplate.ProgramUpdate, data)
+		if err != nil {
+			log.WithError(err).Error("Failed to render WhatsApp program update")
+			return
+		}
+		created, err := wu.whatsAppRepository.Enqueue(ctx, wu.newMessage(to, watemplate.ProgramUpdate, reference, body))
+		if err != nil {
+			log.WithError(err).Warnf("Failed to queue WhatsApp program update to %s", to.Masked())
+			continue
+		}
+		if created {
+			queued++
+		}
+	}
+
+	if queued > 0 {
+		log.Infof("Queued %d WhatsApp program updates for %s", queued, reference)
+	}
+}
+
+func (wu *whatsAppUsecase) programProgress(ctx context.Context, programID uuid.UUID) *watemplate.ProgramProgress {
+	program, err := wu.programDonation.GetProgramDonationByID(ctx, programID)
+	if err != nil {
+		return nil
+	}
+
+	percent := 0
+	if program.GoalAmount > 0 {
+		percent = int(math.Min(100, float64(program.CurrentAmount)*100/float64(program.GoalAmount)))
+	}
+	return &watemplate.ProgramProgress{
+		Title:     program.Title,
+		Collected: formatRupiah(program.CurrentAmount),
+		Goal:      formatRupiah(program.GoalAmount),
+		Percent:   percent,
+	}
+}
+
+// HandleWebhook menerapkan laporan status pengiriman dari gateway dan memproses balasan
+// STOP atau MULAI dari donatur
+func (wu *whatsAppUsecase) HandleWebhook(c echo.Context, req *dto.WebhookRequest) error {
+	ctx := c.Request().Context()
+
+	if req.Type == whatsapp.CallbackMessage {
+		number, err := phone.Parse(req.From)
+		if err != nil || number.IsZero() {
+			return phone.ErrInvalidNumber
+		}
+
+		keyword := strings.ToUpper(strings.TrimSpace(req.Message))
+		switch {
+		case slices.Contains(whatsAppStopKeywords, keyword):
+			return wu.whatsAppRepository.OptOut(ctx, number, entities.WhatsAppOptOutReply)
+		case slices.Contains(whatsAppStartKeywords, keyword):
+			return wu.whatsAppRepository.OptIn(ctx, number)
+		}
+		return nil
+	}
+
+	return wu.transactionManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		message, err := wu.whatsAppRepository.FindByProviderIDForUpdate(ctx, req.MessageID)
+		if err != nil {
+			// Laporan untuk pesan yang tidak dikenal diabaikan agar gateway tidak terus mengulang
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				logrus.New().Warnf("WhatsApp status for unknown message %s", req.MessageID)
+				return nil
+			}
+			return err
+		}
+
+		if !message.AdvanceStatus(req.Status, time.Now()) {
+			return nil
+		}
+		if req.Status == entities.WhatsAppStatusFailed {
+			message.Error = truncateError(req.Error)
+		}
+		message.UpdatedAt = time.Now()
+		return wu.whatsAppRepository.Update(ctx, message)
+	})
+}
+
+func (wu *whatsAppUsecase) GetMessages(c echo.Context, status string, req *dto_base.PaginationRequest) (*[]dto.MessageResponse, *dto_base.PaginationMetadata, *dto_base.Link, error) {
+	ctx := c.Request().Context()
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	messages, totalData, err := wu.whatsAppRepository.GetMessages(ctx, status, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	totalPage := int(math.Ceil(float64(totalData) / float64(req.Limit)))
+	if totalData > 0 && req.Page > totalPage {
+		return nil, nil, nil, err_util.ErrPageNotFound
+	}
+
+	responses := []dto.MessageResponse{}
+	for i := range messages {
+		responses = append(responses, toWhatsAppMessageResponse(&messages[i]))
+	}
+
+	filter := ""
+	if status != "" {
+		filter = "status=" + status
+	}
+	metadata, link := paginationResult(c, req, filter, totalData, totalPage)
+	return &responses, metadata, link, nil
+}
+
+func (wu *whatsAppUsecase) GetPreference(c echo.Context, userID uuid.UUID) (*dto_donor.WhatsAppPreferenceResponse, error) {
+	ctx := c.Request().Context()
+
+	user, err := wu.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.NoWA.IsZero() {
+		return nil, err_util.ErrWhatsAppNumberRequired
+	}
+
+	optedOut, err := wu.whatsAppRepository.IsOptedOut(ctx, user.NoWA)
+	if err != nil {
+		return nil, err
+	}
+	return &dto_donor.WhatsAppPreferenceResponse{NoWA: user.NoWA.String(), OptIn: !optedOut}, nil
+}
+
+// UpdatePreference mengatur opt-out nomor WhatsApp akun. Hanya nomor yang sudah
+// diverifikasi yang bisa diubah, agar akun lain tidak bisa mengatur nomor milik orang lain.
+func (wu *whatsAppUsecase) UpdatePreference(c echo.Context, userID uuid.UUID, req *dto_donor.WhatsAppPreferenceRequest) (*dto_donor.WhatsAppPreferenceResponse, error) {
+	ctx := c.Request().Context()
+
+	user, err := wu.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.NoWA.IsZero() || user.PhoneVerifiedAt == nil {
+		return nil, err_util.ErrWhatsAppNumberRequired
+	}
+
+	if *req.OptIn {
+		err = wu.whatsAppRepository.OptIn(ctx, user.NoWA)
+	} else {
+		err = wu.whatsAppRepository.OptOut(ctx, user.NoWA, entities.WhatsAppOptOutDonor)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dto_donor.WhatsAppPreferenceResponse{NoWA: user.NoWA.String(), OptIn: *req.OptIn}, nil
+}
+
+// enqueueDonation mengantrekan pesan donasi ke nomor donatur. Donasi tanpa nomor dan nomor
+// yang opt-out dilewati. Kegagalan hanya dicatat agar alur donasi tidak terganggu.
+func (wu *whatsAppUsecase) enqueueDonation(ctx context.Context, template string, reference string, donation *entities.Donation) {
+	if donation.NoWA.IsZero() {
+		return
+	}
+	log := logrus.New()
+
+	optedOut, err := wu.whatsAppRepository.IsOptedOut(ctx, donation.NoWA)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to check WhatsApp opt-out for donation %s", donation.ID)
+		return
+	}
+	if optedOut {
+		return
+	}
+
+	programTitle := ""
+	if program, err := wu.programDonation.GetProgramDonationByID(ctx, donation.ProgramDonationID); err == nil {
+		programTitle = program.Title
+	}
+	date := donation.CreatedAt
+	if donation.PaidAt != nil {
+		date = *donation.PaidAt
+	}
+
+	body, err := wu.renderer.Render(template, watemplate.DonationData{
+		Name:         donation.Name,
+		Amount:       formatRupiah(donation.Amount),
+		ProgramTitle: programTitle,
+		OrderID:      donation.CurrentOrderID(),
+		PaymentURL:   donation.SnapURL,
+		Date:         date.Format(mailDateLayout),
+	})
+	if err != nil {
+		log.WithError(err).Warnf("Failed to render %s WhatsApp for donation %s", template, donation.ID)
+		return
+	}
+
+	message := wu.newMessage(donation.NoWA, template, reference, body)
+	message.DonationID = &donation.ID
+	if _, err := wu.whatsAppRepository.Enqueue(ctx, message); err != nil {
+		log.WithError(err).Warnf("Failed to queue %s WhatsApp for donation %s", template, donation.ID)
+	}
+}
+
+func (wu *whatsAppUsecase) newMessage(to phone.Number, template string, reference string, body string) *entities.WhatsAppMessage {
+	now := time.Now()
+	return &entities.WhatsAppMessage{
+		ID:            uuid.New(),
+		Phone:         to,
+		Template:      template,
+		Reference:     reference,
+		Body:          body,
+		Status:        entities.WhatsAppStatusQueued,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func toWhatsAppMessageResponse(message *entities.WhatsAppMessage) dto.MessageResponse {
+	response := dto.MessageResponse{
+		ID:        message.ID.String(),
+		Phone:     message.Phone.String(),
+		Template:  message.Template,
+		Reference: message.Reference,
+		Body:      message.Body,
+		Status:    message.Status,
+		Attempts:  message.Attempts,
+		Error:     message.Error,
+		CreatedAt: message.CreatedAt.Format(time.RFC3339),
+	}
+	if message.DonationID != nil {
+		response.DonationID = message.DonationID.String()
+	}
+	if message.SentAt != nil {
+		response.SentAt = message.SentAt.Format(time.RFC3339)
+	}
+	if message.DeliveredAt != nil {
+		response.DeliveredAt = message.DeliveredAt.Format(time.RFC3339)
+	}
+	if message.ReadAt != nil {
+		response.ReadAt = message.ReadAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// truncateError memotong pesan error agar muat di kolom error tanpa memotong karakter
+func truncateError(message string) string {
+	runes := []rune(message)
+	if len(runes) > 255 {
+		return string(runes[:255])
+	}
+	return message
+}
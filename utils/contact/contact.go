@@ -0,0 +1,62 @@
+package contact
+
+import (
+	"strings"
+	"tugas-akhir/utils/phone"
+)
+
+// EmailKey menormalkan email untuk mengenali donatur yang sama: huruf kecil, tanpa
+// spasi, tanpa sub-alamat "+tag", dan titik pada Gmail diabaikan
+func EmailKey(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// PhoneKey mengubah nomor WhatsApp menjadi deretan digit E.164 tanpa tanda +,
+// misalnya 6281234567890
+func PhoneKey(number phone.Number) string {
+	return number.Digits()
+}
+
+// NameKey menormalkan nama untuk perbandingan: huruf kecil dengan spasi tunggal
+func NameKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// Distance menghitung jarak Levenshtein antara dua string
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -7,7 +7,7 @@ import (
 
 var (
 	// Password
-	ErrFailedHashingPassword = errors.New(messages.FAILED_HASHING_PASSWORD )
+	ErrFailedHashingPassword = errors.New(messages.FAILED_HASHING_PASSWORD)
 	ErrPasswordMismatch      = errors.New(messages.PASSWORD_MISMATCH)
 
 	// // External Service
@@ -25,5 +25,53 @@ var (
 	// pages
 	ErrPageNotFound = errors.New(messages.PAGE_NOT_FOUND)
 
+	// Payment
+	ErrDuplicateNotification   = errors.New(messages.DUPLICATE_NOTIFICATION)
+	ErrIllegalStatusTransition = errors.New(messages.ILLEGAL_STATUS_TRANSITION)
+	ErrGrossAmountMismatch     = errors.New(messages.GROSS_AMOUNT_MISMATCH)
+	ErrInvalidAllocationAmount = errors.New(messages.INVALID_ALLOCATION_AMOUNT)
+	ErrReconciliationRunning   = errors.New(messages.RECONCILIATION_ALREADY_RUNNING)
+	ErrDonationNotRefundable   = errors.New(messages.DONATION_NOT_REFUNDABLE)
+	ErrInvalidRefundAmount     = errors.New(messages.INVALID_REFUND_AMOUNT)
+	ErrDonationNotResumable    = errors.New(messages.DONATION_NOT_RESUMABLE)
+	ErrTransferProofReviewed   = errors.New(messages.TRANSFER_PROOF_ALREADY_REVIEWED)
+	ErrInvalidManageToken      = errors.New(messages.INVALID_MANAGE_TOKEN)
+	ErrSubscriptionState       = errors.New(messages.SUBSCRIPTION_STATE_CONFLICT)
+	ErrReceiptNotAvailable     = errors.New(messages.RECEIPT_NOT_AVAILABLE)
+	ErrInvalidReceipt          = errors.New(messages.INVALID_RECEIPT)
+
+	// Donor account
+	ErrEmailRegistered             = errors.New(messages.EMAIL_ALREADY_REGISTERED)
+	ErrEmailNotVerified            = errors.New(messages.EMAIL_NOT_VERIFIED)
+	ErrInvalidVerification         = errors.New(messages.INVALID_VERIFICATION)
+	ErrTooManyVerificationAttempts = errors.New(messages.TOO_MANY_VERIFICATION_ATTEMPTS)
+
+	// Donor directory
+	ErrInvalidDonorMerge      = errors.New(messages.INVALID_DONOR_MERGE)
+	ErrDonorDuplicateReviewed = errors.New(messages.DONOR_DUPLICATE_REVIEWED)
+
+	// Donation notifications
+	ErrInvalidCursor = errors.New(messages.INVALID_CURSOR)
+
+	// WhatsApp
+	ErrWhatsAppNumberRequired = errors.New(messages.WHATSAPP_NUMBER_REQUIRED)
+
+	// Admin roles
+	ErrUnknownRole         = errors.New(messages.UNKNOWN_ROLE)
+	ErrUnknownPermission   = errors.New(messages.UNKNOWN_PERMISSION)
+	ErrRoleLocked          = errors.New(messages.ROLE_LOCKED)
+	ErrCannotChangeOwnRole = errors.New(messages.CANNOT_CHANGE_OWN_ROLE)
+	ErrPermissionReserved  = errors.New(messages.PERMISSION_RESERVED)
+
+	// Admin accounts
+	ErrInvalidInvitation      = errors.New(messages.INVALID_INVITATION)
+	ErrAdminDeactivated       = errors.New(messages.ADMIN_DEACTIVATED)
+	ErrCannotManageSelf       = errors.New(messages.CANNOT_MANAGE_SELF)
+	ErrSuperAdminRequired     = errors.New(messages.SUPER_ADMIN_REQUIRED)
+	ErrInvalidCurrentPassword = errors.New(messages.INVALID_CURRENT_PASSWORD)
+
+	// Sessions
+	ErrInvalidRefreshToken = errors.New(messages.INVALID_REFRESH_TOKEN)
+
 	// ErrNotFound = errors.New(message.NOT_FOUND)
 )
@@ -56,3 +56,14 @@ func HandlePaginationResponse(c echo.Context, message string, data any, paginati
 		Link:       link,
 	})
 }
+
+func HandleCursorPaginationResponse(c echo.Context, message string, data any, pagination *dto.CursorMetadata) error {
+	return c.JSON(http.StatusOK, &dto.CursorPaginationResponse{
+		BaseResponse: dto.BaseResponse{
+			Status:  status.STATUS_SUCCESS,
+			Message: message,
+			Data:    data,
+		},
+		Pagination: pagination,
+	})
+}
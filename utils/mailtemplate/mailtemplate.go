@@ -0,0 +1,137 @@
+package mailtemplate
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Nama template email yang tersedia di folder templates
+const (
+	DonationCreated           = "donation_created"
+	PaymentReceived           = "payment_received"
+	PaymentExpired            = "payment_expired"
+	LargeDonation             = "large_donation"
+	DuplicatePayment          = "duplicate_payment"
+	SubscriptionPaymentLink   = "subscription_payment_link"
+	SubscriptionPaymentFailed = "subscription_payment_failed"
+	EmailVerification         = "email_verification"
+	AdminInvitation           = "admin_invitation"
+)
+
+//go:embed templates/*.tmpl
+var files embed.FS
+
+// Email adalah hasil render satu template: subjek, isi teks biasa, dan isi HTML
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// DonationData dipakai template donation_created, payment_received, payment_expired,
+// dan large_donation. Amount dan Date sudah diformat untuk ditampilkan.
+type DonationData struct {
+	Name           string
+	Email          string
+	Amount         string
+	ProgramTitle   string
+	OrderID        string
+	PaymentURL     string
+	PaymentChannel string
+	Message        string
+	Date           string
+
+	// ReceiptNumber diisi pada payment_received jika kuitansi PDF ikut dilampirkan
+	ReceiptNumber string
+}
+
+// DuplicatePaymentData dipakai template duplicate_payment. OrderID adalah order yang
+// tercatat lunas, DuplicateOrderID adalah order yang dibayar kedua kalinya.
+type DuplicatePaymentData struct {
+	DonationData
+	DuplicateOrderID string
+}
+
+type SubscriptionData struct {
+	Name         string
+	Amount       string
+	ProgramTitle string
+	PaymentURL   string
+	Attempt      int
+	Suspended    bool
+}
+
+type VerificationData struct {
+	Name     string
+	Token    string
+	ValidFor string
+}
+
+// AdminInvitationData dipakai template admin_invitation. URL kosong jika halaman
+// penerimaan undangan belum dikonfigurasi, sehingga email hanya berisi token.
+type AdminInvitationData struct {
+	Name      string
+	Role      string
+	InvitedBy string
+	Token     string
+	URL       string
+	ValidFor  string
+}
+
+type template struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Renderer menyusun email dari template. Setiap template mendefinisikan blok subject,
+// text, dan html. Blok html dirender dengan html/template agar data donatur di-escape.
+type Renderer struct {
+	templates map[string]template
+}
+
+// NewRenderer mem-parse semua template. Nama pengirim tersedia di template lewat fungsi
+// sender. Template disematkan saat build, sehingga template yang rusak langsung panic.
+func NewRenderer(sender string) *Renderer {
+	funcs := map[string]any{
+		"sender": func() string { return sender },
+	}
+
+	names := []string{DonationCreated, PaymentReceived, PaymentExpired, LargeDonation, DuplicatePayment, SubscriptionPaymentLink, SubscriptionPaymentFailed, EmailVerification, AdminInvitation}
+	renderer := &Renderer{templates: make(map[string]template, len(names))}
+	for _, name := range names {
+		patterns := []string{"templates/layout.tmpl", "templates/" + name + ".tmpl"}
+		renderer.templates[name] = template{
+			text: texttemplate.Must(texttemplate.New(name).Funcs(funcs).ParseFS(files, patterns...)),
+			html: htmltemplate.Must(htmltemplate.New(name).Funcs(funcs).ParseFS(files, patterns...)),
+		}
+	}
+	return renderer
+}
+
+func (r *Renderer) Render(name string, data any) (*Email, error) {
+	tmpl, ok := r.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("mail template %q not found", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return nil, err
+	}
+
+	return &Email{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    strings.TrimSpace(html.String()),
+	}, nil
+}
@@ -0,0 +1,34 @@
+package midtrans
+
+import "tugas-akhir/entities"
+
+// MapTransactionStatus memetakan transaction_status dan fraud_status Midtrans ke status donasi.
+// Nilai kedua bernilai false jika status dari Midtrans tidak dikenali.
+// Lihat https://docs.midtrans.com/docs/https-notification-webhooks#status-definition
+func MapTransactionStatus(transactionStatus string, fraudStatus string) (entities.DonationStatus, bool) {
+	switch transactionStatus {
+	case "capture":
+		// Pembayaran kartu hanya dianggap lunas jika lolos pemeriksaan fraud. Fraud status
+		// lain selain deny (challenge atau kosong) menunggu keputusan admin.
+		switch fraudStatus {
+		case "accept":
+			return entities.DonationStatusPaid, true
+		case "deny":
+			return entities.DonationStatusFailed, true
+		}
+		return entities.DonationStatusChallenged, true
+	case "settlement":
+		return entities.DonationStatusPaid, true
+	case "pending":
+		return entities.DonationStatusPending, true
+	case "deny", "cancel", "failure":
+		return entities.DonationStatusFailed, true
+	case "expire":
+		return entities.DonationStatusExpired, true
+	case "refund":
+		return entities.DonationStatusRefunded, true
+	case "partial_refund":
+		return entities.DonationStatusPartiallyRefunded, true
+	}
+	return entities.DonationStatusPending, false
+}
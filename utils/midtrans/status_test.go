@@ -0,0 +1,41 @@
+package midtrans
+
+import (
+	"testing"
+	"tugas-akhir/entities"
+)
+
+func TestMapTransactionStatus(t *testing.T) {
+	tests := []struct {
+		name              string
+		transactionStatus string
+		fraudStatus       string
+		want              entities.DonationStatus
+		wantKnown         bool
+	}{
+		{"capture accepted", "capture", "accept", entities.DonationStatusPaid, true},
+		{"capture challenged", "capture", "challenge", entities.DonationStatusChallenged, true},
+		{"capture denied by fraud check", "capture", "deny", entities.DonationStatusFailed, true},
+		{"capture without fraud status", "capture", "", entities.DonationStatusChallenged, true},
+		{"capture with unknown fraud status", "capture", "review", entities.DonationStatusChallenged, true},
+		{"settlement", "settlement", "", entities.DonationStatusPaid, true},
+		{"settlement ignores fraud status", "settlement", "accept", entities.DonationStatusPaid, true},
+		{"pending", "pending", "", entities.DonationStatusPending, true},
+		{"deny", "deny", "", entities.DonationStatusFailed, true},
+		{"cancel", "cancel", "", entities.DonationStatusFailed, true},
+		{"failure", "failure", "", entities.DonationStatusFailed, true},
+		{"expire", "expire", "", entities.DonationStatusExpired, true},
+		{"refund", "refund", "", entities.DonationStatusRefunded, true},
+		{"partial refund", "partial_refund", "", entities.DonationStatusPartiallyRefunded, true},
+		{"unknown status", "authorize", "", entities.DonationStatusPending, false},
+		{"empty status", "", "", entities.DonationStatusPending, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, known := MapTransactionStatus(tt.transactionStatus, tt.fraudStatus)
+			if got != tt.want || known != tt.wantKnown {
+				t.Errorf("MapTransactionStatus(%q, %q) = (%s, %t), want (%s, %t)", tt.transactionStatus, tt.fraudStatus, got, known, tt.want, tt.wantKnown)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package moderation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Pola tautan: alamat dengan skema, diawali www, atau domain dengan akhiran umum
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|\b[a-z0-9-]+\.(com|net|org|id|co|io|xyz|info|biz|me|ly|link|site|online|top|vip)\b)`)
+
+// Filter memeriksa pesan donatur terhadap daftar kata terlarang dan tautan
+type Filter struct {
+	words      []string
+	blockLinks bool
+}
+
+func NewFilter(words []string, blockLinks bool) *Filter {
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = normalize(word); word != "" {
+			normalized = append(normalized, word)
+		}
+	}
+	return &Filter{
+		words:      normalized,
+		blockLinks: blockLinks,
+	}
+}
+
+// Check mengembalikan alasan pesan ditandai, atau string kosong jika pesan aman.
+// Kata terlarang dicocokkan per kata utuh sehingga "babinsa" tidak cocok dengan "babi".
+func (f *Filter) Check(message string) string {
+	if f.blockLinks && linkPattern.MatchString(message) {
+		return "contains link"
+	}
+
+	text := " " + normalize(message) + " "
+	for _, word := range f.words {
+		if strings.Contains(text, " "+word+" ") {
+			return "blocked word: " + word
+		}
+	}
+	return ""
+}
+
+// normalize mengubah teks menjadi huruf kecil dengan kata dipisah satu spasi, tanda baca
+// dianggap pemisah kata
+func normalize(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,142 @@
+package phone
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Kode negara Indonesia, dipakai untuk nomor lokal yang diawali 0 atau 8
+const indonesiaCode = "62"
+
+var ErrInvalidNumber = errors.New("invalid phone number")
+
+// Number adalah nomor telepon dalam format E.164, misalnya +6281234567890.
+// Nilai kosong berarti nomor tidak diisi.
+type Number string
+
+// Parse menerima nomor internasional (+<kode negara>...) maupun format lokal Indonesia
+// seperti 0812-3456-7890, 812 3456 7890, atau 6281234567890, lalu mengubahnya ke E.164
+func Parse(input string) (Number, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", nil
+	}
+
+	international := strings.HasPrefix(input, "+")
+	var digits strings.Builder
+	for i, r := range input {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0, r == ' ', r == '-', r == '.', r == '(', r == ')':
+		default:
+			return "", ErrInvalidNumber
+		}
+	}
+
+	number := digits.String()
+	if !international {
+		switch {
+		case strings.HasPrefix(number, "0"):
+			number = indonesiaCode + number[1:]
+		case strings.HasPrefix(number, "8"):
+			number = indonesiaCode + number
+		case !strings.HasPrefix(number, indonesiaCode):
+			return "", ErrInvalidNumber
+		}
+	}
+
+	// E.164 paling banyak 15 digit. Nomor Indonesia terdiri dari 8 sampai 12 digit
+	// setelah kode negara.
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidNumber
+	}
+	if strings.HasPrefix(number, indonesiaCode) {
+		national := len(number) - len(indonesiaCode)
+		if national < 8 || national > 12 || number[len(indonesiaCode)] == '0' {
+			return "", ErrInvalidNumber
+		}
+	}
+	return Number("+" + number), nil
+}
+
+// FromLegacy mengubah nomor lama yang tersimpan sebagai angka. Angka 0 di depan hilang
+// saat disimpan, sehingga nomor yang tidak diawali 62 dianggap nomor lokal Indonesia.
+func FromLegacy(number int) Number {
+	if number <= 0 {
+		return ""
+	}
+
+	digits := strconv.Itoa(number)
+	if !strings.HasPrefix(digits, indonesiaCode) {
+		digits = indonesiaCode + digits
+	}
+	return Number("+" + digits)
+}
+
+func (n Number) String() string {
+	return string(n)
+}
+
+func (n Number) IsZero() bool {
+	return n == ""
+}
+
+// Digits mengembalikan nomor tanpa tanda +, format yang dipakai API WhatsApp dan wa.me
+func (n Number) Digits() string {
+	return strings.TrimPrefix(string(n), "+")
+}
+
+// Masked menyamarkan bagian tengah nomor untuk ditampilkan di halaman publik,
+// misalnya +62812****7890
+func (n Number) Masked() string {
+	if n == "" {
+		return ""
+	}
+
+	digits := n.Digits()
+	visibleStart := 3
+	if strings.HasPrefix(digits, indonesiaCode) {
+		visibleStart = len(indonesiaCode) + 3
+	}
+	if len(digits) <= visibleStart+4 {
+		return "+" + digits[:len(digits)-2] + "**"
+	}
+	return "+" + digits[:visibleStart] + strings.Repeat("*", len(digits)-visibleStart-4) + digits[len(digits)-4:]
+}
+
+// UnmarshalJSON menerima string maupun angka agar klien lama yang masih mengirim
+// nomor sebagai angka tetap didukung
+func (n *Number) UnmarshalJSON(data []byte) error {
+	var input string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &input); err != nil {
+			return err
+		}
+	} else if string(data) != "null" {
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return ErrInvalidNumber
+		}
+		input = number.String()
+	}
+
+	parsed, err := Parse(input)
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
+
+// UnmarshalParam dipakai binder echo untuk form dan query parameter
+func (n *Number) UnmarshalParam(param string) error {
+	parsed, err := Parse(param)
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
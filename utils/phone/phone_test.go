@@ -0,0 +1,86 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Number
+		wantErr error
+	}{
+		{"local with leading zero", "081234567890", "+6281234567890", nil},
+		{"local with separators", "0812-3456-7890", "+6281234567890", nil},
+		{"local without leading zero", "812 3456 7890", "+6281234567890", nil},
+		{"country code without plus", "6281234567890", "+6281234567890", nil},
+		{"international", "+6281234567890", "+6281234567890", nil},
+		{"international with separators", "+62 (812) 3456.7890", "+6281234567890", nil},
+		{"international outside Indonesia", "+14155552671", "+14155552671", nil},
+		{"surrounding spaces", "  081234567890  ", "+6281234567890", nil},
+		{"empty", "", "", nil},
+		{"only spaces", "   ", "", nil},
+		{"letters", "0812abcd7890", "", ErrInvalidNumber},
+		{"plus in the middle", "0812+34567890", "", ErrInvalidNumber},
+		{"local without Indonesian prefix", "12345678901", "", ErrInvalidNumber},
+		{"local too short", "0812345", "", ErrInvalidNumber},
+		{"local too long", "08123456789012", "", ErrInvalidNumber},
+		{"country code followed by zero", "+62081234567890", "", ErrInvalidNumber},
+		{"international too short", "+1234567", "", ErrInvalidNumber},
+		{"international too long", "+1234567890123456", "", ErrInvalidNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) returned error %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromLegacy(t *testing.T) {
+	tests := []struct {
+		name   string
+		number int
+		want   Number
+	}{
+		{"local number without leading zero", 81234567890, "+6281234567890"},
+		{"number with country code", 6281234567890, "+6281234567890"},
+		{"zero", 0, ""},
+		{"negative", -81234567890, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromLegacy(tt.number); got != tt.want {
+				t.Errorf("FromLegacy(%d) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMasked(t *testing.T) {
+	tests := []struct {
+		name   string
+		number Number
+		want   string
+	}{
+		{"indonesian mobile", "+6281234567890", "+62812****7890"},
+		{"short indonesian number", "+62812345678", "+62812**5678"},
+		{"international", "+14155552671", "+141****2671"},
+		{"too short to keep both ends", "+1234567", "+12345**"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.number.Masked(); got != tt.want {
+				t.Errorf("%q.Masked() = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
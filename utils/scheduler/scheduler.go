@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Every menjalankan job di goroutine terpisah setiap interval sampai ctx dibatalkan.
+// Panic di dalam job dicatat dan tidak menghentikan jadwal berikutnya.
+func Every(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context)) {
+	log := logrus.New()
+	if interval <= 0 {
+		log.Warnf("Scheduler %s disabled: interval %s", name, interval)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Infof("Scheduler %s started, runs every %s", name, interval)
+		for {
+			select {
+			case <-ctx.Done():
+				log.Infof("Scheduler %s stopped", name)
+				return
+			case <-ticker.C:
+				run(ctx, name, job)
+			}
+		}
+	}()
+}
+
+func run(ctx context.Context, name string, job func(ctx context.Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.New().Errorf("Scheduler %s panicked: %v", name, r)
+		}
+	}()
+	job(ctx)
+}
@@ -17,6 +17,8 @@ import (
 type JWTClaim struct {
 	ID   uuid.UUID `json:"id"`
 	Role string    `json:"role"` // Menambahkan field role
+	// Sesi login pemilik token, dipakai untuk menolak token dari sesi yang sudah dicabut
+	SessionID uuid.UUID `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -27,9 +29,28 @@ func GetJWTConfig() echojwt.Config {
 		},
 		ErrorHandler: jwtErrorHandler,
 		SigningKey:   []byte(os.Getenv("JWT_KEY")),
+		ContextKey:   "admin", // dibaca oleh TokenUtil.GetClaims
 	}
 }
 
+// GetOptionalJWTConfig dipakai pada route publik yang juga bisa diakses pengguna login.
+// Request tanpa header Authorization diteruskan tanpa claims.
+func GetOptionalJWTConfig() echojwt.Config {
+	config := GetJWTConfig()
+	config.Skipper = func(c echo.Context) bool {
+		return c.Request().Header.Get(echo.HeaderAuthorization) == ""
+	}
+	return config
+}
+
+// GetStreamJWTConfig dipakai pada stream Server-Sent Events. EventSource di browser tidak
+// bisa mengirim header, sehingga token juga dibaca dari query ?token=.
+func GetStreamJWTConfig() echojwt.Config {
+	config := GetJWTConfig()
+	config.TokenLookup = "header:Authorization:Bearer ,query:token"
+	return config
+}
+
 func jwtErrorHandler(c echo.Context, err error) error {
 	code := http.StatusUnauthorized
 
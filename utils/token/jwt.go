@@ -9,8 +9,11 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// AccessTokenTTL adalah masa berlaku access token. Sesi diperpanjang dengan refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
 type TokenUtil interface {
-	GenerateToken(id uuid.UUID, role string) (string, error) // Menambahkan parameter role
+	GenerateToken(id uuid.UUID, role string, sessionID uuid.UUID) (string, time.Time, error)
 	GetClaims(c echo.Context) *JWTClaim
 }
 
@@ -20,20 +23,36 @@ func NewTokenUtil() *tokenUtil {
 	return &tokenUtil{}
 }
 
-func (*tokenUtil) GenerateToken(id uuid.UUID, role string) (string, error) { // Menambahkan parameter role
+// GenerateToken membuat access token untuk sesi login. Token mengembalikan waktu
+// kedaluwarsanya agar klien tahu kapan harus memakai refresh token.
+func (*tokenUtil) GenerateToken(id uuid.UUID, role string, sessionID uuid.UUID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL)
 	claims := JWTClaim{
-		ID:   id,
-		Role: role, // Menambahkan role ke dalam claims
+		ID:        id,
+		Role:      role, // Menambahkan role ke dalam claims
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(30 * 24 * time.Hour)), // Token kadaluarsa 30 hari
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
 	}
 	unsignedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := unsignedToken.SignedString([]byte(os.Getenv("JWT_KEY")))
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
-	return signedToken, nil
+	return signedToken, expiresAt, nil
+}
+
+// OptionalClaims mengembalikan claims jika request membawa token, atau nil jika tidak
+func OptionalClaims(c echo.Context) *JWTClaim {
+	user, ok := c.Get("admin").(*jwt.Token)
+	if !ok {
+		return nil
+	}
+	claims, _ := user.Claims.(*JWTClaim)
+	return claims
 }
 
 func (*tokenUtil) GetClaims(c echo.Context) *JWTClaim {
@@ -0,0 +1,86 @@
+package watemplate
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Nama template pesan WhatsApp yang tersedia di folder templates
+const (
+	PaymentLink      = "payment_link"
+	ThankYou         = "thank_you"
+	ProgramUpdate    = "program_update"
+	VerificationCode = "verification_code"
+)
+
+//go:embed templates/*.tmpl
+var files embed.FS
+
+// DonationData dipakai template payment_link dan thank_you. Amount dan Date sudah
+// diformat untuk ditampilkan.
+type DonationData struct {
+	Name         string
+	Amount       string
+	ProgramTitle string
+	OrderID      string
+	PaymentURL   string
+	Date         string
+}
+
+type ProgramProgress struct {
+	Title     string
+	Collected string
+	Goal      string
+	Percent   int
+}
+
+// ProgramUpdateData dipakai template program_update untuk kabar bulanan program yang
+// pernah didukung donatur
+type ProgramUpdateData struct {
+	Name     string
+	Month    string
+	Programs []ProgramProgress
+}
+
+type VerificationData struct {
+	Code     string
+	ValidFor string
+}
+
+// Renderer menyusun isi pesan WhatsApp dari template. Pesan memakai format teks
+// WhatsApp (*tebal*, _miring_) sehingga cukup dirender dengan text/template.
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// NewRenderer mem-parse semua template. Nama pengirim tersedia di template lewat fungsi
+// sender. Template disematkan saat build, sehingga template yang rusak langsung panic.
+func NewRenderer(sender string) *Renderer {
+	funcs := template.FuncMap{
+		"sender": func() string { return sender },
+	}
+
+	names := []string{PaymentLink, ThankYou, ProgramUpdate, VerificationCode}
+	renderer := &Renderer{templates: make(map[string]*template.Template, len(names))}
+	for _, name := range names {
+		patterns := []string{"templates/layout.tmpl", "templates/" + name + ".tmpl"}
+		renderer.templates[name] = template.Must(template.New(name).Funcs(funcs).ParseFS(files, patterns...))
+	}
+	return renderer
+}
+
+func (r *Renderer) Render(name string, data any) (string, error) {
+	tmpl, ok := r.templates[name]
+	if !ok {
+		return "", fmt.Errorf("whatsapp template %q not found", name)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&body, "message", data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(body.String()), nil
+}